@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os/signal"
+	"rotator/internal/aggregator"
+	internalconfig "rotator/internal/config"
+	internallogger "rotator/internal/logger"
+	"rotator/internal/rq"
+	internalstore "rotator/internal/storage/store"
+	"syscall"
+	"time"
+)
+
+var configFile string
+
+func init() {
+	flag.StringVar(&configFile, "config", "configs/config.json", "Path to configuration file")
+}
+
+func main() {
+	flag.Parse()
+
+	config, err := internalconfig.LoadConfig(configFile)
+	if err != nil {
+		log.Fatalf("Failed load config %s", err)
+	}
+
+	logger, err := internallogger.NewLogger(config.Logger)
+	if err != nil {
+		log.Fatalf("Failed load config %s", err)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(),
+		syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	defer cancel()
+
+	store := internalstore.CreateStorage(ctx, *config)
+	logger.Info("[+] Connected to database")
+
+	agg := aggregator.New(store, logger)
+	consumer := rq.NewConsumer(config.Rabbit, config.Aggregator.BatchSize,
+		time.Duration(config.Aggregator.FlushInterval), store, logger)
+
+	logger.Info("[+] Aggregator starting...")
+	consumer.Consume(ctx, agg.Apply)
+	logger.Info("[+] Aggregator stopped")
+}
@@ -2,16 +2,27 @@ package main
 
 import (
 	"context"
+	"expvar"
 	"flag"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"io"
 	"log"
 	"os"
 	"os/signal"
+	bandit "rotator/internal/alghoritms"
 	internalapp "rotator/internal/app"
 	internalconfig "rotator/internal/config"
+	"rotator/internal/decisionlog"
 	internallogger "rotator/internal/logger"
+	"rotator/internal/ratelimit"
 	"rotator/internal/rq"
+	internalgrpc "rotator/internal/server/grpc"
 	internalhttp "rotator/internal/server/http"
 	internalstore "rotator/internal/storage/store"
+	"rotator/internal/tracing"
+	"sync"
 	"syscall"
 	"time"
 )
@@ -37,21 +48,106 @@ func main() {
 		syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 	defer cancel()
 
+	shutdownTracing, err := tracing.Init(ctx, config.Tracing, "rotator")
+	if err != nil {
+		log.Fatalf("Failed to init tracing %s", err)
+	}
+
 	store := internalstore.CreateStorage(ctx, *config)
 	logger.Info("[+] Connected to database")
 
-	application := internalapp.New(logger, store)
+	// publisher сам переподключается к RabbitMQ и пишет в лог о состоянии соединения.
+	// Он останавливается последним: в него еще пишут журнал решений и запросы, которые
+	// завершаются при остановке серверов.
+	publisherCtx, stopPublisher := context.WithCancel(context.Background())
+	defer stopPublisher()
+	publisher, err := rq.NewRabbit(publisherCtx, config.Rabbit, store, logger)
+	if err != nil {
+		log.Fatalf("Failed to create RabbitMQ publisher %s", err)
+	}
+	expvar.Publish("rabbit", expvar.Func(func() interface{} {
+		return publisher.Stats()
+	}))
+	prometheus.MustRegister(publisher.Collector())
+	if collector, ok := store.(interface{ Collector() prometheus.Collector }); ok {
+		prometheus.MustRegister(collector.Collector())
+	}
+
+	application := internalapp.New(logger, store, publisher)
+	switch mode := internalapp.StatsMode(config.Stats.Mode); mode {
+	case "":
+	case internalapp.StatsDirect, internalapp.StatsEvents:
+		application.StatsMode = mode
+	default:
+		log.Fatalf("Unknown stats mode %s", mode)
+	}
+
+	if config.HTTP.IdempotencyTTL > 0 {
+		application.IdempotencyTTL = time.Duration(config.HTTP.IdempotencyTTL)
+	}
+	application.AuthEnabled = config.Auth.Enabled
+	go purgeIdempotencyKeys(ctx, application)
 
-	server := internalhttp.NewServer(config.HTTP.Host, config.HTTP.Port, application, logger)
+	if config.Stats.RollupAfterDays > 0 {
+		application.StatsRollupAfter = time.Duration(config.Stats.RollupAfterDays) * time.Hour * 24
+	}
+	application.StatsRetention = time.Duration(config.Stats.RetentionDays) * time.Hour * 24
+	go compactStatistics(ctx, application)
 
-	// reciver
-	_, err = rq.NewRabbit(ctx, config.Rabbit.Url, config.Rabbit.Exchange, config.Rabbit.Queue, logger)
+	if config.Prior.Alpha < 0 || config.Prior.Beta < 0 {
+		log.Fatalf("Prior alpha and beta should not be negative")
+	}
+	application.Prior = bandit.Prior{Alpha: config.Prior.Alpha, Beta: config.Prior.Beta}
+
+	location, err := time.LoadLocation(config.Schedule.Timezone)
 	if err != nil {
-		logger.Error("[-] Failed to start RabbitMQ")
+		log.Fatalf("Invalid schedule timezone %s", err)
 	}
-	logger.Info("[+] Connected to RabbitMQ")
+	application.Location = location
+
+	// журнал решений дописывается после остановки серверов и до остановки publisher,
+	// main ждет его в конце
+	decisionCtx, stopDecisions := context.WithCancel(context.Background())
+	defer stopDecisions()
+	var background sync.WaitGroup
+	if config.Decisions.Sink != "" {
+		sink, err := newDecisionSink(config.Decisions, store, publisher)
+		if err != nil {
+			log.Fatalf("Failed to create decision log %s", err)
+		}
+		if closer, ok := sink.(io.Closer); ok {
+			defer func() {
+				if err := closer.Close(); err != nil {
+					logger.Error("failed to close decision log: " + err.Error())
+				}
+			}()
+		}
+
+		decisionLog := decisionlog.NewBuffered(sink, config.Decisions.BufferSize, config.Decisions.BatchSize,
+			time.Duration(config.Decisions.FlushInterval), logger)
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			decisionLog.Run(decisionCtx)
+		}()
+
+		application.DecisionLog = decisionLog
+		application.DecisionSampleRate = config.Decisions.SampleRate
+	}
+
+	// бюджеты общие для REST и gRPC, иначе ограничение обходится сменой протокола
+	limiter, err := ratelimit.New(config.HTTP.RateLimit)
+	if err != nil {
+		log.Fatalf("Invalid rate limit config %s", err)
+	}
+
+	server := internalhttp.NewServer(config.HTTP.Host, config.HTTP.Port, limiter, application, logger)
+	grpcServer := internalgrpc.NewServer(config.GRPC.Host, config.GRPC.Port,
+		config.GRPC.StreamConcurrency, limiter, application, logger)
 
+	serversStopped := make(chan struct{})
 	go func() {
+		defer close(serversStopped)
 		<-ctx.Done()
 
 		ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
@@ -60,6 +156,21 @@ func main() {
 		if err := server.Stop(ctx); err != nil {
 			logger.Error("failed to stop http server: " + err.Error())
 		}
+
+		if err := grpcServer.Stop(ctx); err != nil {
+			logger.Error("failed to stop grpc server: " + err.Error())
+		}
+
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Error("failed to flush traces: " + err.Error())
+		}
+	}()
+
+	go func() {
+		if err := grpcServer.Start(ctx); err != nil {
+			logger.Error("failed to start grpc server: " + err.Error())
+			cancel()
+		}
 	}()
 
 	logger.Info("[+] Application starting...")
@@ -69,4 +180,67 @@ func main() {
 		cancel()
 		os.Exit(1) //nolint:gocritic
 	}
+
+	<-serversStopped
+	stopDecisions()
+	background.Wait()
+	stopPublisher()
+	<-publisher.Done()
+}
+
+func newDecisionSink(conf internalconfig.DecisionLogConf, store internalapp.Storage,
+	publisher decisionlog.Publisher,
+) (decisionlog.Sink, error) {
+	switch conf.Sink {
+	case decisionlog.SinkTable:
+		storage, ok := store.(decisionlog.Storage)
+		if !ok {
+			return nil, fmt.Errorf("storage %T can't save decisions", store)
+		}
+		return decisionlog.NewTable(storage), nil
+	case decisionlog.SinkFile:
+		return decisionlog.NewFile(conf.Path)
+	case decisionlog.SinkEvents:
+		return decisionlog.NewEvents(publisher), nil
+	default:
+		return nil, fmt.Errorf("unknown decision log sink %s", conf.Sink)
+	}
+}
+
+// purgeIdempotencyKeys раз в час удаляет истекшие ключи идемпотентности.
+func purgeIdempotencyKeys(ctx context.Context, application *internalapp.App) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := application.PurgeIdempotencyKeys(ctx); err != nil {
+				application.Logger.Error("failed to purge idempotency keys: " + err.Error())
+			}
+		}
+	}
+}
+
+// compactStatistics раз в час сворачивает старую часовую статистику в дневную.
+func compactStatistics(ctx context.Context, application *internalapp.App) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			rolledUp, deleted, err := application.CompactStatistics(ctx)
+			if err != nil {
+				application.Logger.Error("failed to compact statistics: " + err.Error())
+				continue
+			}
+			application.Logger.Debug("statistics compacted",
+				zap.Int64("rolled_up", rolledUp), zap.Int64("deleted", deleted))
+		}
+	}
 }
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"rotator/internal/app"
+	internalconfig "rotator/internal/config"
+	"rotator/internal/rq"
+	sqlstorage "rotator/internal/storage/sql"
+	internalstore "rotator/internal/storage/store"
+	"text/tabwriter"
+	"time"
+)
+
+func eventsList(ctx context.Context, config *internalconfig.Config, args []string) error {
+	fs := flag.NewFlagSet("events list", flag.ExitOnError)
+	limit := fs.Int("limit", 100, "Max number of events to show")
+	fs.Parse(args)
+
+	store := internalstore.CreateStorage(ctx, *config)
+
+	letters, err := store.GetDeadLetters(ctx, *limit)
+	if err != nil {
+		return fmt.Errorf("can't read dead letters: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tCREATED\tCONTENT TYPE\tVERSION\tREASON")
+	for _, l := range letters {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%s\n",
+			l.ID, l.CreatedAt.Format(time.RFC3339), l.ContentType, l.SchemaVersion, l.Reason)
+	}
+
+	return w.Flush()
+}
+
+func eventsReplay(ctx context.Context, config *internalconfig.Config, logger app.Logger, args []string) error {
+	fs := flag.NewFlagSet("events replay", flag.ExitOnError)
+	limit := fs.Int("limit", 1000, "Max number of events to replay")
+	dryRun := fs.Bool("dry-run", false, "Only show how many events would be replayed")
+	fs.Parse(args)
+
+	store := internalstore.CreateStorage(ctx, *config)
+
+	letters, err := store.GetDeadLetters(ctx, *limit)
+	if err != nil {
+		return fmt.Errorf("can't read dead letters: %w", err)
+	}
+
+	if *dryRun || len(letters) == 0 {
+		fmt.Printf("%d events to replay\n", len(letters))
+		return nil
+	}
+
+	replayed, err := rq.Replay(ctx, config.Rabbit, letters, func(letter sqlstorage.DeadLetter) error {
+		return store.MarkDeadLetterReplayed(ctx, letter.ID)
+	}, logger)
+
+	fmt.Printf("replayed %d of %d events\n", replayed, len(letters))
+
+	return err
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"rotator/internal/app"
+	internalconfig "rotator/internal/config"
+	sqlstorage "rotator/internal/storage/sql"
+	internalstore "rotator/internal/storage/store"
+	"text/tabwriter"
+	"time"
+)
+
+func keysCreate(ctx context.Context, config *internalconfig.Config, logger app.Logger, args []string) error {
+	fs := flag.NewFlagSet("keys create", flag.ExitOnError)
+	name := fs.String("name", "", "Who the key is issued to")
+	role := fs.String("role", app.RoleServing, "Key role: serving, admin or analytics")
+	tenant := fs.Int64("tenant", sqlstorage.DefaultTenant, "Tenant ID the key belongs to")
+	fs.Parse(args)
+
+	if *name == "" {
+		return errors.New("-name is required")
+	}
+
+	application := app.New(logger, internalstore.CreateStorage(ctx, *config), nil)
+
+	key, id, err := application.CreateAPIKey(app.WithTenant(ctx, *tenant), *name, *role)
+	if err != nil {
+		return fmt.Errorf("can't create api key: %w", err)
+	}
+
+	fmt.Printf("created key %d for %s with role %s in tenant %d, it is shown only once:\n%s\n",
+		id, *name, *role, *tenant, key)
+
+	return nil
+}
+
+func keysList(ctx context.Context, config *internalconfig.Config, logger app.Logger) error {
+	application := app.New(logger, internalstore.CreateStorage(ctx, *config), nil)
+
+	keys, err := application.ListAPIKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("can't read api keys: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTENANT\tNAME\tROLE\tCREATED\tREVOKED")
+	for _, k := range keys {
+		revoked := "-"
+		if k.RevokedAt != nil {
+			revoked = k.RevokedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%s\t%s\n", k.ID, k.TenantID, k.Name, k.Role, k.CreatedAt.Format(time.RFC3339), revoked)
+	}
+
+	return w.Flush()
+}
+
+func keysRevoke(ctx context.Context, config *internalconfig.Config, logger app.Logger, args []string) error {
+	fs := flag.NewFlagSet("keys revoke", flag.ExitOnError)
+	id := fs.Int64("id", 0, "Key ID from keys list")
+	fs.Parse(args)
+
+	if *id == 0 {
+		return errors.New("-id is required")
+	}
+
+	application := app.New(logger, internalstore.CreateStorage(ctx, *config), nil)
+
+	if err := application.RevokeAPIKey(ctx, *id); err != nil {
+		return fmt.Errorf("can't revoke api key: %w", err)
+	}
+
+	fmt.Printf("revoked key %d, running services may accept a serving or analytics key for up to 30s\n", *id)
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	internalconfig "rotator/internal/config"
+	internallogger "rotator/internal/logger"
+	"syscall"
+)
+
+var configFile string
+
+func init() {
+	flag.StringVar(&configFile, "config", "configs/config.json", "Path to configuration file")
+}
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), `Usage: rotatorctl [-config path] <command> [flags]
+
+Commands:
+  events list    show events waiting in dead letter
+  events replay  publish dead letter events to the queue again
+  keys create    create an api key, -name, -role (serving, admin, analytics) and -tenant
+  keys list      show api keys
+  keys revoke    revoke an api key by -id
+  stats export   export statistics as -format csv or parquet, lifetime or by -interval hour or day,
+                 filtered by -tenant, -slot, -banner, -group, -from and -to, into -out
+  stats import   load lifetime counters of -tenant from csv -file, -mode replace or add
+  tenants create create a tenant, -name
+  tenants list   show tenants
+
+Flags:
+`)
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	config, err := internalconfig.LoadConfig(configFile)
+	if err != nil {
+		log.Fatalf("Failed load config %s", err)
+	}
+
+	logger, err := internallogger.NewLogger(config.Logger)
+	if err != nil {
+		log.Fatalf("Failed load config %s", err)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(),
+		syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	defer cancel()
+
+	switch args[0] + " " + args[1] {
+	case "events list":
+		err = eventsList(ctx, config, args[2:])
+	case "events replay":
+		err = eventsReplay(ctx, config, logger, args[2:])
+	case "keys create":
+		err = keysCreate(ctx, config, logger, args[2:])
+	case "keys list":
+		err = keysList(ctx, config, logger)
+	case "keys revoke":
+		err = keysRevoke(ctx, config, logger, args[2:])
+	case "stats export":
+		err = statsExport(ctx, config, logger, args[2:])
+	case "stats import":
+		err = statsImport(ctx, config, logger, args[2:])
+	case "tenants create":
+		err = tenantsCreate(ctx, config, logger, args[2:])
+	case "tenants list":
+		err = tenantsList(ctx, config, logger)
+	default:
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		cancel()
+		os.Exit(1) //nolint:gocritic
+	}
+}
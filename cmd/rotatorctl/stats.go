@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"rotator/internal/app"
+	internalconfig "rotator/internal/config"
+	"rotator/internal/export"
+	internalstore "rotator/internal/storage/store"
+	"time"
+)
+
+// parseDate дата вида 2024-03-01 (полночь UTC) или время в RFC 3339, пусто - без границы.
+func parseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
+
+func statsExport(ctx context.Context, config *internalconfig.Config, logger app.Logger, args []string) error {
+	fs := flag.NewFlagSet("stats export", flag.ExitOnError)
+	format := fs.String("format", export.FormatCSV, "File format: csv or parquet")
+	interval := fs.String("interval", "", "Period: hour or day, empty - lifetime counters")
+	tenantID := fs.Int64("tenant", 1, "Tenant id")
+	slotID := fs.Int64("slot", 0, "Only this slot")
+	bannerID := fs.Int64("banner", 0, "Only this banner")
+	socialGroupID := fs.Int64("group", 0, "Only this social group")
+	fromFlag := fs.String("from", "", "Period start, 2024-03-01 or RFC 3339")
+	toFlag := fs.String("to", "", "Period end (exclusive), 2024-03-08 or RFC 3339")
+	out := fs.String("out", "", "Output file, stdout by default")
+	fs.Parse(args)
+
+	from, err := parseDate(*fromFlag)
+	if err != nil {
+		return fmt.Errorf("invalid -from: %w", err)
+	}
+	to, err := parseDate(*toFlag)
+	if err != nil {
+		return fmt.Errorf("invalid -to: %w", err)
+	}
+
+	var output io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return fmt.Errorf("can't create %s: %w", *out, err)
+		}
+		defer file.Close()
+		output = file
+	}
+	buffered := bufio.NewWriter(output)
+
+	writer, err := export.NewWriter(*format, buffered, *interval != "")
+	if err != nil {
+		return err
+	}
+
+	application := app.New(logger, internalstore.CreateStorage(ctx, *config), nil)
+
+	err = application.ExportStats(app.WithTenant(ctx, *tenantID), app.ExportQuery{
+		Interval:      *interval,
+		SlotID:        *slotID,
+		BannerID:      *bannerID,
+		SocialGroupID: *socialGroupID,
+		From:          from,
+		To:            to,
+	}, writer.Write)
+	if err != nil {
+		return fmt.Errorf("can't export statistics: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("can't write export: %w", err)
+	}
+
+	return buffered.Flush()
+}
+
+// importBatch сколько строк загружается одной транзакцией.
+const importBatch = 1000
+
+func statsImport(ctx context.Context, config *internalconfig.Config, logger app.Logger, args []string) error {
+	fs := flag.NewFlagSet("stats import", flag.ExitOnError)
+	file := fs.String("file", "", "CSV file with lifetime counters, stdin by default")
+	mode := fs.String("mode", app.ImportReplace, "replace counters or add to them")
+	tenantID := fs.Int64("tenant", 1, "Tenant id")
+	fs.Parse(args)
+
+	var input io.Reader = os.Stdin
+	if *file != "" {
+		f, err := os.Open(*file)
+		if err != nil {
+			return fmt.Errorf("can't open %s: %w", *file, err)
+		}
+		defer f.Close()
+		input = f
+	}
+
+	reader, err := export.NewCSVReader(bufio.NewReader(input))
+	if err != nil {
+		return err
+	}
+
+	application := app.New(logger, internalstore.CreateStorage(ctx, *config), nil)
+	ctx = app.WithTenant(ctx, *tenantID)
+
+	var imported, failed, offset int
+	batch := make([]app.ImportRow, 0, importBatch)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		results, err := application.ImportStats(ctx, *mode, batch)
+		if err != nil {
+			return fmt.Errorf("can't import statistics: %w", err)
+		}
+
+		for i, err := range results {
+			if err != nil {
+				failed++
+				// строка 1 - заголовок
+				fmt.Fprintf(os.Stderr, "row %d: %s\n", offset+i+2, app.AsError(err).PublicMessage())
+				continue
+			}
+			imported++
+		}
+		offset += len(batch)
+		batch = batch[:0]
+
+		return nil
+	}
+
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		batch = append(batch, row)
+		if len(batch) == importBatch {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("imported %d rows, failed %d\n", imported, failed)
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"rotator/internal/app"
+	internalconfig "rotator/internal/config"
+	internalstore "rotator/internal/storage/store"
+	"text/tabwriter"
+)
+
+func tenantsCreate(ctx context.Context, config *internalconfig.Config, logger app.Logger, args []string) error {
+	fs := flag.NewFlagSet("tenants create", flag.ExitOnError)
+	name := fs.String("name", "", "Site or advertiser name")
+	fs.Parse(args)
+
+	if *name == "" {
+		return errors.New("-name is required")
+	}
+
+	application := app.New(logger, internalstore.CreateStorage(ctx, *config), nil)
+
+	id, err := application.CreateTenant(ctx, *name)
+	if err != nil {
+		return fmt.Errorf("can't create tenant: %w", err)
+	}
+
+	fmt.Printf("created tenant %d for %s\n", id, *name)
+
+	return nil
+}
+
+func tenantsList(ctx context.Context, config *internalconfig.Config, logger app.Logger) error {
+	application := app.New(logger, internalstore.CreateStorage(ctx, *config), nil)
+
+	tenants, err := application.ListTenants(ctx)
+	if err != nil {
+		return fmt.Errorf("can't read tenants: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME")
+	for _, t := range tenants {
+		fmt.Fprintf(w, "%d\t%s\n", t.ID, t.Description)
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,164 @@
+package aggregator
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"rotator/internal/events"
+	sqlstorage "rotator/internal/storage/sql"
+	"sort"
+	"time"
+)
+
+type Logger interface {
+	Debug(message string, fields ...zap.Field)
+	Error(message string, fields ...zap.Field)
+}
+
+type Storage interface {
+	ApplyStatistics(ctx context.Context, deltas []sqlstorage.StatisticsDelta,
+		deadLetters func(missing []sqlstorage.StatisticsDelta) []sqlstorage.DeadLetter,
+	) ([]sqlstorage.StatisticsDelta, error)
+}
+
+// Aggregator применяет события кликов и показов к таблице statistics.
+type Aggregator struct {
+	storage Storage
+	logger  Logger
+}
+
+func New(storage Storage, logger Logger) *Aggregator {
+	return &Aggregator{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+// Apply события, для которых нет строки в statistics (баннер убрали из слота
+// или неизвестная соц.группа), сохраняются в dead letter в той же транзакции.
+func (a *Aggregator) Apply(ctx context.Context, batch []events.Event) error {
+	deltas := Aggregate(batch)
+	missing, err := a.storage.ApplyStatistics(ctx, deltas, func(missing []sqlstorage.StatisticsDelta) []sqlstorage.DeadLetter {
+		return deadLetters(batch, missing)
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(missing) > 0 {
+		a.logger.Error("events without statistics row moved to dead letter", zap.Int("rows", len(missing)))
+	}
+
+	a.logger.Debug("applied events", zap.Int("events", len(batch)), zap.Int("rows", len(deltas)-len(missing)))
+
+	return nil
+}
+
+type key struct {
+	tenantID, slotID, bannerID, socialGroupID int64
+}
+
+// tenantOf события без арендатора относятся к арендатору по умолчанию.
+func tenantOf(e events.Event) int64 {
+	if e.TenantID == 0 {
+		return sqlstorage.DefaultTenant
+	}
+
+	return e.TenantID
+}
+
+func deadLetters(batch []events.Event, missing []sqlstorage.StatisticsDelta) []sqlstorage.DeadLetter {
+	keys := make(map[key]struct{}, len(missing))
+	for _, d := range missing {
+		keys[key{d.TenantID, d.SlotID, d.BannerID, d.SocialGroupID}] = struct{}{}
+	}
+
+	codec := events.JSONCodec{}
+	letters := make([]sqlstorage.DeadLetter, 0)
+	for _, e := range batch {
+		if _, ok := keys[key{tenantOf(e), e.SlotID, e.BannerID, e.SocialGroupID}]; !ok {
+			continue
+		}
+
+		body, err := codec.Encode(e)
+		if err != nil {
+			continue
+		}
+
+		letters = append(letters, sqlstorage.DeadLetter{
+			Body:          body,
+			ContentType:   codec.ContentType(),
+			SchemaVersion: events.SchemaVersion,
+			Reason:        "statistics row not found",
+		})
+	}
+
+	return letters
+}
+
+// Aggregate сворачивает события в приращения счетчиков по (арендатор, слот, баннер, соц.группа)
+// с разбивкой по часам события. Решения стратегии счетчики не меняют и пропускаются.
+// Результат отсортирован, чтобы строки обновлялись в одном порядке.
+func Aggregate(batch []events.Event) []sqlstorage.StatisticsDelta {
+	index := make(map[key]int)
+	hours := make([]map[time.Time]int, 0)
+	deltas := make([]sqlstorage.StatisticsDelta, 0)
+	for _, e := range batch {
+		if e.Type != events.TypeClick && e.Type != events.TypeDisplay {
+			continue
+		}
+
+		k := key{tenantOf(e), e.SlotID, e.BannerID, e.SocialGroupID}
+		i, ok := index[k]
+		if !ok {
+			i = len(deltas)
+			index[k] = i
+			deltas = append(deltas, sqlstorage.StatisticsDelta{
+				TenantID:      k.tenantID,
+				SlotID:        e.SlotID,
+				BannerID:      e.BannerID,
+				SocialGroupID: e.SocialGroupID,
+			})
+			hours = append(hours, make(map[time.Time]int))
+		}
+
+		// время без часового пояса, чтобы один час в разных зонах попал в одну строку
+		hour := e.Timestamp.UTC().Truncate(time.Hour)
+		if e.Timestamp.IsZero() {
+			hour = time.Time{}
+		}
+		h, ok := hours[i][hour]
+		if !ok {
+			h = len(deltas[i].Hourly)
+			hours[i][hour] = h
+			deltas[i].Hourly = append(deltas[i].Hourly, sqlstorage.HourlyDelta{Hour: hour})
+		}
+
+		switch e.Type {
+		case events.TypeClick:
+			deltas[i].Click++
+			deltas[i].Hourly[h].Click++
+		case events.TypeDisplay:
+			deltas[i].Display++
+			deltas[i].Hourly[h].Display++
+		}
+	}
+
+	for _, d := range deltas {
+		sort.Slice(d.Hourly, func(i, j int) bool { return d.Hourly[i].Hour.Before(d.Hourly[j].Hour) })
+	}
+
+	sort.Slice(deltas, func(i, j int) bool {
+		if deltas[i].TenantID != deltas[j].TenantID {
+			return deltas[i].TenantID < deltas[j].TenantID
+		}
+		if deltas[i].SlotID != deltas[j].SlotID {
+			return deltas[i].SlotID < deltas[j].SlotID
+		}
+		if deltas[i].BannerID != deltas[j].BannerID {
+			return deltas[i].BannerID < deltas[j].BannerID
+		}
+		return deltas[i].SocialGroupID < deltas[j].SocialGroupID
+	})
+
+	return deltas
+}
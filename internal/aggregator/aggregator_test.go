@@ -0,0 +1,101 @@
+package aggregator
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"rotator/internal/events"
+	sqlstorage "rotator/internal/storage/sql"
+	"testing"
+	"time"
+)
+
+func TestAggregate(t *testing.T) {
+	hour := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time {
+		return hour.Add(time.Minute * time.Duration(minutes))
+	}
+
+	batch := []events.Event{
+		{Type: events.TypeDisplay, SlotID: 2, BannerID: 1, SocialGroupID: 1},
+		{Type: events.TypeDisplay, SlotID: 1, BannerID: 3, SocialGroupID: 2, Timestamp: at(70)},
+		{Type: events.TypeDisplay, SlotID: 1, BannerID: 3, SocialGroupID: 2, Timestamp: at(5)},
+		{Type: events.TypeClick, SlotID: 1, BannerID: 3, SocialGroupID: 2,
+			Timestamp: at(10).In(time.FixedZone("MSK", 3*60*60))},
+		{Type: events.TypeClick, SlotID: 1, BannerID: 2, SocialGroupID: 2},
+		{Type: events.TypeDisplay, SlotID: 1, BannerID: 3, SocialGroupID: 2, TenantID: 2},
+		{Type: events.TypeDecision, SlotID: 1, BannerID: 3, SocialGroupID: 2},
+	}
+
+	require.Equal(t, []sqlstorage.StatisticsDelta{
+		{TenantID: 1, SlotID: 1, BannerID: 2, SocialGroupID: 2, Display: 0, Click: 1,
+			Hourly: []sqlstorage.HourlyDelta{{Click: 1}}},
+		{TenantID: 1, SlotID: 1, BannerID: 3, SocialGroupID: 2, Display: 2, Click: 1,
+			Hourly: []sqlstorage.HourlyDelta{
+				{Hour: hour, Display: 1, Click: 1},
+				{Hour: hour.Add(time.Hour), Display: 1},
+			}},
+		{TenantID: 1, SlotID: 2, BannerID: 1, SocialGroupID: 1, Display: 1, Click: 0,
+			Hourly: []sqlstorage.HourlyDelta{{Display: 1}}},
+		{TenantID: 2, SlotID: 1, BannerID: 3, SocialGroupID: 2, Display: 1, Click: 0,
+			Hourly: []sqlstorage.HourlyDelta{{Display: 1}}},
+	}, Aggregate(batch))
+
+	require.Empty(t, Aggregate(nil))
+}
+
+func TestDeadLetters(t *testing.T) {
+	batch := []events.Event{
+		{Type: events.TypeDisplay, SlotID: 1, BannerID: 1, SocialGroupID: 1},
+		{Type: events.TypeClick, SlotID: 1, BannerID: 5, SocialGroupID: 1},
+		{Type: events.TypeDisplay, SlotID: 1, BannerID: 5, SocialGroupID: 1},
+	}
+
+	letters := deadLetters(batch, []sqlstorage.StatisticsDelta{
+		{TenantID: 1, SlotID: 1, BannerID: 5, SocialGroupID: 1, Display: 1, Click: 1},
+	})
+	require.Len(t, letters, 2)
+
+	for i, l := range letters {
+		event, err := events.JSONCodec{}.Decode(l.Body)
+		require.NoError(t, err)
+		require.Equal(t, batch[i+1], event)
+		require.Equal(t, events.ContentTypeJSON, l.ContentType)
+	}
+}
+
+// fakeStorage не находит строку статистики для баннера 5 и запоминает dead letter,
+// сохраненные вместе с приращениями.
+type fakeStorage struct {
+	letters []sqlstorage.DeadLetter
+}
+
+func (s *fakeStorage) ApplyStatistics(_ context.Context, deltas []sqlstorage.StatisticsDelta,
+	deadLetters func(missing []sqlstorage.StatisticsDelta) []sqlstorage.DeadLetter,
+) ([]sqlstorage.StatisticsDelta, error) {
+	missing := make([]sqlstorage.StatisticsDelta, 0)
+	for _, d := range deltas {
+		if d.BannerID == 5 {
+			missing = append(missing, d)
+		}
+	}
+	s.letters = deadLetters(missing)
+
+	return missing, nil
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...zap.Field) {}
+
+func (nopLogger) Error(string, ...zap.Field) {}
+
+func TestApply(t *testing.T) {
+	storage := &fakeStorage{}
+	err := New(storage, nopLogger{}).Apply(context.Background(), []events.Event{
+		{Type: events.TypeDisplay, SlotID: 1, BannerID: 1, SocialGroupID: 1},
+		{Type: events.TypeClick, SlotID: 1, BannerID: 5, SocialGroupID: 1},
+	})
+	require.NoError(t, err)
+	require.Len(t, storage.letters, 1)
+}
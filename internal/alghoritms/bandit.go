@@ -5,26 +5,131 @@ import (
 	"math"
 )
 
+// StrategyUCB1 название стратегии, которое уходит в события.
+const StrategyUCB1 = "ucb1"
+
+// Bandit баннер со своими показами (Trials) и кликами (Reward). Alpha и Beta - априорные
+// псевдосчетчики кликов и показов без клика, они складываются с настоящими, но в статистику не попадают.
 type Bandit struct {
 	ID     int
 	Trials int
 	Reward int
+	Alpha  float64
+	Beta   float64
 }
 
-func ChooseAlgorithm(bandit []Bandit, allTrials int) (int, error) {
-	if len(bandit) == 0 {
+// Prior априорные псевдосчетчики: Alpha кликов и Beta показов без клика.
+type Prior struct {
+	Alpha float64
+	Beta  float64
+}
+
+// pseudoTrials показы вместе с псевдосчетчиками.
+func (b Bandit) pseudoTrials() float64 {
+	return float64(b.Trials) + b.Alpha + b.Beta
+}
+
+// CTR наблюдаемая доля кликов без псевдосчетчиков, 0 - показов не было.
+func (b Bandit) CTR() float64 {
+	if b.Trials <= 0 {
+		return 0
+	}
+
+	return float64(b.Reward) / float64(b.Trials)
+}
+
+// Unplayed у баннера нет ни показов, ни априорных псевдосчетчиков: оценить его нечем,
+// поэтому любая стратегия сначала показывает такие баннеры.
+func (b Bandit) Unplayed() bool {
+	return b.pseudoTrials() <= 0
+}
+
+// Estimate оценка баннера стратегией. Score = Mean + Bonus, баннер с наибольшим Score выигрывает.
+// У баннера без показов и псевдосчетчиков Bonus и Score бесконечны: его нужно показать хотя бы раз.
+type Estimate struct {
+	Bandit
+	// Mean средняя награда (CTR) с учетом псевдосчетчиков
+	Mean float64
+	// Bonus бонус за исследование
+	Bonus float64
+	Score float64
+	// Propensity вероятность, с которой стратегия показывает баннер. У детерминированной
+	// стратегии она равна 1 у победителя и 0 у остальных.
+	Propensity float64
+}
+
+// Strategy стратегия выбора баннера, которая умеет объяснить свой выбор.
+type Strategy interface {
+	Name() string
+	// Params параметры стратегии, с которыми принималось решение.
+	Params() map[string]float64
+	// Estimate оценки баннеров в том же порядке, что и bandits. allTrials - настоящие показы слота.
+	Estimate(bandits []Bandit, allTrials int) []Estimate
+}
+
+// UCB1 средняя награда + sqrt(c ln N / n), c = 2. n и N включают псевдосчетчики.
+type UCB1 struct{}
+
+func (UCB1) Name() string {
+	return StrategyUCB1
+}
+
+func (UCB1) Params() map[string]float64 {
+	return map[string]float64{"c": 2}
+}
+
+func (UCB1) Estimate(bandits []Bandit, allTrials int) []Estimate {
+	total := float64(allTrials)
+	for _, b := range bandits {
+		total += b.Alpha + b.Beta
+	}
+
+	result := make([]Estimate, len(bandits))
+	for i, b := range bandits {
+		result[i] = ucb1(b, total)
+	}
+
+	if index, err := Best(result); err == nil {
+		result[index].Propensity = 1
+	}
+
+	return result
+}
+
+func ucb1(bandit Bandit, allTrials float64) Estimate {
+	if bandit.Unplayed() {
+		return Estimate{Bandit: bandit, Bonus: math.Inf(1), Score: math.Inf(1)}
+	}
+
+	// без показов в слоте логарифм не определен, бонус тогда нулевой
+	trials := bandit.pseudoTrials()
+	mean := (float64(bandit.Reward) + bandit.Alpha) / trials
+	bonus := math.Sqrt(2 * math.Log(math.Max(allTrials, 1)) / trials)
+
+	return Estimate{Bandit: bandit, Mean: mean, Bonus: bonus, Score: mean + bonus}
+}
+
+// Best индекс оценки с наибольшим Score, при равенстве - первой из них.
+func Best(estimates []Estimate) (int, error) {
+	if len(estimates) == 0 {
 		return 0, fmt.Errorf("len slice is nil")
 	}
 
 	index := 0
-	var statistic float64 = -1
-	for i, v := range bandit {
-		reward := float64(v.Reward) / float64(v.Trials)
-		currentStatistic := reward + math.Sqrt(2*math.Log(float64(allTrials))/float64(v.Trials))
-		if currentStatistic > statistic {
-			index, statistic = i, currentStatistic
+	for i, e := range estimates {
+		if e.Score > estimates[index].Score {
+			index = i
 		}
 	}
 
+	return index, nil
+}
+
+func ChooseAlgorithm(bandit []Bandit, allTrials int) (int, error) {
+	index, err := Best(UCB1{}.Estimate(bandit, allTrials))
+	if err != nil {
+		return 0, err
+	}
+
 	return bandit[index].ID, nil
 }
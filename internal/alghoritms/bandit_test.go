@@ -2,6 +2,7 @@ package alghoritms
 
 import (
 	"github.com/stretchr/testify/require"
+	"math"
 	"testing"
 )
 
@@ -35,3 +36,75 @@ func TestMakeChoice(t *testing.T) {
 		require.Equal(t, 9, choice)
 	})
 }
+
+func TestUCB1Estimate(t *testing.T) {
+	stats := []Bandit{
+		{ID: 1, Trials: 10, Reward: 5},
+		{ID: 2, Trials: 0, Reward: 0},
+	}
+
+	estimates := UCB1{}.Estimate(stats, 10)
+	require.Len(t, estimates, 2)
+
+	require.Equal(t, 1, estimates[0].ID)
+	require.InDelta(t, 0.5, estimates[0].Mean, 1e-9)
+	require.InDelta(t, 0.6786, estimates[0].Bonus, 1e-4)
+	require.InDelta(t, estimates[0].Mean+estimates[0].Bonus, estimates[0].Score, 1e-9)
+
+	t.Run("banner without displays wins", func(t *testing.T) {
+		require.True(t, math.IsInf(estimates[1].Score, 1))
+
+		index, err := Best(estimates)
+		require.NoError(t, err)
+		require.Equal(t, 1, index)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		_, err := Best(nil)
+		require.Error(t, err)
+	})
+}
+
+func TestUCB1Prior(t *testing.T) {
+	stats := []Bandit{
+		{ID: 1, Trials: 10, Reward: 5, Alpha: 1, Beta: 9},
+		{ID: 2, Trials: 0, Reward: 0, Alpha: 1, Beta: 9},
+	}
+
+	estimates := UCB1{}.Estimate(stats, 10)
+	require.InDelta(t, 0.3, estimates[0].Mean, 1e-9)
+	require.InDelta(t, 0.1, estimates[1].Mean, 1e-9)
+	// N = 10 настоящих показов + 20 псевдопоказов
+	require.InDelta(t, math.Sqrt(2*math.Log(30)/20), estimates[0].Bonus, 1e-9)
+
+	t.Run("prior is enough to estimate", func(t *testing.T) {
+		require.False(t, stats[1].Unplayed())
+		require.False(t, math.IsInf(estimates[1].Score, 1))
+	})
+
+	t.Run("unplayed banner wins", func(t *testing.T) {
+		estimates := UCB1{}.Estimate(append(stats, Bandit{ID: 3}), 10)
+
+		index, err := Best(estimates)
+		require.NoError(t, err)
+		require.Equal(t, 2, index)
+	})
+}
+
+func TestWilsonInterval(t *testing.T) {
+	lower, upper := WilsonInterval(10, 100, 0.95)
+	require.InDelta(t, 0.0552, lower, 1e-4)
+	require.InDelta(t, 0.1744, upper, 1e-4)
+
+	t.Run("no clicks", func(t *testing.T) {
+		lower, upper := WilsonInterval(0, 20, 0.95)
+		require.Equal(t, 0.0, lower)
+		require.InDelta(t, 0.1611, upper, 1e-4)
+	})
+
+	t.Run("no displays", func(t *testing.T) {
+		lower, upper := WilsonInterval(0, 0, 0.95)
+		require.Equal(t, 0.0, lower)
+		require.Equal(t, 1.0, upper)
+	})
+}
@@ -0,0 +1,22 @@
+package alghoritms
+
+import "math"
+
+// WilsonInterval доверительный интервал Уилсона для CTR = clicks / trials с уровнем доверия
+// confidence (например 0.95). В отличие от нормального приближения не выходит за [0, 1]
+// и не схлопывается при малом числе показов. Без показов интервал [0, 1].
+func WilsonInterval(clicks, trials int64, confidence float64) (float64, float64) {
+	if trials <= 0 {
+		return 0, 1
+	}
+
+	n := float64(trials)
+	p := math.Min(float64(clicks)/n, 1)
+	z := math.Sqrt2 * math.Erfinv(confidence)
+
+	denominator := 1 + z*z/n
+	center := (p + z*z/(2*n)) / denominator
+	margin := z / denominator * math.Sqrt(p*(1-p)/n+z*z/(4*n*n))
+
+	return math.Max(center-margin, 0), math.Min(center+margin, 1)
+}
@@ -2,15 +2,56 @@ package app
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
+	"rotator/internal/aggregator"
 	bandit "rotator/internal/alghoritms"
+	"rotator/internal/events"
+	"rotator/internal/metrics"
 	sqlstorage "rotator/internal/storage/sql"
+	"rotator/internal/tracing"
 	"time"
 )
 
+type StatsMode string
+
+const (
+	// StatsDirect счетчики обновляются в storage прямо в запросе.
+	StatsDirect StatsMode = "direct"
+	// StatsEvents сервис только читает статистику и публикует события,
+	// счетчики обновляет rotator-aggregator.
+	StatsEvents StatsMode = "events"
+)
+
 type App struct {
-	Logger  Logger
-	Storage Storage
+	Logger    Logger
+	Storage   Storage
+	Publisher Publisher
+	StatsMode StatsMode
+	// Strategy стратегия выбора баннера, по умолчанию UCB1
+	Strategy bandit.Strategy
+	// IdempotencyTTL сколько хранится ответ на запрос с ключом идемпотентности
+	IdempotencyTTL time.Duration
+	// AuthEnabled требовать ключ API в HTTP и gRPC запросах
+	AuthEnabled bool
+	// StatsRollupAfter через сколько часовая статистика сворачивается в дневную
+	StatsRollupAfter time.Duration
+	// StatsRetention сколько хранится дневная статистика, 0 - всегда
+	StatsRetention time.Duration
+	// Prior псевдосчетчики слотов, у которых нет своих
+	Prior bandit.Prior
+	// Location часовой пояс дней недели и часов в расписаниях баннеров
+	Location *time.Location
+	// DecisionLog журнал решений ChooseBanner, nil - не вести
+	DecisionLog DecisionLog
+	// DecisionSampleRate доля решений, которые попадают в журнал, от 0 до 1
+	DecisionSampleRate float64
+
+	keys *keyCache
 }
 
 type Logger interface {
@@ -21,71 +62,281 @@ type Logger interface {
 }
 
 type Storage interface {
+	Ping(ctx context.Context) error
 	GetBannerId(ctx context.Context, bannerID int64) (*sqlstorage.Banner, error)
 	GetSlotByID(ctx context.Context, slotID int64) (*sqlstorage.Slot, error)
 	GetSocialGroupByID(ctx context.Context, socialGroupID int64) (*sqlstorage.SocialGroup, error)
-	AddBannerToSlot(ctx context.Context, bannerID, slotID int64) error
+	SetSlotPrior(ctx context.Context, slotID int64, alpha, beta *float64) error
+	AddBannerToSlot(ctx context.Context, bannerID, slotID int64, warmStart sqlstorage.WarmStart,
+		schedule sqlstorage.Schedule) error
+	GetBannerSchedule(ctx context.Context, bannerID, slotID int64) (*sqlstorage.Schedule, error)
+	SetBannerSchedule(ctx context.Context, bannerID, slotID int64, schedule sqlstorage.Schedule) error
+	GetBannerStatus(ctx context.Context, bannerID, slotID int64) (string, error)
+	SetBannerStatus(ctx context.Context, bannerID, slotID int64, status string) (string, error)
 	RemoveBannerFromSlot(ctx context.Context, bannerID, slotID int64) error
 	CountTransition(ctx context.Context, bannerID, slotID, socialGroupID int64) error
 	CountDisplay(ctx context.Context, bannerID, slotID, socialGroupID int64) error
-	GetBannersStat(ctx context.Context, slotID, socialGroupID int64) ([]sqlstorage.BannerStats, int, error)
+	GetBannersStat(ctx context.Context, slotID, socialGroupID int64, at time.Time) ([]sqlstorage.BannerStats, int, error)
+	ApplyStatistics(ctx context.Context, deltas []sqlstorage.StatisticsDelta,
+		deadLetters func(missing []sqlstorage.StatisticsDelta) []sqlstorage.DeadLetter,
+	) ([]sqlstorage.StatisticsDelta, error)
+	ImportStatistics(ctx context.Context, rows []sqlstorage.StatisticsImport, replace bool) ([]int, error)
+	SaveDeadLetters(ctx context.Context, letters []sqlstorage.DeadLetter) error
+	GetDeadLetters(ctx context.Context, limit int) ([]sqlstorage.DeadLetter, error)
+	MarkDeadLetterReplayed(ctx context.Context, id int64) error
+	CreateCatalogItem(ctx context.Context, catalog, description string) (int64, error)
+	GetCatalogItem(ctx context.Context, catalog string, id int64) (*sqlstorage.CatalogItem, error)
+	ListCatalogItems(ctx context.Context, catalog string) ([]sqlstorage.CatalogItem, error)
+	UpdateCatalogItem(ctx context.Context, catalog string, id int64, description string) error
+	DeleteCatalogItem(ctx context.Context, catalog string, id int64) error
+	ReserveIdempotencyKey(ctx context.Context, key string, requestHash []byte,
+		expiresAt, staleBefore time.Time) (*sqlstorage.IdempotencyKey, bool, error)
+	SaveIdempotentResponse(ctx context.Context, key string, statusCode int, contentType string, body []byte) error
+	ReleaseIdempotencyKey(ctx context.Context, key string) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+	CreateAPIKey(ctx context.Context, name, role string, keyHash []byte) (int64, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash []byte) (*sqlstorage.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]sqlstorage.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int64) error
+	CreateTenant(ctx context.Context, description string) (int64, error)
+	GetStatistics(ctx context.Context, filter sqlstorage.StatisticsFilter, groupBy []string) ([]sqlstorage.StatisticsRow, error)
+	GetStatisticsSeries(ctx context.Context, filter sqlstorage.StatisticsFilter, groupBy []string,
+		interval string, from, to time.Time) ([]sqlstorage.StatisticsPoint, error)
+	ExportStatistics(ctx context.Context, filter sqlstorage.StatisticsFilter, interval string,
+		from, to time.Time, fn func(sqlstorage.StatisticsPoint) error) error
+	RollupHourlyStatistics(ctx context.Context, before time.Time) (int64, error)
+	DeleteDailyStatistics(ctx context.Context, before time.Time) (int64, error)
+	ListTenants(ctx context.Context) ([]sqlstorage.Tenant, error)
+}
+
+// Publisher отправляет события кликов и показов в очередь.
+type Publisher interface {
+	Publish(ctx context.Context, event events.Event) error
+}
+
+// Choice выбранный для показа баннер.
+type Choice struct {
+	BannerID     int64
+	ImpressionID string
 }
 
-func New(logger Logger, storage Storage) *App {
+func New(logger Logger, storage Storage, publisher Publisher) *App {
 	return &App{
-		Logger:  logger,
-		Storage: storage,
+		Logger:           logger,
+		Storage:          storage,
+		Publisher:        publisher,
+		StatsMode:        StatsDirect,
+		Strategy:         bandit.UCB1{},
+		IdempotencyTTL:   defaultIdempotencyTTL,
+		StatsRollupAfter: defaultStatsRollupAfter,
+		Location:         time.UTC,
+		keys:             newKeyCache(),
 	}
 }
 
-func (a *App) AddBannerToSlot(ctx context.Context, bannerID, slotID int64) error {
+// AddBannerToSlot warmStart задает начальную статистику баннера, нулевой - без нее,
+// schedule - окно, в котором баннер участвует в выборе, нулевое - всегда.
+func (a *App) AddBannerToSlot(ctx context.Context, bannerID, slotID int64, warmStart WarmStart,
+	schedule Schedule,
+) error {
+	ctx, span := tracing.Start(ctx, "App.AddBannerToSlot", trace.WithAttributes(
+		attribute.Int64("banner.id", bannerID), attribute.Int64("slot.id", slotID),
+		attribute.String("warm_start", warmStart.Source)))
+	defer span.End()
+
+	if err := warmStart.validate(bannerID); err != nil {
+		return err
+	}
+	stored, err := schedule.storage()
+	if err != nil {
+		return err
+	}
+
 	opCtx, cancel := context.WithTimeout(ctx, time.Second*3)
 	defer cancel()
 
-	return a.Storage.AddBannerToSlot(opCtx, bannerID, slotID)
+	if warmStart.Source == sqlstorage.WarmStartBanner {
+		similar, err := a.Storage.GetBannerId(opCtx, warmStart.BannerID)
+		if err != nil {
+			return storageError(err)
+		}
+		if similar == nil {
+			return ErrInvalidWarmStart.Wrap(fmt.Errorf("similar banner %d not found", warmStart.BannerID))
+		}
+	}
+
+	err = a.Storage.AddBannerToSlot(opCtx, bannerID, slotID, sqlstorage.WarmStart{
+		Source:   warmStart.Source,
+		BannerID: warmStart.BannerID,
+		Displays: warmStart.Displays,
+	}, stored)
+
+	return storageError(err, ErrBannerAlreadyInSlot, ErrBannerOrSlotNotFound)
 }
 
+// RemoveBannerToSlot убирает баннер из слота вместе со статистикой. Снять баннер с показа
+// без потери статистики - SetBannerStatus.
 func (a *App) RemoveBannerToSlot(ctx context.Context, bannerID, slotID int64) error {
+	ctx, span := tracing.Start(ctx, "App.RemoveBannerToSlot", trace.WithAttributes(
+		attribute.Int64("banner.id", bannerID), attribute.Int64("slot.id", slotID)))
+	defer span.End()
+
 	opCtx, cancel := context.WithTimeout(ctx, time.Second*3)
 	defer cancel()
 
-	return a.Storage.RemoveBannerFromSlot(opCtx, bannerID, slotID)
+	err := a.Storage.RemoveBannerFromSlot(opCtx, bannerID, slotID)
+
+	return storageError(err, ErrBannerNotInSlot)
 }
 
-func (a *App) CountTransition(ctx context.Context, bannerID, slotID, socialGroupID int64) error {
+func (a *App) CountTransition(ctx context.Context, bannerID, slotID, socialGroupID int64, impressionID string) error {
+	ctx, span := tracing.Start(ctx, "App.CountTransition", trace.WithAttributes(
+		attribute.Int64("banner.id", bannerID), attribute.Int64("slot.id", slotID),
+		attribute.Int64("social_group.id", socialGroupID)))
+	defer span.End()
+
 	opCtx, cancel := context.WithTimeout(ctx, time.Second*3)
 	defer cancel()
 
-	return a.Storage.CountTransition(opCtx, bannerID, slotID, socialGroupID)
+	if a.StatsMode != StatsEvents {
+		err := a.Storage.CountTransition(opCtx, bannerID, slotID, socialGroupID)
+		if err != nil {
+			return storageError(err, ErrBannerNotInSlot)
+		}
+	}
+	metrics.Click(slotID, bannerID, socialGroupID)
+
+	a.publishEvent(ctx, events.Event{
+		TenantID:      TenantFromContext(ctx),
+		Type:          events.TypeClick,
+		SlotID:        slotID,
+		BannerID:      bannerID,
+		SocialGroupID: socialGroupID,
+		ImpressionID:  impressionID,
+	})
+
+	return nil
 }
 
-func (a *App) ChooseBanner(ctx context.Context, slotID, socialGroupID int64) (bannerID int64, err error) {
+func (a *App) ChooseBanner(ctx context.Context, slotID, socialGroupID int64) (Choice, error) {
+	ctx, span := tracing.Start(ctx, "App.ChooseBanner", trace.WithAttributes(
+		attribute.Int64("slot.id", slotID), attribute.Int64("social_group.id", socialGroupID)))
+	defer span.End()
+
 	opCtx, cancel := context.WithTimeout(ctx, time.Second*5)
 	defer cancel()
 
-	bannerStat, totalDisplay, err := a.Storage.GetBannersStat(opCtx, slotID, socialGroupID)
+	estimates, winner, totalDisplay, err := a.estimate(opCtx, slotID, socialGroupID)
 	if err != nil {
-		return 0, err
+		return Choice{}, err
 	}
+	banner := estimates[winner]
 
-	stat := make([]bandit.Bandit, len(bannerStat))
-	for i, v := range bannerStat {
-		stat[i] = bandit.Bandit{
-			ID:     int(v.ID),
-			Trials: int(v.Display),
-			Reward: int(v.Click),
+	if a.StatsMode != StatsEvents {
+		err = a.Storage.CountDisplay(opCtx, int64(banner.ID), slotID, socialGroupID)
+		if err != nil {
+			return Choice{}, storageError(err, ErrBannerNotInSlot)
 		}
 	}
 
-	banner, err := bandit.ChooseAlgorithm(stat, totalDisplay)
-	if err != nil {
-		return 0, err
+	choice := Choice{
+		BannerID:     int64(banner.ID),
+		ImpressionID: uuid.NewString(),
 	}
+	metrics.Decision(slotID, choice.BannerID, socialGroupID)
 
-	err = a.Storage.CountTransition(ctx, int64(banner), slotID, socialGroupID)
-	if err != nil {
-		return 0, err
+	a.publishEvent(ctx, events.Event{
+		TenantID:      TenantFromContext(ctx),
+		Type:          events.TypeDisplay,
+		SlotID:        slotID,
+		BannerID:      choice.BannerID,
+		SocialGroupID: socialGroupID,
+		ImpressionID:  choice.ImpressionID,
+		Strategy:      a.Strategy.Name(),
+		Score:         finiteScore(banner.Score),
+	})
+	a.recordDecision(ctx, slotID, socialGroupID, estimates, winner, totalDisplay, choice)
+
+	return choice, nil
+}
+
+// ApplyEvents засчитывает пачку кликов и показов одной транзакцией.
+// Возвращает результат для каждого события: nil, если событие засчитано.
+func (a *App) ApplyEvents(ctx context.Context, batch []events.Event) ([]error, error) {
+	ctx, span := tracing.Start(ctx, "App.ApplyEvents", trace.WithAttributes(
+		attribute.Int("events", len(batch))))
+	defer span.End()
+
+	opCtx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	// события из запроса относятся к арендатору запроса, что бы ни было указано в них
+	tenantID := TenantFromContext(ctx)
+	for i := range batch {
+		batch[i].TenantID = tenantID
+	}
+
+	results := make([]error, len(batch))
+	valid := make([]events.Event, 0, len(batch))
+	for i, e := range batch {
+		err := e.Validate()
+		if err == nil && e.Type == events.TypeDecision {
+			err = errors.New("decisions do not change statistics")
+		}
+		if err != nil {
+			results[i] = ErrInvalidEvent.Wrap(err)
+			continue
+		}
+		valid = append(valid, e)
+	}
+
+	if a.StatsMode != StatsEvents && len(valid) > 0 {
+		missing, err := a.Storage.ApplyStatistics(opCtx, aggregator.Aggregate(valid), nil)
+		if err != nil {
+			return nil, storageError(err)
+		}
+
+		type key struct {
+			slotID, bannerID, socialGroupID int64
+		}
+		notFound := make(map[key]struct{}, len(missing))
+		for _, d := range missing {
+			notFound[key{d.SlotID, d.BannerID, d.SocialGroupID}] = struct{}{}
+		}
+
+		for i, e := range batch {
+			if results[i] != nil {
+				continue
+			}
+			if _, ok := notFound[key{e.SlotID, e.BannerID, e.SocialGroupID}]; ok {
+				results[i] = ErrBannerNotInSlot
+			}
+		}
+	}
+
+	for i, e := range batch {
+		if results[i] != nil {
+			continue
+		}
+		if e.Type == events.TypeClick {
+			metrics.Click(e.SlotID, e.BannerID, e.SocialGroupID)
+		}
+		a.publishEvent(ctx, e)
 	}
 
-	return int64(banner), nil
+	return results, nil
+}
+
+// publishEvent ошибка отправки события не должна ломать показ или клик, поэтому только логируем.
+func (a *App) publishEvent(ctx context.Context, event events.Event) {
+	if a.Publisher == nil {
+		return
+	}
+
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+	if err := a.Publisher.Publish(ctx, event); err != nil {
+		a.Logger.Error("failed to publish event", zap.String("type", string(event.Type)), zap.Error(err))
+	}
 }
@@ -5,7 +5,9 @@ import (
 	"github.com/stretchr/testify/require"
 	"log"
 	"rotator/internal/config"
+	"rotator/internal/events"
 	"testing"
+	"time"
 
 	internallogger "rotator/internal/logger"
 	internalstorage "rotator/internal/storage/sql"
@@ -27,7 +29,7 @@ func TestApp(t *testing.T) {
 		log.Fatalf("Failed logger %s", err)
 	}
 
-	testApp := New(logger, storage)
+	testApp := New(logger, storage, nil)
 
 	t.Run("Test choose banner", func(t *testing.T) {
 		_, err := testApp.ChooseBanner(ctx, 1, 1)
@@ -36,17 +38,17 @@ func TestApp(t *testing.T) {
 
 	t.Run("Test out of data", func(t *testing.T) {
 		_, err := testApp.ChooseBanner(ctx, 1, 5)
-		require.Error(t, err)
+		require.ErrorIs(t, err, ErrNoBannersInSlot)
 	})
 
 	t.Run("Add banner to slot", func(t *testing.T) {
-		err = testApp.AddBannerToSlot(ctx, 2, 2)
+		err = testApp.AddBannerToSlot(ctx, 2, 2, WarmStart{}, Schedule{})
 		require.NoError(t, err)
 	})
 
 	t.Run("Add banner to slot duplicate", func(t *testing.T) {
-		err = testApp.AddBannerToSlot(ctx, 1, 1)
-		require.Error(t, err)
+		err = testApp.AddBannerToSlot(ctx, 1, 1, WarmStart{}, Schedule{})
+		require.ErrorIs(t, err, ErrBannerAlreadyInSlot)
 	})
 
 	t.Run("Remove banner from slot", func(t *testing.T) {
@@ -55,8 +57,142 @@ func TestApp(t *testing.T) {
 	})
 
 	t.Run("Count transition", func(t *testing.T) {
-		err = testApp.CountTransition(ctx, 2, 2, 2)
+		err = testApp.CountTransition(ctx, 1, 1, 1, "")
 		require.NoError(t, err)
 	})
 
+	t.Run("Count transition banner not in slot", func(t *testing.T) {
+		err = testApp.CountTransition(ctx, 2, 2, 2, "")
+		require.ErrorIs(t, err, ErrBannerNotInSlot)
+	})
+
+	t.Run("Add banner to slot with warm start", func(t *testing.T) {
+		err = testApp.AddBannerToSlot(ctx, 2, 2, WarmStart{Source: internalstorage.WarmStartSlot, Displays: 10}, Schedule{})
+		require.NoError(t, err)
+
+		err = testApp.RemoveBannerToSlot(ctx, 2, 2)
+		require.NoError(t, err)
+	})
+
+	t.Run("Warm start requires similar banner", func(t *testing.T) {
+		err = testApp.AddBannerToSlot(ctx, 2, 2, WarmStart{Source: internalstorage.WarmStartBanner}, Schedule{})
+		require.ErrorIs(t, err, ErrInvalidWarmStart)
+
+		err = testApp.AddBannerToSlot(ctx, 2, 2, WarmStart{Source: "unknown"}, Schedule{})
+		require.ErrorIs(t, err, ErrInvalidWarmStart)
+	})
+
+	t.Run("Banner schedule", func(t *testing.T) {
+		expired := Schedule{To: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), Weekdays: []int{1, 2}, Hours: []int{10}}
+		err = testApp.AddBannerToSlot(ctx, 2, 2, WarmStart{}, expired)
+		require.NoError(t, err)
+
+		schedule, err := testApp.GetBannerSchedule(ctx, 2, 2)
+		require.NoError(t, err)
+		require.True(t, expired.To.Equal(schedule.To))
+		require.Equal(t, expired.Weekdays, schedule.Weekdays)
+
+		err = testApp.SetBannerSchedule(ctx, 2, 2, Schedule{Weekdays: []int{8}})
+		require.ErrorIs(t, err, ErrInvalidSchedule)
+
+		err = testApp.RemoveBannerToSlot(ctx, 2, 2)
+		require.NoError(t, err)
+
+		_, err = testApp.GetBannerSchedule(ctx, 2, 2)
+		require.ErrorIs(t, err, ErrBannerNotInSlot)
+	})
+
+	t.Run("Pause banner", func(t *testing.T) {
+		err = testApp.AddBannerToSlot(ctx, 2, 2, WarmStart{}, Schedule{})
+		require.NoError(t, err)
+
+		err = testApp.SetBannerStatus(ctx, 2, 2, internalstorage.BannerPaused)
+		require.NoError(t, err)
+
+		status, err := testApp.GetBannerStatus(ctx, 2, 2)
+		require.NoError(t, err)
+		require.Equal(t, internalstorage.BannerPaused, status)
+
+		err = testApp.SetBannerStatus(ctx, 2, 2, internalstorage.BannerArchived)
+		require.NoError(t, err)
+
+		err = testApp.SetBannerStatus(ctx, 2, 2, internalstorage.BannerActive)
+		require.ErrorIs(t, err, ErrInvalidBannerStatus)
+
+		err = testApp.RemoveBannerToSlot(ctx, 2, 2)
+		require.NoError(t, err)
+
+		err = testApp.SetBannerStatus(ctx, 2, 2, internalstorage.BannerPaused)
+		require.ErrorIs(t, err, ErrBannerNotInSlot)
+	})
+
+	t.Run("Import stats", func(t *testing.T) {
+		results, err := testApp.ImportStats(ctx, ImportAdd, []ImportRow{
+			{SlotID: 1, BannerID: 1, SocialGroupID: 1},
+			{SlotID: 1, BannerID: 1, SocialGroupID: 1},
+			{SlotID: 1, BannerID: 1, SocialGroupID: 2, Display: 1, Click: 2},
+			{SlotID: 2, BannerID: 2, SocialGroupID: 2, Display: 1},
+		})
+		require.NoError(t, err)
+		require.NoError(t, results[0])
+		require.ErrorIs(t, results[1], ErrInvalidImport)
+		require.ErrorIs(t, results[2], ErrInvalidImport)
+		require.ErrorIs(t, results[3], ErrBannerNotInSlot)
+
+		_, err = testApp.ImportStats(ctx, "merge", nil)
+		require.ErrorIs(t, err, ErrInvalidImport)
+	})
+
+	t.Run("Apply events batch", func(t *testing.T) {
+		results, err := testApp.ApplyEvents(ctx, []events.Event{
+			{Type: events.TypeClick, SlotID: 1, BannerID: 1, SocialGroupID: 1},
+			{Type: "unknown", SlotID: 1, BannerID: 1, SocialGroupID: 1},
+		})
+		require.NoError(t, err)
+		require.NoError(t, results[0])
+		require.ErrorIs(t, results[1], ErrValidation)
+	})
+
+	t.Run("Apply events rejects decisions", func(t *testing.T) {
+		results, err := testApp.ApplyEvents(ctx, []events.Event{
+			{Type: events.TypeDecision, SlotID: 1, BannerID: 1, SocialGroupID: 1,
+				Candidates: []events.Candidate{{BannerID: 1}}},
+		})
+		require.NoError(t, err)
+		require.ErrorIs(t, results[0], ErrValidation)
+	})
+
+	t.Run("Decision log", func(t *testing.T) {
+		recorder := &decisionRecorder{}
+		testApp.DecisionLog = recorder
+		testApp.DecisionSampleRate = 1
+		defer func() { testApp.DecisionLog = nil }()
+
+		choice, err := testApp.ChooseBanner(ctx, 1, 1)
+		require.NoError(t, err)
+
+		require.Len(t, recorder.decisions, 1)
+		decision := recorder.decisions[0]
+		require.Equal(t, events.TypeDecision, decision.Type)
+		require.Equal(t, choice.BannerID, decision.BannerID)
+		require.Equal(t, choice.ImpressionID, decision.ImpressionID)
+		require.Equal(t, "ucb1", decision.Strategy)
+		require.Equal(t, map[string]float64{"c": 2}, decision.StrategyParams)
+		require.NotEmpty(t, decision.Candidates)
+
+		var propensity float64
+		for _, c := range decision.Candidates {
+			propensity += c.Propensity
+		}
+		require.InDelta(t, 1, propensity, 1e-9)
+	})
+}
+
+type decisionRecorder struct {
+	decisions []events.Event
+}
+
+func (r *decisionRecorder) Record(_ context.Context, decision events.Event) error {
+	r.decisions = append(r.decisions, decision)
+	return nil
 }
@@ -0,0 +1,208 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	sqlstorage "rotator/internal/storage/sql"
+	"rotator/internal/tracing"
+	"sync"
+	"time"
+)
+
+// Роли ключей API. Admin имеет доступ ко всем операциям.
+const (
+	RoleServing   = "serving"
+	RoleAdmin     = "admin"
+	RoleAnalytics = "analytics"
+)
+
+const (
+	apiKeyPrefix = "rk_"
+	// authCacheTTL сколько проверенный ключ живет в памяти, столько же может действовать отозванный
+	// ключ с ролью serving или analytics. Ключи admin не кешируются.
+	authCacheTTL = time.Second * 30
+)
+
+var (
+	ErrUnauthenticated = &Error{
+		Kind: KindUnauthenticated, Code: "unauthenticated", Message: "missing or invalid api key",
+	}
+	ErrPermissionDenied = &Error{
+		Kind: KindPermissionDenied, Code: "permission_denied", Message: "api key role does not allow this operation",
+	}
+	ErrUnknownRole = &Error{
+		Kind: KindValidation, Code: "unknown_role", Message: "role should be serving, admin or analytics",
+	}
+	ErrAPIKeyNotFound = &Error{
+		Kind: KindNotFound, Code: "api_key_not_found", Message: "api key not found",
+	}
+)
+
+type apiKeyContextKey struct{}
+
+// WithAPIKey кладет ключ, которым аутентифицирован запрос, в контекст.
+func WithAPIKey(ctx context.Context, key *sqlstorage.APIKey) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey{}, key)
+}
+
+// APIKeyFromContext ключ запроса, nil если аутентификация выключена.
+func APIKeyFromContext(ctx context.Context) *sqlstorage.APIKey {
+	key, _ := ctx.Value(apiKeyContextKey{}).(*sqlstorage.APIKey)
+
+	return key
+}
+
+func HashAPIKey(key string) []byte {
+	sum := sha256.Sum256([]byte(key))
+
+	return sum[:]
+}
+
+// CreateAPIKey создает ключ и возвращает его. Ключ показывается только один раз,
+// в хранилище остается хеш.
+func (a *App) CreateAPIKey(ctx context.Context, name, role string) (string, int64, error) {
+	ctx, span := tracing.Start(ctx, "App.CreateAPIKey")
+	defer span.End()
+
+	if role != RoleServing && role != RoleAdmin && role != RoleAnalytics {
+		return "", 0, ErrUnknownRole
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", 0, ErrInternal.Wrap(fmt.Errorf("can't generate api key: %w", err))
+	}
+	key := apiKeyPrefix + hex.EncodeToString(secret)
+
+	opCtx, cancel := context.WithTimeout(ctx, time.Second*3)
+	defer cancel()
+
+	id, err := a.Storage.CreateAPIKey(opCtx, name, role, HashAPIKey(key))
+	if err != nil {
+		return "", 0, storageError(err)
+	}
+
+	return key, id, nil
+}
+
+func (a *App) ListAPIKeys(ctx context.Context) ([]sqlstorage.APIKey, error) {
+	ctx, span := tracing.Start(ctx, "App.ListAPIKeys")
+	defer span.End()
+
+	opCtx, cancel := context.WithTimeout(ctx, time.Second*3)
+	defer cancel()
+
+	keys, err := a.Storage.ListAPIKeys(opCtx)
+
+	return keys, storageError(err)
+}
+
+// RevokeAPIKey сбрасывает кеш ключей только этого процесса. Другие экземпляры сервиса
+// (и сервис, если ключ отозван через rotatorctl) перестают принимать ключ admin сразу,
+// а ключи serving и analytics - не позже чем через authCacheTTL.
+func (a *App) RevokeAPIKey(ctx context.Context, id int64) error {
+	ctx, span := tracing.Start(ctx, "App.RevokeAPIKey")
+	defer span.End()
+
+	opCtx, cancel := context.WithTimeout(ctx, time.Second*3)
+	defer cancel()
+
+	if err := a.Storage.RevokeAPIKey(opCtx, id); err != nil {
+		return storageError(err, ErrAPIKeyNotFound)
+	}
+
+	a.keys.reset()
+
+	return nil
+}
+
+// Authenticate проверяет ключ из запроса.
+func (a *App) Authenticate(ctx context.Context, key string) (*sqlstorage.APIKey, error) {
+	ctx, span := tracing.Start(ctx, "App.Authenticate")
+	defer span.End()
+
+	if key == "" {
+		return nil, ErrUnauthenticated
+	}
+
+	hash := HashAPIKey(key)
+	if cached := a.keys.get(hash); cached != nil {
+		return cached, nil
+	}
+
+	opCtx, cancel := context.WithTimeout(ctx, time.Second*3)
+	defer cancel()
+
+	apiKey, err := a.Storage.GetAPIKeyByHash(opCtx, hash)
+	if err != nil {
+		return nil, storageError(err)
+	}
+
+	if apiKey == nil {
+		return nil, ErrUnauthenticated
+	}
+
+	// ключи admin не кешируются: их отзыв в другом экземпляре должен действовать сразу
+	if apiKey.Role != RoleAdmin {
+		a.keys.put(hash, apiKey)
+	}
+
+	return apiKey, nil
+}
+
+// Authorize проверяет, что роль ключа разрешает операцию с ролью role.
+func Authorize(key *sqlstorage.APIKey, role string) error {
+	if key == nil {
+		return ErrUnauthenticated
+	}
+
+	if key.Role != RoleAdmin && key.Role != role {
+		return ErrPermissionDenied
+	}
+
+	return nil
+}
+
+// keyCache проверенные ключи, чтобы не ходить в базу на каждый запрос.
+type keyCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedKey
+}
+
+type cachedKey struct {
+	key     *sqlstorage.APIKey
+	expires time.Time
+}
+
+func newKeyCache() *keyCache {
+	return &keyCache{entries: make(map[string]cachedKey)}
+}
+
+func (c *keyCache) get(hash []byte) *sqlstorage.APIKey {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[string(hash)]
+	if !ok || time.Now().After(entry.expires) {
+		return nil
+	}
+
+	return entry.key
+}
+
+func (c *keyCache) put(hash []byte, key *sqlstorage.APIKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[string(hash)] = cachedKey{key: key, expires: time.Now().Add(authCacheTTL)}
+}
+
+func (c *keyCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]cachedKey)
+}
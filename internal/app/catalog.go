@@ -0,0 +1,82 @@
+package app
+
+import (
+	"context"
+	sqlstorage "rotator/internal/storage/sql"
+	"rotator/internal/tracing"
+	"time"
+)
+
+// Справочники сервиса: баннеры, слоты и соц.группы.
+const (
+	CatalogBanner      = sqlstorage.CatalogBanner
+	CatalogSlot        = sqlstorage.CatalogSlot
+	CatalogSocialGroup = sqlstorage.CatalogSocialGroup
+)
+
+func (a *App) CreateCatalogItem(ctx context.Context, catalog, description string) (int64, error) {
+	ctx, span := tracing.Start(ctx, "App.CreateCatalogItem")
+	defer span.End()
+
+	opCtx, cancel := context.WithTimeout(ctx, time.Second*3)
+	defer cancel()
+
+	id, err := a.Storage.CreateCatalogItem(opCtx, catalog, description)
+
+	return id, storageError(err)
+}
+
+func (a *App) GetCatalogItem(ctx context.Context, catalog string, id int64) (*sqlstorage.CatalogItem, error) {
+	ctx, span := tracing.Start(ctx, "App.GetCatalogItem")
+	defer span.End()
+
+	opCtx, cancel := context.WithTimeout(ctx, time.Second*3)
+	defer cancel()
+
+	item, err := a.Storage.GetCatalogItem(opCtx, catalog, id)
+	if err != nil {
+		return nil, storageError(err, ErrCatalogItemNotFound)
+	}
+
+	if item == nil {
+		return nil, ErrCatalogItemNotFound
+	}
+
+	return item, nil
+}
+
+func (a *App) ListCatalogItems(ctx context.Context, catalog string) ([]sqlstorage.CatalogItem, error) {
+	ctx, span := tracing.Start(ctx, "App.ListCatalogItems")
+	defer span.End()
+
+	opCtx, cancel := context.WithTimeout(ctx, time.Second*3)
+	defer cancel()
+
+	items, err := a.Storage.ListCatalogItems(opCtx, catalog)
+
+	return items, storageError(err)
+}
+
+func (a *App) UpdateCatalogItem(ctx context.Context, catalog string, id int64, description string) error {
+	ctx, span := tracing.Start(ctx, "App.UpdateCatalogItem")
+	defer span.End()
+
+	opCtx, cancel := context.WithTimeout(ctx, time.Second*3)
+	defer cancel()
+
+	err := a.Storage.UpdateCatalogItem(opCtx, catalog, id, description)
+
+	return storageError(err, ErrCatalogItemNotFound)
+}
+
+func (a *App) DeleteCatalogItem(ctx context.Context, catalog string, id int64) error {
+	ctx, span := tracing.Start(ctx, "App.DeleteCatalogItem")
+	defer span.End()
+
+	opCtx, cancel := context.WithTimeout(ctx, time.Second*3)
+	defer cancel()
+
+	err := a.Storage.DeleteCatalogItem(opCtx, catalog, id)
+
+	return storageError(err, ErrCatalogItemNotFound)
+}
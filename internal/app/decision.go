@@ -0,0 +1,68 @@
+package app
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"math"
+	"math/rand"
+	bandit "rotator/internal/alghoritms"
+	"rotator/internal/events"
+	"time"
+)
+
+// DecisionLog журнал решений стратегии, см. пакет decisionlog.
+type DecisionLog interface {
+	Record(ctx context.Context, decision events.Event) error
+}
+
+// finiteScore бесконечную оценку баннера без показов не закодировать в событие, вместо нее 0.
+func finiteScore(score float64) float64 {
+	if math.IsInf(score, 0) {
+		return 0
+	}
+
+	return score
+}
+
+// recordDecision записывает в журнал долю DecisionSampleRate решений. Ошибка журнала
+// не должна ломать показ, поэтому только логируем.
+func (a *App) recordDecision(ctx context.Context, slotID, socialGroupID int64,
+	estimates []bandit.Estimate, winner, totalDisplay int, choice Choice,
+) {
+	if a.DecisionLog == nil || a.DecisionSampleRate <= 0 {
+		return
+	}
+	if a.DecisionSampleRate < 1 && rand.Float64() >= a.DecisionSampleRate { //nolint:gosec
+		return
+	}
+
+	candidates := make([]events.Candidate, len(estimates))
+	for i, e := range estimates {
+		candidates[i] = events.Candidate{
+			BannerID:   int64(e.ID),
+			Display:    int64(e.Trials),
+			Click:      int64(e.Reward),
+			Score:      finiteScore(e.Score),
+			Unexplored: math.IsInf(e.Score, 1),
+			Propensity: e.Propensity,
+		}
+	}
+
+	decision := events.Event{
+		TenantID:       TenantFromContext(ctx),
+		Type:           events.TypeDecision,
+		SlotID:         slotID,
+		BannerID:       choice.BannerID,
+		SocialGroupID:  socialGroupID,
+		Timestamp:      time.Now().UTC(),
+		ImpressionID:   choice.ImpressionID,
+		Strategy:       a.Strategy.Name(),
+		Score:          finiteScore(estimates[winner].Score),
+		Candidates:     candidates,
+		StrategyParams: a.Strategy.Params(),
+		TotalDisplay:   int64(totalDisplay),
+	}
+	if err := a.DecisionLog.Record(ctx, decision); err != nil {
+		a.Logger.Error("failed to record decision", zap.Int64("slot", slotID), zap.Error(err))
+	}
+}
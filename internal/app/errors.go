@@ -0,0 +1,178 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	sqlstorage "rotator/internal/storage/sql"
+)
+
+// ErrorKind класс ошибки, по нему транспорт выбирает статус ответа.
+type ErrorKind int
+
+const (
+	KindInternal ErrorKind = iota
+	KindNotFound
+	KindAlreadyExists
+	KindValidation
+	KindUnavailable
+	KindUnauthenticated
+	KindPermissionDenied
+)
+
+// Error доменная ошибка. Code - стабильный код для клиентов, Message - описание
+// для человека, Err - исходная ошибка, наружу не отдается.
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+	Err     error
+}
+
+// Классы ошибок для проверки через errors.Is, совпадают с любой ошибкой своего класса.
+var (
+	ErrNotFound      = &Error{Kind: KindNotFound}
+	ErrAlreadyExists = &Error{Kind: KindAlreadyExists}
+	ErrValidation    = &Error{Kind: KindValidation}
+	ErrUnavailable   = &Error{Kind: KindUnavailable}
+)
+
+var (
+	ErrSlotNotFound = &Error{
+		Kind: KindNotFound, Code: "slot_not_found", Message: "slot not found",
+	}
+	ErrNoBannersInSlot = &Error{
+		Kind: KindNotFound, Code: "no_banners_in_slot", Message: "no banners in slot for social group",
+	}
+	ErrBannerNotInSlot = &Error{
+		Kind: KindNotFound, Code: "banner_not_in_slot", Message: "banner is not in slot or social group is unknown",
+	}
+	ErrBannerOrSlotNotFound = &Error{
+		Kind: KindNotFound, Code: "banner_or_slot_not_found", Message: "banner or slot not found",
+	}
+	ErrBannerAlreadyInSlot = &Error{
+		Kind: KindAlreadyExists, Code: "banner_already_in_slot", Message: "banner is already in slot",
+	}
+	ErrCatalogItemNotFound = &Error{
+		Kind: KindNotFound, Code: "catalog_item_not_found", Message: "catalog item not found",
+	}
+	ErrUnknownCatalog = &Error{
+		Kind: KindValidation, Code: "unknown_catalog", Message: "unknown catalog",
+	}
+	ErrInvalidEvent = &Error{
+		Kind: KindValidation, Code: "invalid_event", Message: "invalid event",
+	}
+	ErrInvalidStatsQuery = &Error{
+		Kind: KindValidation, Code: "invalid_stats_query", Message: "invalid stats query",
+	}
+	ErrInvalidImport = &Error{
+		Kind: KindValidation, Code: "invalid_import", Message: "invalid statistics import",
+	}
+	ErrInvalidWarmStart = &Error{
+		Kind: KindValidation, Code: "invalid_warm_start", Message: "invalid warm start",
+	}
+	ErrInvalidPrior = &Error{
+		Kind: KindValidation, Code: "invalid_prior", Message: "invalid prior",
+	}
+	ErrInvalidSchedule = &Error{
+		Kind: KindValidation, Code: "invalid_schedule", Message: "invalid schedule",
+	}
+	ErrInvalidBannerStatus = &Error{
+		Kind: KindValidation, Code: "invalid_banner_status", Message: "invalid banner status",
+	}
+	ErrStorageUnavailable = &Error{
+		Kind: KindUnavailable, Code: "storage_unavailable", Message: "storage is unavailable",
+	}
+	ErrInternal = &Error{
+		Kind: KindInternal, Code: "internal", Message: "internal error",
+	}
+)
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s", e.Message, e.Err)
+	}
+
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is ошибки совпадают по классу и коду, пустой код у target означает любой код класса.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+
+	return t.Kind == e.Kind && (t.Code == "" || t.Code == e.Code)
+}
+
+// PublicMessage текст для клиента. Причину показываем только у ошибок валидации,
+// в остальных она может содержать детали хранилища.
+func (e *Error) PublicMessage() string {
+	if e.Kind == KindValidation {
+		return e.Error()
+	}
+
+	return e.Message
+}
+
+// Wrap возвращает копию ошибки с исходной причиной.
+func (e *Error) Wrap(err error) *Error {
+	wrapped := *e
+	wrapped.Err = err
+
+	return &wrapped
+}
+
+// AsError приводит любую ошибку к доменной, неизвестные ошибки считаются внутренними.
+func AsError(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	return ErrInternal.Wrap(err)
+}
+
+// storageError переводит ошибку хранилища в доменную. known уточняет код
+// для классов ошибок, которые ожидаются в конкретной операции.
+func storageError(err error, known ...*Error) error {
+	if err == nil {
+		return nil
+	}
+
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return err
+	}
+
+	kind := KindInternal
+	switch {
+	case errors.Is(err, sqlstorage.ErrNotFound):
+		kind = KindNotFound
+	case errors.Is(err, sqlstorage.ErrAlreadyExists):
+		kind = KindAlreadyExists
+	case errors.Is(err, sqlstorage.ErrUnavailable):
+		return ErrStorageUnavailable.Wrap(err)
+	case errors.Is(err, sqlstorage.ErrUnknownCatalog):
+		return ErrUnknownCatalog.Wrap(err)
+	}
+
+	for _, k := range known {
+		if k.Kind == kind {
+			return k.Wrap(err)
+		}
+	}
+
+	switch kind {
+	case KindNotFound:
+		return (&Error{Kind: KindNotFound, Code: "not_found", Message: "not found"}).Wrap(err)
+	case KindAlreadyExists:
+		return (&Error{Kind: KindAlreadyExists, Code: "already_exists", Message: "already exists"}).Wrap(err)
+	default:
+		return ErrInternal.Wrap(err)
+	}
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	bandit "rotator/internal/alghoritms"
+	"rotator/internal/tracing"
+	"sort"
+	"time"
+)
+
+// Explanation почему стратегия выбрала бы баннер: оценки всех баннеров слота в соц.группе.
+type Explanation struct {
+	Strategy     string
+	TotalDisplay int
+	WinnerID     int64
+	// Candidates отсортированы по убыванию оценки, победитель первый
+	Candidates []bandit.Estimate
+}
+
+// estimate оценки баннеров слота активной стратегией и индекс победителя.
+func (a *App) estimate(ctx context.Context, slotID, socialGroupID int64) ([]bandit.Estimate, int, int, error) {
+	bannerStat, totalDisplay, err := a.Storage.GetBannersStat(ctx, slotID, socialGroupID, time.Now().In(a.Location))
+	if err != nil {
+		return nil, 0, 0, storageError(err, ErrSlotNotFound)
+	}
+
+	if len(bannerStat) == 0 {
+		return nil, 0, 0, ErrNoBannersInSlot
+	}
+
+	stat := make([]bandit.Bandit, len(bannerStat))
+	for i, v := range bannerStat {
+		prior := a.slotPrior(v.SlotPriorAlpha, v.SlotPriorBeta)
+		stat[i] = bandit.Bandit{
+			ID:     int(v.ID),
+			Trials: int(v.Display),
+			Reward: int(v.Click),
+			Alpha:  prior.Alpha + v.PriorAlpha,
+			Beta:   prior.Beta + v.PriorBeta,
+		}
+	}
+
+	estimates := a.Strategy.Estimate(stat, totalDisplay)
+	winner, err := bandit.Best(estimates)
+	if err != nil {
+		return nil, 0, 0, ErrInternal.Wrap(err)
+	}
+
+	return estimates, winner, totalDisplay, nil
+}
+
+// ExplainBanner оценки, по которым ChooseBanner выбрал бы баннер. Показ не засчитывается.
+func (a *App) ExplainBanner(ctx context.Context, slotID, socialGroupID int64) (Explanation, error) {
+	ctx, span := tracing.Start(ctx, "App.ExplainBanner", trace.WithAttributes(
+		attribute.Int64("slot.id", slotID), attribute.Int64("social_group.id", socialGroupID)))
+	defer span.End()
+
+	opCtx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	estimates, winner, totalDisplay, err := a.estimate(opCtx, slotID, socialGroupID)
+	if err != nil {
+		return Explanation{}, err
+	}
+
+	explanation := Explanation{
+		Strategy:     a.Strategy.Name(),
+		TotalDisplay: totalDisplay,
+		WinnerID:     int64(estimates[winner].ID),
+		Candidates:   make([]bandit.Estimate, 0, len(estimates)),
+	}
+
+	// победитель первый, остальные по убыванию оценки
+	explanation.Candidates = append(explanation.Candidates, estimates[winner])
+	rest := append(append([]bandit.Estimate{}, estimates[:winner]...), estimates[winner+1:]...)
+	sort.SliceStable(rest, func(i, j int) bool {
+		return rest[i].Score > rest[j].Score
+	})
+	explanation.Candidates = append(explanation.Candidates, rest...)
+
+	return explanation, nil
+}
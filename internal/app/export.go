@@ -0,0 +1,71 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	sqlstorage "rotator/internal/storage/sql"
+	"rotator/internal/tracing"
+	"time"
+)
+
+// ExportQuery Interval пустой - накопленная статистика, иначе по периодам sqlstorage.Interval*
+// в [From, To). Нулевые фильтры и границы не ограничивают выгрузку.
+type ExportQuery struct {
+	Interval      string
+	SlotID        int64
+	BannerID      int64
+	SocialGroupID int64
+	From          time.Time
+	To            time.Time
+}
+
+func (q ExportQuery) validate() error {
+	switch q.Interval {
+	case "":
+		if !q.From.IsZero() || !q.To.IsZero() {
+			return ErrInvalidStatsQuery.Wrap(errors.New("date range requires interval"))
+		}
+	case sqlstorage.IntervalHour, sqlstorage.IntervalDay:
+	default:
+		return ErrInvalidStatsQuery.Wrap(fmt.Errorf("unknown interval %q", q.Interval))
+	}
+
+	if !q.From.IsZero() && !q.To.IsZero() && !q.From.Before(q.To) {
+		return ErrInvalidStatsQuery.Wrap(errors.New("from should be before to"))
+	}
+
+	return nil
+}
+
+// ExportStats передает в fn по одной строке статистики на слот, баннер, соц.группу
+// (и период). Ошибка fn прерывает выгрузку и возвращается как есть.
+func (a *App) ExportStats(ctx context.Context, query ExportQuery, fn func(StatsPoint) error) error {
+	ctx, span := tracing.Start(ctx, "App.ExportStats", trace.WithAttributes(
+		attribute.String("interval", query.Interval)))
+	defer span.End()
+
+	if err := query.validate(); err != nil {
+		return err
+	}
+
+	opCtx, cancel := context.WithTimeout(ctx, time.Minute*10)
+	defer cancel()
+
+	var fnErr error
+	err := a.Storage.ExportStatistics(opCtx, sqlstorage.StatisticsFilter{
+		SlotID:        query.SlotID,
+		BannerID:      query.BannerID,
+		SocialGroupID: query.SocialGroupID,
+	}, query.Interval, query.From, query.To, func(p sqlstorage.StatisticsPoint) error {
+		fnErr = fn(StatsPoint{Time: p.Time, StatsRow: statsRow(p.StatisticsRow, DefaultConfidence)})
+		return fnErr
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+
+	return storageError(err)
+}
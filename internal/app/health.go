@@ -0,0 +1,64 @@
+package app
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"time"
+)
+
+// Состояния зависимостей в ответе /readyz.
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// DependencyCheck состояние одной зависимости. Required - без нее сервис не готов принимать запросы.
+type DependencyCheck struct {
+	Status   string `json:"status"`
+	Required bool   `json:"required"`
+	Error    string `json:"error,omitempty"`
+}
+
+type Readiness struct {
+	Ready  bool                       `json:"ready"`
+	Checks map[string]DependencyCheck `json:"checks"`
+}
+
+// connectionReporter издатель, который знает, есть ли соединение с брокером.
+type connectionReporter interface {
+	Connected() bool
+}
+
+// Readiness проверяет базу и соединение издателя событий. Брокер обязателен только
+// в режиме events, в режиме direct без него теряются лишь события для аналитики.
+func (a *App) Readiness(ctx context.Context) Readiness {
+	opCtx, cancel := context.WithTimeout(ctx, time.Second*2)
+	defer cancel()
+
+	result := Readiness{Ready: true, Checks: make(map[string]DependencyCheck)}
+	add := func(name string, check DependencyCheck) {
+		result.Checks[name] = check
+		if check.Required && check.Status != StatusUp {
+			result.Ready = false
+		}
+	}
+
+	storage := DependencyCheck{Status: StatusUp, Required: true}
+	if err := a.Storage.Ping(opCtx); err != nil {
+		a.Logger.Error("[-] Storage is not ready", zap.Error(err))
+		storage.Status = StatusDown
+		storage.Error = "database is unreachable"
+	}
+	add("storage", storage)
+
+	if reporter, ok := a.Publisher.(connectionReporter); ok {
+		publisher := DependencyCheck{Status: StatusUp, Required: a.StatsMode == StatsEvents}
+		if !reporter.Connected() {
+			publisher.Status = StatusDown
+			publisher.Error = "not connected to broker"
+		}
+		add("publisher", publisher)
+	}
+
+	return result
+}
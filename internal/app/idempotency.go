@@ -0,0 +1,92 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	sqlstorage "rotator/internal/storage/sql"
+	"rotator/internal/tracing"
+	"time"
+)
+
+const (
+	defaultIdempotencyTTL = time.Hour * 24
+	// idempotencyInFlight через сколько незавершенный запрос считается брошенным
+	idempotencyInFlight = time.Minute
+)
+
+var (
+	ErrIdempotencyKeyReused = &Error{
+		Kind: KindValidation, Code: "idempotency_key_reused",
+		Message: "idempotency key was already used with a different request",
+	}
+	ErrIdempotencyKeyInProgress = &Error{
+		Kind: KindAlreadyExists, Code: "idempotency_key_in_progress",
+		Message: "request with this idempotency key is in progress",
+	}
+)
+
+// BeginIdempotent занимает ключ идемпотентности под запрос. Если запрос с этим ключом
+// уже выполнен, возвращает сохраненный ответ, который нужно отдать клиенту повторно.
+func (a *App) BeginIdempotent(ctx context.Context, key string, requestHash []byte) (*sqlstorage.IdempotencyKey, error) {
+	ctx, span := tracing.Start(ctx, "App.BeginIdempotent")
+	defer span.End()
+
+	opCtx, cancel := context.WithTimeout(ctx, time.Second*3)
+	defer cancel()
+
+	now := time.Now()
+	existing, reserved, err := a.Storage.ReserveIdempotencyKey(opCtx, key, requestHash,
+		now.Add(a.IdempotencyTTL), now.Add(-idempotencyInFlight))
+	if err != nil {
+		return nil, storageError(err)
+	}
+
+	if reserved {
+		return nil, nil
+	}
+
+	if !bytes.Equal(existing.RequestHash, requestHash) {
+		return nil, ErrIdempotencyKeyReused
+	}
+
+	if existing.StatusCode == 0 {
+		return nil, ErrIdempotencyKeyInProgress
+	}
+
+	return existing, nil
+}
+
+// FinishIdempotent сохраняет ответ на запрос для повторов с тем же ключом.
+func (a *App) FinishIdempotent(ctx context.Context, key string, statusCode int, contentType string, body []byte) error {
+	ctx, span := tracing.Start(ctx, "App.FinishIdempotent")
+	defer span.End()
+
+	opCtx, cancel := context.WithTimeout(ctx, time.Second*3)
+	defer cancel()
+
+	return storageError(a.Storage.SaveIdempotentResponse(opCtx, key, statusCode, contentType, body))
+}
+
+// ReleaseIdempotent освобождает ключ, если запрос не удался и его можно повторить.
+func (a *App) ReleaseIdempotent(ctx context.Context, key string) error {
+	ctx, span := tracing.Start(ctx, "App.ReleaseIdempotent")
+	defer span.End()
+
+	opCtx, cancel := context.WithTimeout(ctx, time.Second*3)
+	defer cancel()
+
+	return storageError(a.Storage.ReleaseIdempotencyKey(opCtx, key))
+}
+
+// PurgeIdempotencyKeys удаляет истекшие ключи.
+func (a *App) PurgeIdempotencyKeys(ctx context.Context) (int64, error) {
+	ctx, span := tracing.Start(ctx, "App.PurgeIdempotencyKeys")
+	defer span.End()
+
+	opCtx, cancel := context.WithTimeout(ctx, time.Second*30)
+	defer cancel()
+
+	deleted, err := a.Storage.DeleteExpiredIdempotencyKeys(opCtx)
+
+	return deleted, storageError(err)
+}
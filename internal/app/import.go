@@ -0,0 +1,134 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	sqlstorage "rotator/internal/storage/sql"
+	"rotator/internal/tracing"
+	"time"
+)
+
+// Режимы загрузки статистики: заменить накопленные счетчики или прибавить к ним.
+const (
+	ImportReplace = "replace"
+	ImportAdd     = "add"
+)
+
+// DefaultWarmStartDisplays сколько показов чужой статистики получает новый баннер, если не задано.
+const DefaultWarmStartDisplays = 100
+
+// WarmStart Начальная статистика баннера при добавлении в слот. Source sqlstorage.WarmStart*,
+// пустой - без нее. BannerID - похожий баннер для sqlstorage.WarmStartBanner.
+type WarmStart struct {
+	Source   string
+	BannerID int64
+	Displays int64
+}
+
+// ImportRow Накопленные показы и клики баннера в слоте и соц.группе.
+type ImportRow struct {
+	SlotID        int64
+	BannerID      int64
+	SocialGroupID int64
+	Display       int64
+	Click         int64
+}
+
+// validate проверяет источник и подставляет число показов по умолчанию.
+func (w *WarmStart) validate(bannerID int64) error {
+	switch w.Source {
+	case "":
+		return nil
+	case sqlstorage.WarmStartBanner:
+		if w.BannerID <= 0 {
+			return ErrInvalidWarmStart.Wrap(errors.New("similar banner is required"))
+		}
+		if w.BannerID == bannerID {
+			return ErrInvalidWarmStart.Wrap(errors.New("banner can't warm start from itself"))
+		}
+	case sqlstorage.WarmStartSlot:
+		if w.BannerID != 0 {
+			return ErrInvalidWarmStart.Wrap(errors.New("similar banner is used only with banner source"))
+		}
+	default:
+		return ErrInvalidWarmStart.Wrap(fmt.Errorf("unknown source %q", w.Source))
+	}
+
+	if w.Displays == 0 {
+		w.Displays = DefaultWarmStartDisplays
+	}
+	if w.Displays < 0 {
+		return ErrInvalidWarmStart.Wrap(errors.New("displays should be positive"))
+	}
+
+	return nil
+}
+
+func (r ImportRow) validate() error {
+	if r.SlotID <= 0 || r.BannerID <= 0 || r.SocialGroupID <= 0 {
+		return errors.New("slot_id, banner_id and social_group_id are required")
+	}
+	if r.Display < 0 || r.Click < 0 {
+		return errors.New("counters should not be negative")
+	}
+	if r.Click > r.Display {
+		return errors.New("click should not exceed display")
+	}
+
+	return nil
+}
+
+// ImportStats Загружает накопленные показы и клики, например из прежнего ротатора, минуя очередь
+// событий. Возвращает результат для каждой строки: nil, если строка записана. Строки с одним
+// и тем же баннером в слоте и соц.группе в одной загрузке не допускаются.
+func (a *App) ImportStats(ctx context.Context, mode string, rows []ImportRow) ([]error, error) {
+	ctx, span := tracing.Start(ctx, "App.ImportStats", trace.WithAttributes(
+		attribute.String("mode", mode), attribute.Int("rows", len(rows))))
+	defer span.End()
+
+	if mode != ImportReplace && mode != ImportAdd {
+		return nil, ErrInvalidImport.Wrap(fmt.Errorf("unknown mode %q", mode))
+	}
+
+	opCtx, cancel := context.WithTimeout(ctx, time.Second*30)
+	defer cancel()
+
+	results := make([]error, len(rows))
+	valid := make([]sqlstorage.StatisticsImport, 0, len(rows))
+	index := make([]int, 0, len(rows))
+	seen := make(map[ImportRow]struct{}, len(rows))
+	for i, r := range rows {
+		err := r.validate()
+		key := ImportRow{SlotID: r.SlotID, BannerID: r.BannerID, SocialGroupID: r.SocialGroupID}
+		if _, ok := seen[key]; ok && err == nil {
+			err = errors.New("duplicate row")
+		}
+		if err != nil {
+			results[i] = ErrInvalidImport.Wrap(err)
+			continue
+		}
+		seen[key] = struct{}{}
+
+		valid = append(valid, sqlstorage.StatisticsImport{
+			SlotID:        r.SlotID,
+			BannerID:      r.BannerID,
+			SocialGroupID: r.SocialGroupID,
+			Display:       r.Display,
+			Click:         r.Click,
+		})
+		index = append(index, i)
+	}
+
+	missing, err := a.Storage.ImportStatistics(opCtx, valid, mode == ImportReplace)
+	if err != nil {
+		return nil, storageError(err)
+	}
+	for _, i := range missing {
+		results[index[i]] = ErrBannerNotInSlot
+	}
+
+	return results, nil
+}
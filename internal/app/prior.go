@@ -0,0 +1,74 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	bandit "rotator/internal/alghoritms"
+	"rotator/internal/tracing"
+	"time"
+)
+
+// SlotPrior псевдосчетчики слота. Default - у слота нет своих, действуют App.Prior.
+type SlotPrior struct {
+	SlotID  int64
+	Default bool
+	bandit.Prior
+}
+
+// slotPrior псевдосчетчики слота или значения по умолчанию, если своих нет.
+func (a *App) slotPrior(alpha, beta *float64) bandit.Prior {
+	if alpha == nil || beta == nil {
+		return a.Prior
+	}
+
+	return bandit.Prior{Alpha: *alpha, Beta: *beta}
+}
+
+func (a *App) GetSlotPrior(ctx context.Context, slotID int64) (SlotPrior, error) {
+	ctx, span := tracing.Start(ctx, "App.GetSlotPrior", trace.WithAttributes(
+		attribute.Int64("slot.id", slotID)))
+	defer span.End()
+
+	opCtx, cancel := context.WithTimeout(ctx, time.Second*3)
+	defer cancel()
+
+	slot, err := a.Storage.GetSlotByID(opCtx, slotID)
+	if err != nil {
+		return SlotPrior{}, storageError(err)
+	}
+	if slot == nil {
+		return SlotPrior{}, ErrSlotNotFound
+	}
+
+	return SlotPrior{
+		SlotID:  slotID,
+		Default: slot.PriorAlpha == nil || slot.PriorBeta == nil,
+		Prior:   a.slotPrior(slot.PriorAlpha, slot.PriorBeta),
+	}, nil
+}
+
+// SetSlotPrior задает псевдосчетчики слота, nil - вернуть значения по умолчанию.
+func (a *App) SetSlotPrior(ctx context.Context, slotID int64, prior *bandit.Prior) (SlotPrior, error) {
+	ctx, span := tracing.Start(ctx, "App.SetSlotPrior", trace.WithAttributes(
+		attribute.Int64("slot.id", slotID)))
+	defer span.End()
+
+	var alpha, beta *float64
+	if prior != nil {
+		if prior.Alpha < 0 || prior.Beta < 0 {
+			return SlotPrior{}, ErrInvalidPrior.Wrap(errors.New("alpha and beta should not be negative"))
+		}
+		alpha, beta = &prior.Alpha, &prior.Beta
+	}
+
+	opCtx, cancel := context.WithTimeout(ctx, time.Second*3)
+	defer cancel()
+
+	if err := a.Storage.SetSlotPrior(opCtx, slotID, alpha, beta); err != nil {
+		return SlotPrior{}, storageError(err, ErrSlotNotFound)
+	}
+
+	return SlotPrior{SlotID: slotID, Default: prior == nil, Prior: a.slotPrior(alpha, beta)}, nil
+}
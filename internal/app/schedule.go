@@ -0,0 +1,99 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	sqlstorage "rotator/internal/storage/sql"
+	"rotator/internal/tracing"
+	"time"
+)
+
+// Schedule Окно активности баннера в слоте: [From, To), нулевое время не ограничивает.
+// Weekdays - дни недели от 1 (понедельник) до 7, Hours - часы от 0 до 23 в часовом поясе
+// App.Location, пустые - без ограничения. Вне окна баннер не участвует в выборе.
+type Schedule struct {
+	From     time.Time
+	To       time.Time
+	Weekdays []int
+	Hours    []int
+}
+
+// storage проверяет окно и переводит дни и часы в маски.
+func (s Schedule) storage() (sqlstorage.Schedule, error) {
+	if !s.From.IsZero() && !s.To.IsZero() && !s.From.Before(s.To) {
+		return sqlstorage.Schedule{}, ErrInvalidSchedule.Wrap(errors.New("from should be before to"))
+	}
+
+	result := sqlstorage.Schedule{From: s.From, To: s.To}
+	for _, day := range s.Weekdays {
+		if day < 1 || day > 7 {
+			return sqlstorage.Schedule{}, ErrInvalidSchedule.Wrap(fmt.Errorf("weekday %d is not between 1 and 7", day))
+		}
+		result.Weekdays |= 1 << (day - 1)
+	}
+	for _, hour := range s.Hours {
+		if hour < 0 || hour > 23 {
+			return sqlstorage.Schedule{}, ErrInvalidSchedule.Wrap(fmt.Errorf("hour %d is not between 0 and 23", hour))
+		}
+		result.Hours |= 1 << hour
+	}
+
+	return result, nil
+}
+
+func scheduleFromStorage(s sqlstorage.Schedule) Schedule {
+	result := Schedule{From: s.From, To: s.To}
+	for day := 1; day <= 7; day++ {
+		if s.Weekdays&(1<<(day-1)) != 0 {
+			result.Weekdays = append(result.Weekdays, day)
+		}
+	}
+	for hour := 0; hour < 24; hour++ {
+		if s.Hours&(1<<hour) != 0 {
+			result.Hours = append(result.Hours, hour)
+		}
+	}
+
+	return result
+}
+
+func (a *App) GetBannerSchedule(ctx context.Context, bannerID, slotID int64) (Schedule, error) {
+	ctx, span := tracing.Start(ctx, "App.GetBannerSchedule", trace.WithAttributes(
+		attribute.Int64("banner.id", bannerID), attribute.Int64("slot.id", slotID)))
+	defer span.End()
+
+	opCtx, cancel := context.WithTimeout(ctx, time.Second*3)
+	defer cancel()
+
+	schedule, err := a.Storage.GetBannerSchedule(opCtx, bannerID, slotID)
+	if err != nil {
+		return Schedule{}, storageError(err)
+	}
+	if schedule == nil {
+		return Schedule{}, ErrBannerNotInSlot
+	}
+
+	return scheduleFromStorage(*schedule), nil
+}
+
+// SetBannerSchedule заменяет окно активности баннера в слоте, нулевое окно снимает ограничения.
+func (a *App) SetBannerSchedule(ctx context.Context, bannerID, slotID int64, schedule Schedule) error {
+	ctx, span := tracing.Start(ctx, "App.SetBannerSchedule", trace.WithAttributes(
+		attribute.Int64("banner.id", bannerID), attribute.Int64("slot.id", slotID)))
+	defer span.End()
+
+	stored, err := schedule.storage()
+	if err != nil {
+		return err
+	}
+
+	opCtx, cancel := context.WithTimeout(ctx, time.Second*3)
+	defer cancel()
+
+	err = a.Storage.SetBannerSchedule(opCtx, bannerID, slotID, stored)
+
+	return storageError(err, ErrBannerNotInSlot)
+}
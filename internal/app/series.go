@@ -0,0 +1,152 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	sqlstorage "rotator/internal/storage/sql"
+	"rotator/internal/tracing"
+	"time"
+)
+
+const (
+	defaultStatsRollupAfter = time.Hour * 24 * 7
+	// maxSeriesPoints сколько периодов можно запросить за раз
+	maxSeriesPoints = 2000
+)
+
+// SeriesQuery ряд за [From, To) с шагом Interval (sqlstorage.Interval*). По умолчанию
+// шаг - час, To - сейчас, From - 24 периода назад. Границы расширяются до целых периодов:
+// From вниз, To вверх. Дни считаются по UTC, как при сворачивании часовой статистики.
+type SeriesQuery struct {
+	StatsQuery
+	Interval string
+	From     time.Time
+	To       time.Time
+}
+
+// StatsPoint статистика группы за период, который начинается в Time.
+type StatsPoint struct {
+	Time time.Time
+	StatsRow
+}
+
+type Series struct {
+	GroupBy    []string
+	Confidence float64
+	Interval   string
+	From       time.Time
+	To         time.Time
+	Points     []StatsPoint
+}
+
+func (q *SeriesQuery) validate() error {
+	if err := q.StatsQuery.validate(); err != nil {
+		return err
+	}
+
+	var step time.Duration
+	switch q.Interval {
+	case "", sqlstorage.IntervalHour:
+		q.Interval, step = sqlstorage.IntervalHour, time.Hour
+	case sqlstorage.IntervalDay:
+		step = time.Hour * 24
+	default:
+		return ErrInvalidStatsQuery.Wrap(fmt.Errorf("unknown interval %q", q.Interval))
+	}
+
+	if q.To.IsZero() {
+		q.To = time.Now()
+	}
+	q.To = truncateUp(q.To.UTC(), step)
+	if q.From.IsZero() {
+		q.From = q.To.Add(-step * 24)
+	}
+	// иначе период, в котором начинается From, не попадет в ряд: его начало раньше From
+	q.From = q.From.UTC().Truncate(step)
+	if !q.From.Before(q.To) {
+		return ErrInvalidStatsQuery.Wrap(errors.New("from should be before to"))
+	}
+	if q.To.Sub(q.From) > step*maxSeriesPoints {
+		return ErrInvalidStatsQuery.Wrap(fmt.Errorf("range is limited to %d periods", maxSeriesPoints))
+	}
+
+	return nil
+}
+
+// truncateUp округляет t вверх до целого периода.
+func truncateUp(t time.Time, step time.Duration) time.Time {
+	truncated := t.Truncate(step)
+	if truncated.Before(t) {
+		return truncated.Add(step)
+	}
+
+	return truncated
+}
+
+// GetStatsSeries показы, клики и CTR по часам или дням. Часовая статистика старше
+// StatsRollupAfter свернута в дневную и есть только в ряду по дням.
+func (a *App) GetStatsSeries(ctx context.Context, query SeriesQuery) (Series, error) {
+	ctx, span := tracing.Start(ctx, "App.GetStatsSeries", trace.WithAttributes(
+		attribute.String("interval", query.Interval)))
+	defer span.End()
+
+	if err := query.validate(); err != nil {
+		return Series{}, err
+	}
+
+	opCtx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	points, err := a.Storage.GetStatisticsSeries(opCtx, sqlstorage.StatisticsFilter{
+		SlotID:        query.SlotID,
+		BannerID:      query.BannerID,
+		SocialGroupID: query.SocialGroupID,
+	}, query.GroupBy, query.Interval, query.From, query.To)
+	if err != nil {
+		return Series{}, storageError(err)
+	}
+
+	result := Series{
+		GroupBy:    query.GroupBy,
+		Confidence: query.Confidence,
+		Interval:   query.Interval,
+		From:       query.From,
+		To:         query.To,
+		Points:     make([]StatsPoint, len(points)),
+	}
+	if result.GroupBy == nil {
+		result.GroupBy = []string{}
+	}
+	for i, p := range points {
+		result.Points[i] = StatsPoint{Time: p.Time, StatsRow: statsRow(p.StatisticsRow, query.Confidence)}
+	}
+
+	return result, nil
+}
+
+// CompactStatistics сворачивает часовую статистику старше StatsRollupAfter в дневную
+// и удаляет дневную старше StatsRetention. Возвращает число перенесенных и удаленных строк.
+func (a *App) CompactStatistics(ctx context.Context) (int64, int64, error) {
+	ctx, span := tracing.Start(ctx, "App.CompactStatistics")
+	defer span.End()
+
+	opCtx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	now := time.Now()
+	rolledUp, err := a.Storage.RollupHourlyStatistics(opCtx, now.Add(-a.StatsRollupAfter))
+	if err != nil {
+		return 0, 0, storageError(err)
+	}
+
+	if a.StatsRetention <= 0 {
+		return rolledUp, 0, nil
+	}
+
+	deleted, err := a.Storage.DeleteDailyStatistics(opCtx, now.Add(-a.StatsRetention))
+
+	return rolledUp, deleted, storageError(err)
+}
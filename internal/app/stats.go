@@ -0,0 +1,119 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	bandit "rotator/internal/alghoritms"
+	sqlstorage "rotator/internal/storage/sql"
+	"rotator/internal/tracing"
+	"strings"
+	"time"
+)
+
+// DefaultConfidence уровень доверия интервала CTR, если в запросе он не задан.
+const DefaultConfidence = 0.95
+
+// StatsQuery GroupBy - измерения sqlstorage.Dimension*, фильтр с нулевым ID не применяется.
+type StatsQuery struct {
+	GroupBy       []string
+	SlotID        int64
+	BannerID      int64
+	SocialGroupID int64
+	Confidence    float64
+}
+
+// StatsRow показы, клики и CTR группы с доверительным интервалом Уилсона [CTRLower, CTRUpper].
+type StatsRow struct {
+	SlotID        int64
+	BannerID      int64
+	SocialGroupID int64
+	Display       int64
+	Click         int64
+	CTR           float64
+	CTRLower      float64
+	CTRUpper      float64
+}
+
+// Stats строки статистики и уровень доверия, с которым посчитаны интервалы.
+type Stats struct {
+	GroupBy    []string
+	Confidence float64
+	Rows       []StatsRow
+}
+
+// validate проверяет измерения и уровень доверия, подставляет уровень по умолчанию.
+func (q *StatsQuery) validate() error {
+	for _, d := range q.GroupBy {
+		switch d {
+		case sqlstorage.DimensionSlot, sqlstorage.DimensionBanner, sqlstorage.DimensionSocialGroup:
+		default:
+			return ErrInvalidStatsQuery.Wrap(fmt.Errorf("unknown group_by %q", d))
+		}
+	}
+
+	if q.Confidence == 0 {
+		q.Confidence = DefaultConfidence
+	}
+	if q.Confidence <= 0 || q.Confidence >= 1 {
+		return ErrInvalidStatsQuery.Wrap(errors.New("confidence should be between 0 and 1"))
+	}
+
+	return nil
+}
+
+// statsRow считает CTR и интервал для сумм показов и кликов.
+func statsRow(r sqlstorage.StatisticsRow, confidence float64) StatsRow {
+	row := StatsRow{
+		SlotID:        r.SlotID,
+		BannerID:      r.BannerID,
+		SocialGroupID: r.SocialGroupID,
+		Display:       r.Display,
+		Click:         r.Click,
+	}
+	if r.Display > 0 {
+		row.CTR = float64(r.Click) / float64(r.Display)
+	}
+	row.CTRLower, row.CTRUpper = bandit.WilsonInterval(r.Click, r.Display, confidence)
+
+	return row
+}
+
+// GetStats показы, клики и CTR арендатора, сгруппированные по слоту, баннеру и/или соц.группе.
+func (a *App) GetStats(ctx context.Context, query StatsQuery) (Stats, error) {
+	ctx, span := tracing.Start(ctx, "App.GetStats", trace.WithAttributes(
+		attribute.String("group_by", strings.Join(query.GroupBy, ","))))
+	defer span.End()
+
+	if err := query.validate(); err != nil {
+		return Stats{}, err
+	}
+
+	opCtx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	rows, err := a.Storage.GetStatistics(opCtx, sqlstorage.StatisticsFilter{
+		SlotID:        query.SlotID,
+		BannerID:      query.BannerID,
+		SocialGroupID: query.SocialGroupID,
+	}, query.GroupBy)
+	if err != nil {
+		return Stats{}, storageError(err)
+	}
+
+	result := Stats{
+		GroupBy:    query.GroupBy,
+		Confidence: query.Confidence,
+		Rows:       make([]StatsRow, len(rows)),
+	}
+	if result.GroupBy == nil {
+		result.GroupBy = []string{}
+	}
+	for i, r := range rows {
+		result.Rows[i] = statsRow(r, query.Confidence)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	sqlstorage "rotator/internal/storage/sql"
+	"rotator/internal/tracing"
+	"time"
+)
+
+// GetBannerStatus состояние баннера в слоте: sqlstorage.BannerActive, BannerPaused или BannerArchived.
+func (a *App) GetBannerStatus(ctx context.Context, bannerID, slotID int64) (string, error) {
+	ctx, span := tracing.Start(ctx, "App.GetBannerStatus", trace.WithAttributes(
+		attribute.Int64("banner.id", bannerID), attribute.Int64("slot.id", slotID)))
+	defer span.End()
+
+	opCtx, cancel := context.WithTimeout(ctx, time.Second*3)
+	defer cancel()
+
+	status, err := a.Storage.GetBannerStatus(opCtx, bannerID, slotID)
+	if err != nil {
+		return "", storageError(err)
+	}
+	if status == "" {
+		return "", ErrBannerNotInSlot
+	}
+
+	return status, nil
+}
+
+// SetBannerStatus приостанавливает, возобновляет или архивирует баннер в слоте. В отличие от
+// RemoveBannerToSlot статистика сохраняется. Архивный баннер вернуть в ротацию нельзя.
+func (a *App) SetBannerStatus(ctx context.Context, bannerID, slotID int64, status string) error {
+	ctx, span := tracing.Start(ctx, "App.SetBannerStatus", trace.WithAttributes(
+		attribute.Int64("banner.id", bannerID), attribute.Int64("slot.id", slotID),
+		attribute.String("status", status)))
+	defer span.End()
+
+	switch status {
+	case sqlstorage.BannerActive, sqlstorage.BannerPaused, sqlstorage.BannerArchived:
+	default:
+		return ErrInvalidBannerStatus.Wrap(fmt.Errorf("unknown status %q", status))
+	}
+
+	opCtx, cancel := context.WithTimeout(ctx, time.Second*3)
+	defer cancel()
+
+	previous, err := a.Storage.SetBannerStatus(opCtx, bannerID, slotID, status)
+	if err != nil {
+		return storageError(err)
+	}
+
+	switch {
+	case previous == "":
+		return ErrBannerNotInSlot
+	case previous == sqlstorage.BannerArchived && status != sqlstorage.BannerArchived:
+		return ErrInvalidBannerStatus.Wrap(errors.New("archived banner can't be returned to rotation"))
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	sqlstorage "rotator/internal/storage/sql"
+	"rotator/internal/tracing"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrInvalidTenant = &Error{
+		Kind: KindValidation, Code: "invalid_tenant", Message: "tenant id should be a positive number",
+	}
+	ErrTenantMismatch = &Error{
+		Kind: KindPermissionDenied, Code: "tenant_mismatch", Message: "api key belongs to another tenant",
+	}
+)
+
+// WithTenant задает арендатора, в рамках которого работают все операции с контекстом.
+func WithTenant(ctx context.Context, tenantID int64) context.Context {
+	return sqlstorage.WithTenant(ctx, tenantID)
+}
+
+// TenantFromContext арендатор запроса, арендатор по умолчанию если не задан.
+func TenantFromContext(ctx context.Context) int64 {
+	return sqlstorage.TenantFromContext(ctx)
+}
+
+// ResolveTenant определяет арендатора запроса. Если запрос аутентифицирован, арендатор
+// берется из ключа, а указанный явно должен с ним совпадать. Без ключа арендатор
+// берется из запроса, а если не указан - арендатор по умолчанию.
+func ResolveTenant(key *sqlstorage.APIKey, requested string) (int64, error) {
+	var tenantID int64
+	if requested != "" {
+		parsed, err := strconv.ParseInt(requested, 10, 64)
+		if err != nil || parsed <= 0 {
+			return 0, ErrInvalidTenant.Wrap(fmt.Errorf("tenant %q", requested))
+		}
+		tenantID = parsed
+	}
+
+	if key == nil {
+		if tenantID == 0 {
+			return sqlstorage.DefaultTenant, nil
+		}
+
+		return tenantID, nil
+	}
+
+	if tenantID != 0 && tenantID != key.TenantID {
+		return 0, ErrTenantMismatch
+	}
+
+	return key.TenantID, nil
+}
+
+func (a *App) CreateTenant(ctx context.Context, description string) (int64, error) {
+	ctx, span := tracing.Start(ctx, "App.CreateTenant")
+	defer span.End()
+
+	opCtx, cancel := context.WithTimeout(ctx, time.Second*3)
+	defer cancel()
+
+	id, err := a.Storage.CreateTenant(opCtx, description)
+
+	return id, storageError(err)
+}
+
+func (a *App) ListTenants(ctx context.Context) ([]sqlstorage.Tenant, error) {
+	ctx, span := tracing.Start(ctx, "App.ListTenants")
+	defer span.End()
+
+	opCtx, cancel := context.WithTimeout(ctx, time.Second*3)
+	defer cancel()
+
+	tenants, err := a.Storage.ListTenants(opCtx)
+
+	return tenants, storageError(err)
+}
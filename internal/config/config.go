@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 )
 
 type (
@@ -11,11 +12,38 @@ type (
 	Storage string
 )
 
+// Duration time.Duration, который читается из конфига строкой вида "1s", "5m".
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("duration should be a string: %w", err)
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("invalid duration %s: %w", value, err)
+	}
+
+	*d = Duration(parsed)
+
+	return nil
+}
+
 type Config struct {
-	Logger  LoggerConf
-	Storage StorageConf
-	HTTP    HttpConf
-	Rabbit  RabbitConf
+	Logger     LoggerConf
+	Storage    StorageConf
+	HTTP       HttpConf
+	GRPC       GrpcConf
+	Rabbit     RabbitConf
+	Stats      StatsConf
+	Aggregator AggregatorConf
+	Auth       AuthConf
+	Tracing    TracingConf
+	Decisions  DecisionLogConf `json:"decisionLog"`
+	Prior      PriorConf
+	Schedule   ScheduleConf
 }
 
 type StorageConf struct {
@@ -26,12 +54,78 @@ type StorageConf struct {
 type HttpConf struct {
 	Host string `json:"host"`
 	Port string `json:"port"`
+	// IdempotencyTTL сколько хранятся ответы на запросы с Idempotency-Key
+	IdempotencyTTL Duration      `json:"idempotencyTTL"`
+	RateLimit      RateLimitConf `json:"rateLimit"`
+}
+
+// RateLimitConf ограничения частоты запросов выбора баннера и переходов в HTTP и gRPC.
+// Бюджеты считаются отдельно для каждого ключа API (без аутентификации - арендатора)
+// и каждого IP клиента и общие для обоих протоколов. TrustedProxies - адреса и подсети
+// прокси, от которых принимаются X-Forwarded-For и X-Real-IP. MaxClients - сколько клиентов
+// помнит один бюджет, по умолчанию 100000.
+type RateLimitConf struct {
+	Enabled        bool           `json:"enabled"`
+	Choose         RateLimitRoute `json:"choose"`
+	Transition     RateLimitRoute `json:"transition"`
+	TrustedProxies []string       `json:"trustedProxies"`
+	MaxClients     int            `json:"maxClients"`
+}
+
+// RateLimitRoute rps - сколько запросов в секунду восполняется, burst - размер корзины.
+// Нулевой rps снимает ограничение.
+type RateLimitRoute struct {
+	PerKey RateLimit `json:"perKey"`
+	PerIP  RateLimit `json:"perIP"`
+}
+
+type RateLimit struct {
+	RPS   float64 `json:"rps"`
+	Burst int     `json:"burst"`
+}
+
+type GrpcConf struct {
+	Host string `json:"host"`
+	Port string `json:"port"`
+	// StreamConcurrency сколько запросов одного потока ChooseBannerStream обрабатывается параллельно
+	StreamConcurrency int `json:"streamConcurrency"`
 }
 
 type RabbitConf struct {
-	Url      string `json:"url"`
-	Queue    string `json:"queue"`
-	Exchange string `json:"exchange"`
+	Url               string   `json:"url"`
+	Queue             string   `json:"queue"`
+	Exchange          string   `json:"exchange"`
+	Encoding          string   `json:"encoding"`
+	BufferSize        int      `json:"bufferSize"`
+	ReconnectDelay    Duration `json:"reconnectDelay"`
+	MaxReconnectDelay Duration `json:"maxReconnectDelay"`
+}
+
+// StatsConf mode "direct" - счетчики обновляются в запросе,
+// "events" - только публикуются события, счетчики обновляет rotator-aggregator.
+// rollupAfterDays - через сколько дней часовая статистика сворачивается в дневную (по умолчанию 7),
+// retentionDays - сколько дней хранится дневная статистика, 0 - всегда.
+type StatsConf struct {
+	Mode            string `json:"mode"`
+	RollupAfterDays int    `json:"rollupAfterDays"`
+	RetentionDays   int    `json:"retentionDays"`
+}
+
+// PriorConf псевдосчетчики по умолчанию для слотов без своих: alpha кликов и beta показов без клика.
+// Нули - без априорных данных, баннер без показов тогда показывается первым.
+type PriorConf struct {
+	Alpha float64 `json:"alpha"`
+	Beta  float64 `json:"beta"`
+}
+
+// ScheduleConf Timezone - часовой пояс дней недели и часов в расписании баннеров, пусто - UTC.
+type ScheduleConf struct {
+	Timezone string `json:"timezone"`
+}
+
+type AggregatorConf struct {
+	BatchSize     int      `json:"batchSize"`
+	FlushInterval Duration `json:"flushInterval"`
 }
 
 type LoggerConf struct {
@@ -68,3 +162,29 @@ func LoadConfig(path string) (*Config, error) {
 
 	return &config, nil
 }
+
+// AuthConf enabled - требовать ключ API, ключи создаются через rotatorctl keys create.
+type AuthConf struct {
+	Enabled bool `json:"enabled"`
+}
+
+// TracingConf exporter - куда отправлять спаны: "none" (по умолчанию), "stdout" или "otlp"
+// (OTLP gRPC на endpoint). sampleRatio - доля трассируемых запросов без родителя, 0 - все.
+type TracingConf struct {
+	Exporter    string  `json:"exporter"`
+	Endpoint    string  `json:"endpoint"`
+	Insecure    bool    `json:"insecure"`
+	SampleRatio float64 `json:"sampleRatio"`
+}
+
+// DecisionLogConf журнал решений ChooseBanner для оффлайн оценки стратегий. sink - куда писать:
+// "" (журнал выключен), "table" (таблица decision_log), "file" (JSON Lines в path) или "events"
+// (издатель событий). sampleRate - доля записываемых решений от 0 до 1.
+type DecisionLogConf struct {
+	Sink          string   `json:"sink"`
+	Path          string   `json:"path"`
+	SampleRate    float64  `json:"sampleRate"`
+	BufferSize    int      `json:"bufferSize"`
+	BatchSize     int      `json:"batchSize"`
+	FlushInterval Duration `json:"flushInterval"`
+}
@@ -0,0 +1,124 @@
+// Package decisionlog журнал решений стратегии: из каких баннеров выбирали, с какими оценками
+// и вероятностями показа. По нему стратегии оцениваются оффлайн.
+package decisionlog
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"rotator/internal/events"
+	"rotator/internal/metrics"
+	"time"
+)
+
+// Куда пишется журнал решений.
+const (
+	SinkTable  = "table"
+	SinkFile   = "file"
+	SinkEvents = "events"
+)
+
+var ErrBufferFull = errors.New("decision log buffer is full")
+
+type Logger interface {
+	Debug(message string, fields ...zap.Field)
+	Error(message string, fields ...zap.Field)
+}
+
+// Sink получатель пачек решений.
+type Sink interface {
+	Write(ctx context.Context, batch []events.Event) error
+}
+
+// Buffered копит решения в памяти и пишет их в Sink пачками из Run, чтобы журнал
+// не задерживал выбор баннера. Решения, которые не поместились в буфер, теряются.
+type Buffered struct {
+	sink          Sink
+	logger        Logger
+	batchSize     int
+	flushInterval time.Duration
+	queue         chan events.Event
+}
+
+func NewBuffered(sink Sink, bufferSize, batchSize int, flushInterval time.Duration, logger Logger) *Buffered {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	if flushInterval <= 0 {
+		flushInterval = time.Second
+	}
+
+	return &Buffered{
+		sink:          sink,
+		logger:        logger,
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		queue:         make(chan events.Event, max(bufferSize, batchSize)),
+	}
+}
+
+// Record ставит решение в очередь на запись, не дожидаясь ее.
+func (b *Buffered) Record(_ context.Context, decision events.Event) error {
+	select {
+	case b.queue <- decision:
+		return nil
+	default:
+		metrics.DecisionLogDropped.Inc()
+		return ErrBufferFull
+	}
+}
+
+// Run пишет решения, пока не отменен ctx, и после отмены сбрасывает то, что осталось в буфере.
+func (b *Buffered) Run(ctx context.Context) {
+	ticker := time.NewTicker(b.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]events.Event, 0, b.batchSize)
+	for {
+		select {
+		case <-ctx.Done():
+			for {
+				select {
+				case decision := <-b.queue:
+					batch = b.add(batch, decision)
+				default:
+					b.flush(batch)
+					return
+				}
+			}
+		case decision := <-b.queue:
+			batch = b.add(batch, decision)
+		case <-ticker.C:
+			batch = b.flush(batch)
+		}
+	}
+}
+
+func (b *Buffered) add(batch []events.Event, decision events.Event) []events.Event {
+	batch = append(batch, decision)
+	if len(batch) >= b.batchSize {
+		return b.flush(batch)
+	}
+
+	return batch
+}
+
+// flush ошибка записи журнала не должна останавливать сервис, пачка теряется и попадает в лог.
+func (b *Buffered) flush(batch []events.Event) []events.Event {
+	if len(batch) == 0 {
+		return batch
+	}
+
+	// после отмены контекста сервиса остаток все равно нужно дописать
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	if err := b.sink.Write(ctx, batch); err != nil {
+		metrics.DecisionLogDropped.Add(float64(len(batch)))
+		b.logger.Error("failed to write decision log", zap.Int("decisions", len(batch)), zap.Error(err))
+	} else {
+		b.logger.Debug("decision log written", zap.Int("decisions", len(batch)))
+	}
+
+	return batch[:0]
+}
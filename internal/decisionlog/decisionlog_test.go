@@ -0,0 +1,146 @@
+package decisionlog
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"rotator/internal/events"
+	"sync"
+	"testing"
+	"time"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...zap.Field) {}
+func (nopLogger) Error(string, ...zap.Field) {}
+
+type memorySink struct {
+	mu      sync.Mutex
+	batches [][]events.Event
+	err     error
+}
+
+func (s *memorySink) Write(_ context.Context, batch []events.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.batches = append(s.batches, append([]events.Event{}, batch...))
+
+	return s.err
+}
+
+func (s *memorySink) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for _, b := range s.batches {
+		n += len(b)
+	}
+
+	return n
+}
+
+func decision(bannerID int64) events.Event {
+	return events.Event{
+		Type:          events.TypeDecision,
+		SlotID:        1,
+		BannerID:      bannerID,
+		SocialGroupID: 1,
+		Timestamp:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Strategy:      "ucb1",
+		Candidates: []events.Candidate{
+			{BannerID: bannerID, Display: 3, Click: 1, Score: 1.2, Propensity: 1},
+			{BannerID: bannerID + 1, Unexplored: true},
+		},
+		StrategyParams: map[string]float64{"c": 2},
+		TotalDisplay:   3,
+	}
+}
+
+func TestBuffered(t *testing.T) {
+	t.Run("flush by batch size and on stop", func(t *testing.T) {
+		sink := &memorySink{}
+		log := NewBuffered(sink, 10, 2, time.Hour, nopLogger{})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			log.Run(ctx)
+			close(done)
+		}()
+
+		for i := int64(1); i <= 3; i++ {
+			require.NoError(t, log.Record(ctx, decision(i)))
+		}
+		require.Eventually(t, func() bool { return sink.count() == 2 }, time.Second, time.Millisecond*5)
+
+		cancel()
+		<-done
+		require.Equal(t, 3, sink.count())
+		require.Len(t, sink.batches, 2)
+	})
+
+	t.Run("flush by interval", func(t *testing.T) {
+		sink := &memorySink{}
+		log := NewBuffered(sink, 10, 100, time.Millisecond*10, nopLogger{})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go log.Run(ctx)
+
+		require.NoError(t, log.Record(ctx, decision(1)))
+		require.Eventually(t, func() bool { return sink.count() == 1 }, time.Second, time.Millisecond*5)
+	})
+
+	t.Run("full buffer drops decisions", func(t *testing.T) {
+		log := NewBuffered(&memorySink{}, 1, 1, time.Hour, nopLogger{})
+
+		require.NoError(t, log.Record(context.Background(), decision(1)))
+		require.ErrorIs(t, log.Record(context.Background(), decision(2)), ErrBufferFull)
+	})
+
+	t.Run("sink error does not stop log", func(t *testing.T) {
+		sink := &memorySink{err: errors.New("disk is full")}
+		log := NewBuffered(sink, 10, 1, time.Hour, nopLogger{})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go log.Run(ctx)
+
+		require.NoError(t, log.Record(ctx, decision(1)))
+		require.NoError(t, log.Record(ctx, decision(2)))
+		require.Eventually(t, func() bool { return sink.count() == 2 }, time.Second, time.Millisecond*5)
+	})
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "decisions.jsonl")
+
+	for i := int64(1); i <= 2; i++ {
+		file, err := NewFile(path)
+		require.NoError(t, err)
+		require.NoError(t, file.Write(context.Background(), []events.Event{decision(i)}))
+		require.NoError(t, file.Close())
+	}
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	lines := make([]events.Event, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e events.Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		lines = append(lines, e)
+	}
+	require.NoError(t, scanner.Err())
+	require.Equal(t, []events.Event{decision(1), decision(2)}, lines)
+}
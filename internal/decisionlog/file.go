@@ -0,0 +1,40 @@
+package decisionlog
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"rotator/internal/events"
+)
+
+// File пишет решения в файл построчно в JSON (JSON Lines), дописывая в конец.
+type File struct {
+	file *os.File
+}
+
+func NewFile(path string) (*File, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("can't open decision log %s: %w", path, err)
+	}
+
+	return &File{file: file}, nil
+}
+
+func (f *File) Write(_ context.Context, batch []events.Event) error {
+	w := bufio.NewWriter(f.file)
+	encoder := json.NewEncoder(w)
+	for _, decision := range batch {
+		if err := encoder.Encode(decision); err != nil {
+			return fmt.Errorf("can't encode decision: %w", err)
+		}
+	}
+
+	return w.Flush()
+}
+
+func (f *File) Close() error {
+	return f.file.Close()
+}
@@ -0,0 +1,32 @@
+package decisionlog
+
+import (
+	"context"
+	"errors"
+	"rotator/internal/events"
+)
+
+type Publisher interface {
+	Publish(ctx context.Context, event events.Event) error
+}
+
+// Events отправляет решения издателю событий вместе с кликами и показами.
+// rotator-aggregator решения пропускает, их забирают другие получатели.
+type Events struct {
+	publisher Publisher
+}
+
+func NewEvents(publisher Publisher) *Events {
+	return &Events{publisher: publisher}
+}
+
+func (e *Events) Write(ctx context.Context, batch []events.Event) error {
+	var errs []error
+	for _, decision := range batch {
+		if err := e.publisher.Publish(ctx, decision); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
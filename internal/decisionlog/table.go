@@ -0,0 +1,57 @@
+package decisionlog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"rotator/internal/events"
+	sqlstorage "rotator/internal/storage/sql"
+)
+
+type Storage interface {
+	SaveDecisions(ctx context.Context, decisions []sqlstorage.Decision) error
+}
+
+// Table пишет решения в таблицу decision_log.
+type Table struct {
+	storage Storage
+}
+
+func NewTable(storage Storage) *Table {
+	return &Table{storage: storage}
+}
+
+func (t *Table) Write(ctx context.Context, batch []events.Event) error {
+	decisions := make([]sqlstorage.Decision, 0, len(batch))
+	for _, e := range batch {
+		params, err := json.Marshal(e.StrategyParams)
+		if err != nil {
+			return fmt.Errorf("can't encode strategy params: %w", err)
+		}
+
+		candidates, err := json.Marshal(e.Candidates)
+		if err != nil {
+			return fmt.Errorf("can't encode candidates: %w", err)
+		}
+
+		tenantID := e.TenantID
+		if tenantID == 0 {
+			tenantID = sqlstorage.DefaultTenant
+		}
+
+		decisions = append(decisions, sqlstorage.Decision{
+			TenantID:       tenantID,
+			DecidedAt:      e.Timestamp,
+			ImpressionID:   e.ImpressionID,
+			SlotID:         e.SlotID,
+			SocialGroupID:  e.SocialGroupID,
+			BannerID:       e.BannerID,
+			Strategy:       e.Strategy,
+			StrategyParams: params,
+			TotalDisplay:   e.TotalDisplay,
+			Candidates:     candidates,
+		})
+	}
+
+	return t.storage.SaveDecisions(ctx, decisions)
+}
@@ -0,0 +1,399 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: event.proto
+
+package eventpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Event_Type int32
+
+const (
+	Event_TYPE_UNSPECIFIED Event_Type = 0
+	Event_TYPE_CLICK       Event_Type = 1
+	Event_TYPE_DISPLAY     Event_Type = 2
+	// запись журнала решений, статистику не меняет
+	Event_TYPE_DECISION Event_Type = 3
+)
+
+// Enum value maps for Event_Type.
+var (
+	Event_Type_name = map[int32]string{
+		0: "TYPE_UNSPECIFIED",
+		1: "TYPE_CLICK",
+		2: "TYPE_DISPLAY",
+		3: "TYPE_DECISION",
+	}
+	Event_Type_value = map[string]int32{
+		"TYPE_UNSPECIFIED": 0,
+		"TYPE_CLICK":       1,
+		"TYPE_DISPLAY":     2,
+		"TYPE_DECISION":    3,
+	}
+)
+
+func (x Event_Type) Enum() *Event_Type {
+	p := new(Event_Type)
+	*p = x
+	return p
+}
+
+func (x Event_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Event_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_event_proto_enumTypes[0].Descriptor()
+}
+
+func (Event_Type) Type() protoreflect.EnumType {
+	return &file_event_proto_enumTypes[0]
+}
+
+func (x Event_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Event_Type.Descriptor instead.
+func (Event_Type) EnumDescriptor() ([]byte, []int) {
+	return file_event_proto_rawDescGZIP(), []int{0, 0}
+}
+
+// Событие клика, показа баннера или решения стратегии, которое уходит в аналитику.
+// Номера полей не переиспользуются: удаленные поля помечаются reserved.
+type Event struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          Event_Type             `protobuf:"varint,1,opt,name=type,proto3,enum=rotator.events.v1.Event_Type" json:"type,omitempty"`
+	SlotId        int64                  `protobuf:"varint,2,opt,name=slot_id,json=slotId,proto3" json:"slot_id,omitempty"`
+	BannerId      int64                  `protobuf:"varint,3,opt,name=banner_id,json=bannerId,proto3" json:"banner_id,omitempty"`
+	SocialGroupId int64                  `protobuf:"varint,4,opt,name=social_group_id,json=socialGroupId,proto3" json:"social_group_id,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	ImpressionId  string                 `protobuf:"bytes,6,opt,name=impression_id,json=impressionId,proto3" json:"impression_id,omitempty"`
+	Strategy      string                 `protobuf:"bytes,7,opt,name=strategy,proto3" json:"strategy,omitempty"`
+	Score         float64                `protobuf:"fixed64,8,opt,name=score,proto3" json:"score,omitempty"`
+	// арендатор, 0 - арендатор по умолчанию
+	TenantId int64 `protobuf:"varint,9,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	// поля решения стратегии, заполнены только у TYPE_DECISION
+	Candidates     []*Candidate       `protobuf:"bytes,10,rep,name=candidates,proto3" json:"candidates,omitempty"`
+	StrategyParams map[string]float64 `protobuf:"bytes,11,rep,name=strategy_params,json=strategyParams,proto3" json:"strategy_params,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"`
+	TotalDisplay   int64              `protobuf:"varint,12,opt,name=total_display,json=totalDisplay,proto3" json:"total_display,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_event_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_event_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_event_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Event) GetType() Event_Type {
+	if x != nil {
+		return x.Type
+	}
+	return Event_TYPE_UNSPECIFIED
+}
+
+func (x *Event) GetSlotId() int64 {
+	if x != nil {
+		return x.SlotId
+	}
+	return 0
+}
+
+func (x *Event) GetBannerId() int64 {
+	if x != nil {
+		return x.BannerId
+	}
+	return 0
+}
+
+func (x *Event) GetSocialGroupId() int64 {
+	if x != nil {
+		return x.SocialGroupId
+	}
+	return 0
+}
+
+func (x *Event) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *Event) GetImpressionId() string {
+	if x != nil {
+		return x.ImpressionId
+	}
+	return ""
+}
+
+func (x *Event) GetStrategy() string {
+	if x != nil {
+		return x.Strategy
+	}
+	return ""
+}
+
+func (x *Event) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *Event) GetTenantId() int64 {
+	if x != nil {
+		return x.TenantId
+	}
+	return 0
+}
+
+func (x *Event) GetCandidates() []*Candidate {
+	if x != nil {
+		return x.Candidates
+	}
+	return nil
+}
+
+func (x *Event) GetStrategyParams() map[string]float64 {
+	if x != nil {
+		return x.StrategyParams
+	}
+	return nil
+}
+
+func (x *Event) GetTotalDisplay() int64 {
+	if x != nil {
+		return x.TotalDisplay
+	}
+	return 0
+}
+
+// Candidate баннер, из которых стратегия выбирала, с его оценкой.
+type Candidate struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	BannerId int64                  `protobuf:"varint,1,opt,name=banner_id,json=bannerId,proto3" json:"banner_id,omitempty"`
+	Display  int64                  `protobuf:"varint,2,opt,name=display,proto3" json:"display,omitempty"`
+	Click    int64                  `protobuf:"varint,3,opt,name=click,proto3" json:"click,omitempty"`
+	Score    float64                `protobuf:"fixed64,4,opt,name=score,proto3" json:"score,omitempty"`
+	// баннер без показов, оценка бесконечна
+	Unexplored bool `protobuf:"varint,5,opt,name=unexplored,proto3" json:"unexplored,omitempty"`
+	// вероятность, с которой стратегия выбрала бы баннер
+	Propensity    float64 `protobuf:"fixed64,6,opt,name=propensity,proto3" json:"propensity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Candidate) Reset() {
+	*x = Candidate{}
+	mi := &file_event_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Candidate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Candidate) ProtoMessage() {}
+
+func (x *Candidate) ProtoReflect() protoreflect.Message {
+	mi := &file_event_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Candidate.ProtoReflect.Descriptor instead.
+func (*Candidate) Descriptor() ([]byte, []int) {
+	return file_event_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Candidate) GetBannerId() int64 {
+	if x != nil {
+		return x.BannerId
+	}
+	return 0
+}
+
+func (x *Candidate) GetDisplay() int64 {
+	if x != nil {
+		return x.Display
+	}
+	return 0
+}
+
+func (x *Candidate) GetClick() int64 {
+	if x != nil {
+		return x.Click
+	}
+	return 0
+}
+
+func (x *Candidate) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *Candidate) GetUnexplored() bool {
+	if x != nil {
+		return x.Unexplored
+	}
+	return false
+}
+
+func (x *Candidate) GetPropensity() float64 {
+	if x != nil {
+		return x.Propensity
+	}
+	return 0
+}
+
+var File_event_proto protoreflect.FileDescriptor
+
+const file_event_proto_rawDesc = "" +
+	"\n" +
+	"\vevent.proto\x12\x11rotator.events.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x96\x05\n" +
+	"\x05Event\x121\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x1d.rotator.events.v1.Event.TypeR\x04type\x12\x17\n" +
+	"\aslot_id\x18\x02 \x01(\x03R\x06slotId\x12\x1b\n" +
+	"\tbanner_id\x18\x03 \x01(\x03R\bbannerId\x12&\n" +
+	"\x0fsocial_group_id\x18\x04 \x01(\x03R\rsocialGroupId\x128\n" +
+	"\ttimestamp\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12#\n" +
+	"\rimpression_id\x18\x06 \x01(\tR\fimpressionId\x12\x1a\n" +
+	"\bstrategy\x18\a \x01(\tR\bstrategy\x12\x14\n" +
+	"\x05score\x18\b \x01(\x01R\x05score\x12\x1b\n" +
+	"\ttenant_id\x18\t \x01(\x03R\btenantId\x12<\n" +
+	"\n" +
+	"candidates\x18\n" +
+	" \x03(\v2\x1c.rotator.events.v1.CandidateR\n" +
+	"candidates\x12U\n" +
+	"\x0fstrategy_params\x18\v \x03(\v2,.rotator.events.v1.Event.StrategyParamsEntryR\x0estrategyParams\x12#\n" +
+	"\rtotal_display\x18\f \x01(\x03R\ftotalDisplay\x1aA\n" +
+	"\x13StrategyParamsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\"Q\n" +
+	"\x04Type\x12\x14\n" +
+	"\x10TYPE_UNSPECIFIED\x10\x00\x12\x0e\n" +
+	"\n" +
+	"TYPE_CLICK\x10\x01\x12\x10\n" +
+	"\fTYPE_DISPLAY\x10\x02\x12\x11\n" +
+	"\rTYPE_DECISION\x10\x03\"\xae\x01\n" +
+	"\tCandidate\x12\x1b\n" +
+	"\tbanner_id\x18\x01 \x01(\x03R\bbannerId\x12\x18\n" +
+	"\adisplay\x18\x02 \x01(\x03R\adisplay\x12\x14\n" +
+	"\x05click\x18\x03 \x01(\x03R\x05click\x12\x14\n" +
+	"\x05score\x18\x04 \x01(\x01R\x05score\x12\x1e\n" +
+	"\n" +
+	"unexplored\x18\x05 \x01(\bR\n" +
+	"unexplored\x12\x1e\n" +
+	"\n" +
+	"propensity\x18\x06 \x01(\x01R\n" +
+	"propensityB!Z\x1frotator/internal/events/eventpbb\x06proto3"
+
+var (
+	file_event_proto_rawDescOnce sync.Once
+	file_event_proto_rawDescData []byte
+)
+
+func file_event_proto_rawDescGZIP() []byte {
+	file_event_proto_rawDescOnce.Do(func() {
+		file_event_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_event_proto_rawDesc), len(file_event_proto_rawDesc)))
+	})
+	return file_event_proto_rawDescData
+}
+
+var file_event_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_event_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_event_proto_goTypes = []any{
+	(Event_Type)(0),               // 0: rotator.events.v1.Event.Type
+	(*Event)(nil),                 // 1: rotator.events.v1.Event
+	(*Candidate)(nil),             // 2: rotator.events.v1.Candidate
+	nil,                           // 3: rotator.events.v1.Event.StrategyParamsEntry
+	(*timestamppb.Timestamp)(nil), // 4: google.protobuf.Timestamp
+}
+var file_event_proto_depIdxs = []int32{
+	0, // 0: rotator.events.v1.Event.type:type_name -> rotator.events.v1.Event.Type
+	4, // 1: rotator.events.v1.Event.timestamp:type_name -> google.protobuf.Timestamp
+	2, // 2: rotator.events.v1.Event.candidates:type_name -> rotator.events.v1.Candidate
+	3, // 3: rotator.events.v1.Event.strategy_params:type_name -> rotator.events.v1.Event.StrategyParamsEntry
+	4, // [4:4] is the sub-list for method output_type
+	4, // [4:4] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_event_proto_init() }
+func file_event_proto_init() {
+	if File_event_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_event_proto_rawDesc), len(file_event_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_event_proto_goTypes,
+		DependencyIndexes: file_event_proto_depIdxs,
+		EnumInfos:         file_event_proto_enumTypes,
+		MessageInfos:      file_event_proto_msgTypes,
+	}.Build()
+	File_event_proto = out.File
+	file_event_proto_goTypes = nil
+	file_event_proto_depIdxs = nil
+}
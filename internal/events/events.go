@@ -0,0 +1,279 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"rotator/internal/events/eventpb"
+	"strconv"
+	"time"
+)
+
+// SchemaVersion версия схемы событий. Добавление полей версию не меняет,
+// увеличиваем ее только при несовместимых изменениях.
+const SchemaVersion = 1
+
+// HeaderSchemaVersion заголовок сообщения с версией схемы.
+const HeaderSchemaVersion = "schema_version"
+
+const (
+	EncodingJSON     = "json"
+	EncodingProtobuf = "protobuf"
+
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/x-protobuf"
+)
+
+type Type string
+
+const (
+	TypeClick   Type = "click"
+	TypeDisplay Type = "display"
+	// TypeDecision запись журнала решений, статистику не меняет
+	TypeDecision Type = "decision"
+)
+
+var (
+	ErrUnknownEncoding = errors.New("unknown event encoding")
+	ErrUnknownVersion  = errors.New("unsupported event schema version")
+)
+
+// Event событие клика, показа или решения стратегии. Общий формат для всех получателей событий.
+// Candidates, StrategyParams и TotalDisplay заполнены только у решений.
+type Event struct {
+	Type          Type      `json:"type"`
+	SlotID        int64     `json:"slot_id"`
+	BannerID      int64     `json:"banner_id"`
+	SocialGroupID int64     `json:"social_group_id"`
+	Timestamp     time.Time `json:"timestamp"`
+	ImpressionID  string    `json:"impression_id,omitempty"`
+	Strategy      string    `json:"strategy,omitempty"`
+	Score         float64   `json:"score,omitempty"`
+	TenantID      int64     `json:"tenant_id,omitempty"`
+
+	Candidates     []Candidate        `json:"candidates,omitempty"`
+	StrategyParams map[string]float64 `json:"strategy_params,omitempty"`
+	TotalDisplay   int64              `json:"total_display,omitempty"`
+}
+
+// Candidate баннер, из которых выбирала стратегия. Оценка баннера без показов бесконечна,
+// поэтому у него Score = 0 и Unexplored = true.
+type Candidate struct {
+	BannerID   int64   `json:"banner_id"`
+	Display    int64   `json:"display"`
+	Click      int64   `json:"click"`
+	Score      float64 `json:"score"`
+	Unexplored bool    `json:"unexplored,omitempty"`
+	Propensity float64 `json:"propensity"`
+}
+
+func (e Event) Validate() error {
+	if e.Type != TypeClick && e.Type != TypeDisplay && e.Type != TypeDecision {
+		return fmt.Errorf("unknown event type %q", e.Type)
+	}
+
+	if e.SlotID == 0 || e.BannerID == 0 || e.SocialGroupID == 0 {
+		return fmt.Errorf("event should have slot, banner and social group")
+	}
+
+	if e.Type == TypeDecision && len(e.Candidates) == 0 {
+		return fmt.Errorf("decision should have candidates")
+	}
+
+	return nil
+}
+
+// Codec кодирует события в тело сообщения.
+type Codec interface {
+	ContentType() string
+	Encode(e Event) ([]byte, error)
+	Decode(data []byte) (Event, error)
+}
+
+// NewCodec возвращает кодек по названию кодировки из конфига.
+func NewCodec(encoding string) (Codec, error) {
+	switch encoding {
+	case EncodingJSON, "":
+		return JSONCodec{}, nil
+	case EncodingProtobuf:
+		return ProtobufCodec{}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEncoding, encoding)
+	}
+}
+
+// CodecFor возвращает кодек по content type сообщения.
+func CodecFor(contentType string) (Codec, error) {
+	switch contentType {
+	case ContentTypeJSON, "":
+		return JSONCodec{}, nil
+	case ContentTypeProtobuf:
+		return ProtobufCodec{}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEncoding, contentType)
+	}
+}
+
+// CheckVersion проверяет, что потребитель понимает версию схемы из заголовка.
+// Сообщения без заголовка считаются первой версией.
+func CheckVersion(header interface{}) error {
+	if header == nil {
+		return nil
+	}
+
+	var version int64
+	switch v := header.(type) {
+	case int:
+		version = int64(v)
+	case int32:
+		version = int64(v)
+	case int64:
+		version = v
+	case string:
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrUnknownVersion, v)
+		}
+		version = parsed
+	default:
+		return fmt.Errorf("%w: %v", ErrUnknownVersion, header)
+	}
+
+	if version < 1 || version > SchemaVersion {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+
+	return nil
+}
+
+type JSONCodec struct{}
+
+func (JSONCodec) ContentType() string {
+	return ContentTypeJSON
+}
+
+func (JSONCodec) Encode(e Event) ([]byte, error) {
+	return json.Marshal(e)
+}
+
+func (JSONCodec) Decode(data []byte) (Event, error) {
+	var e Event
+	if err := json.Unmarshal(data, &e); err != nil {
+		return Event{}, fmt.Errorf("can't decode json event: %w", err)
+	}
+
+	return e, nil
+}
+
+type ProtobufCodec struct{}
+
+func (ProtobufCodec) ContentType() string {
+	return ContentTypeProtobuf
+}
+
+func (ProtobufCodec) Encode(e Event) ([]byte, error) {
+	return proto.Marshal(&eventpb.Event{
+		Type:           toProtoType(e.Type),
+		SlotId:         e.SlotID,
+		BannerId:       e.BannerID,
+		SocialGroupId:  e.SocialGroupID,
+		Timestamp:      timestamppb.New(e.Timestamp),
+		ImpressionId:   e.ImpressionID,
+		Strategy:       e.Strategy,
+		Score:          e.Score,
+		TenantId:       e.TenantID,
+		Candidates:     toProtoCandidates(e.Candidates),
+		StrategyParams: e.StrategyParams,
+		TotalDisplay:   e.TotalDisplay,
+	})
+}
+
+func (ProtobufCodec) Decode(data []byte) (Event, error) {
+	var pb eventpb.Event
+	if err := proto.Unmarshal(data, &pb); err != nil {
+		return Event{}, fmt.Errorf("can't decode protobuf event: %w", err)
+	}
+
+	return Event{
+		Type:           fromProtoType(pb.GetType()),
+		SlotID:         pb.GetSlotId(),
+		BannerID:       pb.GetBannerId(),
+		SocialGroupID:  pb.GetSocialGroupId(),
+		Timestamp:      pb.GetTimestamp().AsTime(),
+		ImpressionID:   pb.GetImpressionId(),
+		Strategy:       pb.GetStrategy(),
+		Score:          pb.GetScore(),
+		TenantID:       pb.GetTenantId(),
+		Candidates:     fromProtoCandidates(pb.GetCandidates()),
+		StrategyParams: pb.GetStrategyParams(),
+		TotalDisplay:   pb.GetTotalDisplay(),
+	}, nil
+}
+
+func toProtoCandidates(candidates []Candidate) []*eventpb.Candidate {
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	result := make([]*eventpb.Candidate, len(candidates))
+	for i, c := range candidates {
+		result[i] = &eventpb.Candidate{
+			BannerId:   c.BannerID,
+			Display:    c.Display,
+			Click:      c.Click,
+			Score:      c.Score,
+			Unexplored: c.Unexplored,
+			Propensity: c.Propensity,
+		}
+	}
+
+	return result
+}
+
+func fromProtoCandidates(candidates []*eventpb.Candidate) []Candidate {
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	result := make([]Candidate, len(candidates))
+	for i, c := range candidates {
+		result[i] = Candidate{
+			BannerID:   c.GetBannerId(),
+			Display:    c.GetDisplay(),
+			Click:      c.GetClick(),
+			Score:      c.GetScore(),
+			Unexplored: c.GetUnexplored(),
+			Propensity: c.GetPropensity(),
+		}
+	}
+
+	return result
+}
+
+func toProtoType(t Type) eventpb.Event_Type {
+	switch t {
+	case TypeClick:
+		return eventpb.Event_TYPE_CLICK
+	case TypeDisplay:
+		return eventpb.Event_TYPE_DISPLAY
+	case TypeDecision:
+		return eventpb.Event_TYPE_DECISION
+	default:
+		return eventpb.Event_TYPE_UNSPECIFIED
+	}
+}
+
+func fromProtoType(t eventpb.Event_Type) Type {
+	switch t {
+	case eventpb.Event_TYPE_CLICK:
+		return TypeClick
+	case eventpb.Event_TYPE_DISPLAY:
+		return TypeDisplay
+	case eventpb.Event_TYPE_DECISION:
+		return TypeDecision
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,68 @@
+package events
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestCodec(t *testing.T) {
+	event := Event{
+		Type:          TypeDisplay,
+		SlotID:        1,
+		BannerID:      2,
+		SocialGroupID: 3,
+		Timestamp:     time.Date(2022, 5, 20, 10, 0, 0, 0, time.UTC),
+		ImpressionID:  "4f6b0a52-6c55-4a8d-a3a0-6a8c7b9e0f11",
+		Strategy:      "ucb1",
+		Score:         1.5,
+		TenantID:      3,
+	}
+
+	decision := event
+	decision.Type = TypeDecision
+	decision.Candidates = []Candidate{
+		{BannerID: 2, Display: 10, Click: 3, Score: 1.5, Propensity: 1},
+		{BannerID: 5, Unexplored: true},
+	}
+	decision.StrategyParams = map[string]float64{"c": 2}
+	decision.TotalDisplay = 10
+
+	for _, encoding := range []string{EncodingJSON, EncodingProtobuf} {
+		t.Run(encoding, func(t *testing.T) {
+			codec, err := NewCodec(encoding)
+			require.NoError(t, err)
+
+			decoder, err := CodecFor(codec.ContentType())
+			require.NoError(t, err)
+
+			for _, e := range []Event{event, decision} {
+				data, err := codec.Encode(e)
+				require.NoError(t, err)
+
+				decoded, err := decoder.Decode(data)
+				require.NoError(t, err)
+				require.Equal(t, e, decoded)
+			}
+		})
+	}
+
+	t.Run("unknown encoding", func(t *testing.T) {
+		_, err := NewCodec("xml")
+		require.ErrorIs(t, err, ErrUnknownEncoding)
+	})
+
+	t.Run("json ignores unknown fields", func(t *testing.T) {
+		decoded, err := JSONCodec{}.Decode([]byte(`{"type":"click","slot_id":1,"banner_id":2,"social_group_id":3,"new_field":true}`))
+		require.NoError(t, err)
+		require.NoError(t, decoded.Validate())
+	})
+}
+
+func TestCheckVersion(t *testing.T) {
+	require.NoError(t, CheckVersion(nil))
+	require.NoError(t, CheckVersion(int32(SchemaVersion)))
+	require.NoError(t, CheckVersion("1"))
+	require.ErrorIs(t, CheckVersion(int32(SchemaVersion+1)), ErrUnknownVersion)
+	require.ErrorIs(t, CheckVersion(0), ErrUnknownVersion)
+}
@@ -0,0 +1,48 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"rotator/internal/app"
+	"strconv"
+	"time"
+)
+
+type csvWriter struct {
+	w        *csv.Writer
+	bucketed bool
+}
+
+func newCSVWriter(w io.Writer, bucketed bool) *csvWriter {
+	writer := &csvWriter{w: csv.NewWriter(w), bucketed: bucketed}
+
+	header := []string{"slot_id", "banner_id", "social_group_id", "display", "click", "ctr"}
+	if bucketed {
+		header = append([]string{"time"}, header...)
+	}
+	// ошибка записи сохраняется в csv.Writer и вернется из Write или Close
+	writer.w.Write(header) //nolint:errcheck
+
+	return writer
+}
+
+func (c *csvWriter) Write(p app.StatsPoint) error {
+	record := []string{
+		strconv.FormatInt(p.SlotID, 10),
+		strconv.FormatInt(p.BannerID, 10),
+		strconv.FormatInt(p.SocialGroupID, 10),
+		strconv.FormatInt(p.Display, 10),
+		strconv.FormatInt(p.Click, 10),
+		strconv.FormatFloat(p.CTR, 'f', -1, 64),
+	}
+	if c.bucketed {
+		record = append([]string{p.Time.UTC().Format(time.RFC3339)}, record...)
+	}
+
+	return c.w.Write(record)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
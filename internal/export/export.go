@@ -0,0 +1,47 @@
+// Package export выгрузка статистики в CSV и Parquet для импорта в хранилище данных и загрузка
+// накопленной статистики из CSV.
+package export
+
+import (
+	"fmt"
+	"io"
+	"rotator/internal/app"
+)
+
+const (
+	FormatCSV     = "csv"
+	FormatParquet = "parquet"
+)
+
+var contentTypes = map[string]string{
+	FormatCSV:     "text/csv",
+	FormatParquet: "application/vnd.apache.parquet",
+}
+
+// Writer пишет строки статистики. Close дописывает буферы и, у Parquet, метаданные файла,
+// сам io.Writer не закрывает.
+type Writer interface {
+	Write(point app.StatsPoint) error
+	Close() error
+}
+
+// ContentType MIME тип формата, false - формат неизвестен.
+func ContentType(format string) (string, bool) {
+	contentType, ok := contentTypes[format]
+	return contentType, ok
+}
+
+// NewWriter bucketed - у строк есть начало периода, иначе колонки time нет.
+func NewWriter(format string, w io.Writer, bucketed bool) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, bucketed), nil
+	case FormatParquet:
+		if bucketed {
+			return newParquetWriter(w, bucketRow), nil
+		}
+		return newParquetWriter(w, lifetimeRow), nil
+	default:
+		return nil, fmt.Errorf("unknown export format %q", format)
+	}
+}
@@ -0,0 +1,119 @@
+package export
+
+import (
+	"bytes"
+	"errors"
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/require"
+	"io"
+	"rotator/internal/app"
+	"strings"
+	"testing"
+	"time"
+)
+
+var points = []app.StatsPoint{
+	{
+		Time:     time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+		StatsRow: app.StatsRow{SlotID: 1, BannerID: 2, SocialGroupID: 3, Display: 100, Click: 10, CTR: 0.1},
+	},
+	{
+		Time:     time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC),
+		StatsRow: app.StatsRow{SlotID: 1, BannerID: 4, SocialGroupID: 3, Display: 8, Click: 0},
+	},
+}
+
+func write(t *testing.T, format string, bucketed bool) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w, err := NewWriter(format, &buf, bucketed)
+	require.NoError(t, err)
+	for _, p := range points {
+		require.NoError(t, w.Write(p))
+	}
+	require.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
+
+func TestCSV(t *testing.T) {
+	require.Equal(t, "time,slot_id,banner_id,social_group_id,display,click,ctr\n"+
+		"2024-03-01T10:00:00Z,1,2,3,100,10,0.1\n"+
+		"2024-03-01T11:00:00Z,1,4,3,8,0,0\n", string(write(t, FormatCSV, true)))
+
+	t.Run("lifetime", func(t *testing.T) {
+		require.Equal(t, "slot_id,banner_id,social_group_id,display,click,ctr\n"+
+			"1,2,3,100,10,0.1\n"+
+			"1,4,3,8,0,0\n", string(write(t, FormatCSV, false)))
+	})
+}
+
+func TestParquet(t *testing.T) {
+	data := write(t, FormatParquet, true)
+
+	rows, err := parquet.Read[parquetBucketRow](bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	require.Equal(t, []parquetBucketRow{bucketRow(points[0]), bucketRow(points[1])}, rows)
+
+	t.Run("lifetime", func(t *testing.T) {
+		data := write(t, FormatParquet, false)
+
+		file, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)))
+		require.NoError(t, err)
+		require.Equal(t, int64(2), file.NumRows())
+		_, hasTime := file.Schema().Lookup("time")
+		require.False(t, hasTime)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := NewWriter("xlsx", &bytes.Buffer{}, false)
+		require.Error(t, err)
+	})
+}
+
+func TestCSVReader(t *testing.T) {
+	reader, err := NewCSVReader(bytes.NewReader(write(t, FormatCSV, false)))
+	require.NoError(t, err)
+
+	var rows []app.ImportRow
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		rows = append(rows, row)
+	}
+	require.Equal(t, []app.ImportRow{
+		{SlotID: 1, BannerID: 2, SocialGroupID: 3, Display: 100, Click: 10},
+		{SlotID: 1, BannerID: 4, SocialGroupID: 3, Display: 8, Click: 0},
+	}, rows)
+
+	t.Run("columns in any order", func(t *testing.T) {
+		reader, err := NewCSVReader(strings.NewReader("click,display,social_group_id,banner_id,slot_id\n1,5,3,2,1\n"))
+		require.NoError(t, err)
+
+		row, err := reader.Read()
+		require.NoError(t, err)
+		require.Equal(t, app.ImportRow{SlotID: 1, BannerID: 2, SocialGroupID: 3, Display: 5, Click: 1}, row)
+	})
+
+	t.Run("bucketed export", func(t *testing.T) {
+		_, err := NewCSVReader(bytes.NewReader(write(t, FormatCSV, true)))
+		require.Error(t, err)
+	})
+
+	t.Run("missing column", func(t *testing.T) {
+		_, err := NewCSVReader(strings.NewReader("slot_id,banner_id,display,click\n"))
+		require.ErrorContains(t, err, "social_group_id")
+	})
+
+	t.Run("invalid number", func(t *testing.T) {
+		reader, err := NewCSVReader(strings.NewReader("slot_id,banner_id,social_group_id,display,click\n1,2,3,x,0\n"))
+		require.NoError(t, err)
+
+		_, err = reader.Read()
+		require.ErrorContains(t, err, "line 2, display")
+	})
+}
@@ -0,0 +1,91 @@
+package export
+
+import (
+	"github.com/parquet-go/parquet-go"
+	"io"
+	"rotator/internal/app"
+	"time"
+)
+
+const (
+	// parquetBatch сколько строк копится перед записью в файл
+	parquetBatch = 1024
+	// parquetRowGroup строк в группе, группы позволяют читать файл по частям
+	parquetRowGroup = 128 * 1024
+)
+
+type parquetLifetimeRow struct {
+	SlotID        int64   `parquet:"slot_id"`
+	BannerID      int64   `parquet:"banner_id"`
+	SocialGroupID int64   `parquet:"social_group_id"`
+	Display       int64   `parquet:"display"`
+	Click         int64   `parquet:"click"`
+	CTR           float64 `parquet:"ctr"`
+}
+
+type parquetBucketRow struct {
+	Time time.Time `parquet:"time,timestamp(millisecond)"`
+	parquetLifetimeRow
+}
+
+func lifetimeRow(p app.StatsPoint) parquetLifetimeRow {
+	return parquetLifetimeRow{
+		SlotID:        p.SlotID,
+		BannerID:      p.BannerID,
+		SocialGroupID: p.SocialGroupID,
+		Display:       p.Display,
+		Click:         p.Click,
+		CTR:           p.CTR,
+	}
+}
+
+func bucketRow(p app.StatsPoint) parquetBucketRow {
+	return parquetBucketRow{Time: p.Time.UTC(), parquetLifetimeRow: lifetimeRow(p)}
+}
+
+type parquetWriter[T any] struct {
+	w       *parquet.GenericWriter[T]
+	convert func(app.StatsPoint) T
+	batch   []T
+	rows    int
+}
+
+func newParquetWriter[T any](w io.Writer, convert func(app.StatsPoint) T) *parquetWriter[T] {
+	return &parquetWriter[T]{
+		w:       parquet.NewGenericWriter[T](w, parquet.Compression(&parquet.Snappy)),
+		convert: convert,
+		batch:   make([]T, 0, parquetBatch),
+	}
+}
+
+func (p *parquetWriter[T]) Write(point app.StatsPoint) error {
+	p.batch = append(p.batch, p.convert(point))
+	if len(p.batch) < parquetBatch {
+		return nil
+	}
+
+	return p.flush()
+}
+
+func (p *parquetWriter[T]) flush() error {
+	if _, err := p.w.Write(p.batch); err != nil {
+		return err
+	}
+	p.rows += len(p.batch)
+	p.batch = p.batch[:0]
+
+	if p.rows >= parquetRowGroup {
+		p.rows = 0
+		return p.w.Flush()
+	}
+
+	return nil
+}
+
+func (p *parquetWriter[T]) Close() error {
+	if err := p.flush(); err != nil {
+		return err
+	}
+
+	return p.w.Close()
+}
@@ -0,0 +1,76 @@
+package export
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"rotator/internal/app"
+	"strconv"
+)
+
+// importColumns колонки CSV, которые нужны для загрузки. Остальные колонки, например ctr
+// из выгрузки, пропускаются.
+var importColumns = []string{"slot_id", "banner_id", "social_group_id", "display", "click"}
+
+// CSVReader читает накопленную статистику из CSV с заголовком в формате выгрузки.
+type CSVReader struct {
+	r       *csv.Reader
+	columns []int
+}
+
+// NewCSVReader читает заголовок. Выгрузка по периодам (с колонкой time) не подходит:
+// загружаются только накопленные счетчики.
+func NewCSVReader(r io.Reader) (*CSVReader, error) {
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("can't read header: %w", err)
+	}
+
+	positions := make(map[string]int, len(header))
+	for i, name := range header {
+		positions[name] = i
+	}
+	if _, ok := positions["time"]; ok {
+		return nil, errors.New("statistics by period can't be imported, export lifetime counters")
+	}
+
+	columns := make([]int, len(importColumns))
+	for i, name := range importColumns {
+		position, ok := positions[name]
+		if !ok {
+			return nil, fmt.Errorf("column %s is missing", name)
+		}
+		columns[i] = position
+	}
+
+	return &CSVReader{r: reader, columns: columns}, nil
+}
+
+// Read следующая строка, io.EOF - строк больше нет.
+func (c *CSVReader) Read() (app.ImportRow, error) {
+	record, err := c.r.Read()
+	if err != nil {
+		return app.ImportRow{}, err
+	}
+
+	var values [5]int64
+	for i, position := range c.columns {
+		values[i], err = strconv.ParseInt(record[position], 10, 64)
+		if err != nil {
+			line, _ := c.r.FieldPos(position)
+			return app.ImportRow{}, fmt.Errorf("line %d, %s: %w", line, importColumns[i], err)
+		}
+	}
+
+	return app.ImportRow{
+		SlotID:        values[0],
+		BannerID:      values[1],
+		SocialGroupID: values[2],
+		Display:       values[3],
+		Click:         values[4],
+	}, nil
+}
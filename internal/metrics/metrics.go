@@ -0,0 +1,92 @@
+// Package metrics метрики Prometheus, которые отдаются на /metrics.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"strconv"
+	"time"
+)
+
+// Namespace префикс имен всех метрик сервиса.
+const Namespace = "rotator"
+
+var (
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route, method and status code.",
+	}, []string{"route", "method", "code"})
+
+	HTTPDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	GRPCRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "grpc_requests_total",
+		Help:      "gRPC calls by full method, call type and status code.",
+	}, []string{"route", "method", "code"})
+
+	GRPCDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "grpc_request_duration_seconds",
+		Help:      "gRPC call latency by full method and call type.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	Decisions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "decisions_total",
+		Help:      "Banners chosen for display by slot, banner and social group.",
+	}, []string{"slot", "banner", "social_group"})
+
+	Clicks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "clicks_total",
+		Help:      "Counted clicks by slot, banner and social group.",
+	}, []string{"slot", "banner", "social_group"})
+
+	DecisionLogDropped = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "decision_log_dropped_total",
+		Help:      "Decisions lost because the decision log buffer was full or the sink failed.",
+	})
+
+	StorageDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "storage_query_duration_seconds",
+		Help:      "Database query latency by statement type.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"statement"})
+)
+
+// ObserveHTTP учитывает один HTTP запрос.
+func ObserveHTTP(route, method string, code int, duration time.Duration) {
+	HTTPRequests.WithLabelValues(route, method, strconv.Itoa(code)).Inc()
+	HTTPDuration.WithLabelValues(route, method).Observe(duration.Seconds())
+}
+
+// ObserveGRPC учитывает один вызов gRPC. Метки те же, что у HTTP: route - полное имя метода,
+// method - тип вызова (unary или stream), code - код статуса gRPC.
+func ObserveGRPC(route, method, code string, duration time.Duration) {
+	GRPCRequests.WithLabelValues(route, method, code).Inc()
+	GRPCDuration.WithLabelValues(route, method).Observe(duration.Seconds())
+}
+
+// Decision учитывает выбор баннера для показа.
+func Decision(slotID, bannerID, socialGroupID int64) {
+	Decisions.WithLabelValues(id(slotID), id(bannerID), id(socialGroupID)).Inc()
+}
+
+// Click учитывает засчитанный переход.
+func Click(slotID, bannerID, socialGroupID int64) {
+	Clicks.WithLabelValues(id(slotID), id(bannerID), id(socialGroupID)).Inc()
+}
+
+func id(v int64) string {
+	return strconv.FormatInt(v, 10)
+}
@@ -0,0 +1,304 @@
+// Package ratelimit ограничение частоты выбора баннера и переходов, общее для HTTP и gRPC.
+package ratelimit
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"golang.org/x/time/rate"
+	"net"
+	"net/netip"
+	"rotator/internal/app"
+	"rotator/internal/config"
+	"rotator/internal/events"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Маршруты с ограничением частоты, у каждого свои бюджеты.
+const (
+	RouteChoose     = "choose"
+	RouteTransition = "transition"
+)
+
+// bucketIdleTTL через сколько забывается корзина клиента, который не делал запросов.
+const bucketIdleTTL = time.Minute * 10
+
+// defaultMaxClients сколько корзин хранит один бюджет, если не задано.
+const defaultMaxClients = 100000
+
+// buckets корзины токенов одного бюджета, по одной на клиента. При переполнении
+// вытесняется корзина, которая дольше всех не использовалась.
+type buckets struct {
+	limit      rate.Limit
+	burst      int
+	maxClients int
+
+	mu    sync.Mutex
+	items map[string]*list.Element
+	// order корзины от недавно использованной к давно не использованной
+	order *list.List
+}
+
+type bucket struct {
+	client  string
+	limiter *rate.Limiter
+	seen    time.Time
+}
+
+// newBuckets возвращает nil, если бюджет не ограничен.
+func newBuckets(conf config.RateLimit, maxClients int) *buckets {
+	if conf.RPS <= 0 {
+		return nil
+	}
+
+	return &buckets{
+		limit:      rate.Limit(conf.RPS),
+		burst:      max(conf.Burst, 1),
+		maxClients: maxClients,
+		items:      make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+func (b *buckets) reserve(client string, n int, now time.Time) *rate.Reservation {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for e := b.order.Back(); e != nil && now.Sub(e.Value.(*bucket).seen) > bucketIdleTTL; e = b.order.Back() {
+		b.remove(e)
+	}
+
+	e, ok := b.items[client]
+	if ok {
+		b.order.MoveToFront(e)
+	} else {
+		if b.order.Len() >= b.maxClients {
+			b.remove(b.order.Back())
+		}
+		e = b.order.PushFront(&bucket{client: client, limiter: rate.NewLimiter(b.limit, b.burst)})
+		b.items[client] = e
+	}
+
+	item := e.Value.(*bucket)
+	item.seen = now
+
+	return item.limiter.ReserveN(now, n)
+}
+
+func (b *buckets) remove(e *list.Element) {
+	b.order.Remove(e)
+	delete(b.items, e.Value.(*bucket).client)
+}
+
+func (b *buckets) len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.order.Len()
+}
+
+type routeLimits struct {
+	perClient *buckets
+	perIP     *buckets
+}
+
+// Limiter бюджеты по клиенту и IP для каждого маршрута. Клиент - проверенный ключ API,
+// а если аутентификация выключена - арендатор запроса, поэтому новый или выдуманный ключ
+// не дает нового бюджета.
+type Limiter struct {
+	routes  map[string]routeLimits
+	proxies []netip.Prefix
+}
+
+// New возвращает nil, если ограничение выключено. Nil Limiter ничего не ограничивает.
+func New(conf config.RateLimitConf) (*Limiter, error) {
+	if !conf.Enabled {
+		return nil, nil
+	}
+
+	proxies := make([]netip.Prefix, 0, len(conf.TrustedProxies))
+	for _, proxy := range conf.TrustedProxies {
+		prefix, err := parsePrefix(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		proxies = append(proxies, prefix)
+	}
+
+	maxClients := conf.MaxClients
+	if maxClients <= 0 {
+		maxClients = defaultMaxClients
+	}
+
+	return &Limiter{
+		routes: map[string]routeLimits{
+			RouteChoose: {
+				perClient: newBuckets(conf.Choose.PerKey, maxClients),
+				perIP:     newBuckets(conf.Choose.PerIP, maxClients),
+			},
+			RouteTransition: {
+				perClient: newBuckets(conf.Transition.PerKey, maxClients),
+				perIP:     newBuckets(conf.Transition.PerIP, maxClients),
+			},
+		},
+		proxies: proxies,
+	}, nil
+}
+
+// parsePrefix адрес или подсеть в нотации CIDR.
+func parsePrefix(value string) (netip.Prefix, error) {
+	if strings.Contains(value, "/") {
+		prefix, err := netip.ParsePrefix(value)
+		return prefix.Masked(), err
+	}
+
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// ErrBurstExceeded пачка больше, чем бюджет может пропустить за один раз.
+var ErrBurstExceeded = errors.New("batch exceeds rate limit burst")
+
+// Reserve списывает токен из корзин клиента и IP. Если хотя бы в одной корзине токена нет,
+// ничего не списывается и возвращается время, через которое стоит повторить запрос.
+func (l *Limiter) Reserve(route, client, ip string, now time.Time) time.Duration {
+	delay, _ := l.ReserveN(map[string]int{route: 1}, client, ip, now)
+
+	return delay
+}
+
+// ReserveN списывает из бюджетов маршрутов по токену на каждый запрос пачки, например на каждый
+// клик и показ в пачке событий. Пачка проходит целиком или не списывается ничего.
+// ErrBurstExceeded - пачка не пройдет никогда, ее нужно разбить.
+func (l *Limiter) ReserveN(counts map[string]int, client, ip string, now time.Time) (time.Duration, error) {
+	if l == nil {
+		return 0, nil
+	}
+
+	reservations := make([]*rate.Reservation, 0, len(counts)*2)
+	for route, n := range counts {
+		limits, ok := l.routes[route]
+		if !ok || n <= 0 {
+			continue
+		}
+
+		if limits.perClient != nil {
+			reservations = append(reservations, limits.perClient.reserve(client, n, now))
+		}
+		if limits.perIP != nil {
+			reservations = append(reservations, limits.perIP.reserve(ip, n, now))
+		}
+	}
+
+	var (
+		delay time.Duration
+		err   error
+	)
+	for _, r := range reservations {
+		if !r.OK() {
+			err = ErrBurstExceeded
+			continue
+		}
+		delay = max(delay, r.DelayFrom(now))
+	}
+
+	if delay > 0 || err != nil {
+		for _, r := range reservations {
+			r.CancelAt(now)
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return delay, nil
+}
+
+// Events сколько списать из бюджетов за пачку событий: клик расходует бюджет переходов,
+// показ - бюджет выбора баннера, иначе пачками можно обойти оба ограничения.
+func Events(types []events.Type) map[string]int {
+	counts := make(map[string]int, 2)
+	for _, t := range types {
+		switch t {
+		case events.TypeClick:
+			counts[RouteTransition]++
+		case events.TypeDisplay:
+			counts[RouteChoose]++
+		default:
+		}
+	}
+
+	return counts
+}
+
+// Client клиент запроса для бюджета: ключ API из контекста, без него - арендатор.
+// Вызывается после аутентификации.
+func Client(ctx context.Context) string {
+	if key := app.APIKeyFromContext(ctx); key != nil {
+		return "key:" + strconv.FormatInt(key.ID, 10)
+	}
+
+	return "tenant:" + strconv.FormatInt(app.TenantFromContext(ctx), 10)
+}
+
+// ClientIP адрес клиента. X-Forwarded-For и X-Real-IP учитываются, только если запрос пришел
+// от доверенного прокси: в X-Forwarded-For берется последний адрес перед цепочкой доверенных прокси.
+func (l *Limiter) ClientIP(remoteAddr string, forwardedFor []string, realIP string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+
+	if l == nil || !l.trusted(host) {
+		return host
+	}
+
+	hops := make([]string, 0, len(forwardedFor))
+	for _, header := range forwardedFor {
+		for _, hop := range strings.Split(header, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		if !l.trusted(hops[i]) {
+			return hops[i]
+		}
+	}
+	if len(hops) > 0 {
+		return hops[0]
+	}
+
+	if realIP = strings.TrimSpace(realIP); realIP != "" {
+		return realIP
+	}
+
+	return host
+}
+
+func (l *Limiter) trusted(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, proxy := range l.proxies {
+		if proxy.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
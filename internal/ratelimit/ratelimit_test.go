@@ -0,0 +1,97 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"rotator/internal/app"
+	"rotator/internal/config"
+	"rotator/internal/events"
+	sqlstorage "rotator/internal/storage/sql"
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	limiter, err := New(config.RateLimitConf{
+		Enabled: true,
+		Choose: config.RateLimitRoute{
+			PerKey: config.RateLimit{RPS: 1, Burst: 1},
+			PerIP:  config.RateLimit{RPS: 1, Burst: 2},
+		},
+		TrustedProxies: []string{"10.0.0.0/8", "192.0.2.10"},
+		MaxClients:     2,
+	})
+	require.NoError(t, err)
+	now := time.Now()
+
+	t.Run("budget is refilled", func(t *testing.T) {
+		require.Zero(t, limiter.Reserve(RouteChoose, "key:1", "ip", now))
+		require.Equal(t, time.Second, limiter.Reserve(RouteChoose, "key:1", "ip", now))
+		require.Zero(t, limiter.Reserve(RouteChoose, "key:1", "ip", now.Add(time.Second)))
+	})
+
+	t.Run("unlimited route", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			require.Zero(t, limiter.Reserve(RouteTransition, "key:1", "ip", now))
+		}
+	})
+
+	t.Run("clients are capped", func(t *testing.T) {
+		perClient := limiter.routes[RouteChoose].perClient
+		for i := 0; i < 10; i++ {
+			limiter.Reserve(RouteChoose, "key:"+string(rune('a'+i)), "ip", now.Add(time.Minute))
+		}
+		require.Equal(t, 2, perClient.len())
+
+		limiter.Reserve(RouteChoose, "key:z", "ip", now.Add(time.Hour))
+		require.Equal(t, 1, perClient.len())
+	})
+
+	t.Run("client ip", func(t *testing.T) {
+		require.Equal(t, "203.0.113.1", limiter.ClientIP("203.0.113.1:1234", []string{"198.51.100.1"}, "198.51.100.2"))
+		require.Equal(t, "198.51.100.1", limiter.ClientIP("10.0.0.1:1234", []string{"1.1.1.1, 198.51.100.1"}, ""))
+		require.Equal(t, "198.51.100.1",
+			limiter.ClientIP("10.0.0.1:1234", []string{"198.51.100.1", "192.0.2.10, 10.0.0.2"}, ""))
+		require.Equal(t, "198.51.100.2", limiter.ClientIP("192.0.2.10:1234", nil, "198.51.100.2"))
+		require.Equal(t, "10.0.0.1", limiter.ClientIP("10.0.0.1:1234", nil, ""))
+	})
+
+	t.Run("client", func(t *testing.T) {
+		ctx := app.WithTenant(context.Background(), 3)
+		require.Equal(t, "tenant:3", Client(ctx))
+		require.Equal(t, "key:7", Client(app.WithAPIKey(ctx, &sqlstorage.APIKey{ID: 7})))
+	})
+
+	t.Run("batch is charged per event", func(t *testing.T) {
+		counts := Events([]events.Type{events.TypeClick, events.TypeDisplay, events.TypeDisplay, events.TypeDecision})
+		require.Equal(t, map[string]int{RouteTransition: 1, RouteChoose: 2}, counts)
+
+		later := now.Add(time.Hour * 2)
+		_, err := limiter.ReserveN(counts, "key:batch", "batch-ip", later)
+		require.ErrorIs(t, err, ErrBurstExceeded)
+
+		batch := Events([]events.Type{events.TypeClick, events.TypeDisplay})
+		delay, err := limiter.ReserveN(batch, "key:batch", "batch-ip", later)
+		require.NoError(t, err)
+		require.Zero(t, delay)
+
+		delay, err = limiter.ReserveN(batch, "key:batch", "batch-ip", later)
+		require.NoError(t, err)
+		require.Equal(t, time.Second, delay)
+
+		// отклоненные пачки ничего не списали
+		require.Zero(t, limiter.Reserve(RouteChoose, "key:batch", "batch-ip", later.Add(time.Second)))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		limiter, err := New(config.RateLimitConf{})
+		require.NoError(t, err)
+		require.Nil(t, limiter)
+		require.Zero(t, limiter.Reserve(RouteChoose, "key:1", "ip", now))
+	})
+
+	t.Run("invalid proxy", func(t *testing.T) {
+		_, err := New(config.RateLimitConf{Enabled: true, TrustedProxies: []string{"proxy"}})
+		require.Error(t, err)
+	})
+}
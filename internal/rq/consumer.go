@@ -0,0 +1,247 @@
+package rq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	rq "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+	"rotator/internal/app"
+	"rotator/internal/config"
+	"rotator/internal/events"
+	sqlstorage "rotator/internal/storage/sql"
+	"time"
+)
+
+const (
+	defaultBatchSize     = 500
+	defaultFlushInterval = time.Second
+	maxApplyAttempts     = 5
+)
+
+// BatchHandler обрабатывает пачку событий. Если вернулась ошибка, вся пачка
+// возвращается в очередь, а после нескольких неудачных попыток подряд уходит в dead letter.
+type BatchHandler func(ctx context.Context, batch []events.Event) error
+
+// Consumer читает события из очереди пачками и подтверждает их после обработки.
+// Нечитаемые события и пачки, которые не удалось применить за несколько попыток,
+// уходят в dead letter.
+type Consumer struct {
+	connector
+	consumer      string
+	batchSize     int
+	flushInterval time.Duration
+	deadLetters   DeadLetters
+}
+
+func NewConsumer(conf config.RabbitConf, batchSize int, flushInterval time.Duration,
+	deadLetters DeadLetters, logger app.Logger,
+) *Consumer {
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	if flushInterval <= 0 {
+		flushInterval = defaultFlushInterval
+	}
+
+	return &Consumer{
+		connector:     newConnector(conf, logger),
+		consumer:      "rotator-consumer",
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		deadLetters:   deadLetters,
+	}
+}
+
+// Consume блокируется, пока не отменен контекст.
+func (c *Consumer) Consume(ctx context.Context, handler BatchHandler) {
+	c.keepConnected(ctx, false, func(ctx context.Context, ch *rq.Channel, closed chan *rq.Error) error {
+		return c.consumeLoop(ctx, ch, closed, handler)
+	})
+}
+
+func (c *Consumer) consumeLoop(ctx context.Context, ch *rq.Channel, closed chan *rq.Error, handler BatchHandler) error {
+	if err := ch.Qos(c.batchSize, 0, false); err != nil {
+		return fmt.Errorf("failed to set qos: %w", err)
+	}
+
+	deliveries, err := ch.Consume(c.queue, c.consumer, false, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to consume queue %s: %w", c.queue, err)
+	}
+
+	ticker := time.NewTicker(c.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]events.Event, 0, c.batchSize)
+	raw := make([]rq.Delivery, 0, c.batchSize)
+	invalid := make([]rq.Delivery, 0)
+	letters := make([]sqlstorage.DeadLetter, 0)
+	var (
+		received int
+		failures int
+	)
+
+	reset := func() {
+		batch, raw, invalid, letters, received = batch[:0], raw[:0], invalid[:0], letters[:0], 0
+	}
+
+	flush := func() error {
+		if received == 0 {
+			return nil
+		}
+
+		// нечитаемые события не прочитаются ни с какой попытки, поэтому в очередь не возвращаются
+		if err := c.rejectInvalid(ctx, ch, invalid, letters); err != nil {
+			return err
+		}
+
+		if len(batch) > 0 {
+			if err := handler(ctx, batch); err != nil {
+				failures++
+				c.logger.Error("[-] Failed to apply events",
+					zap.Int("events", len(batch)), zap.Int("attempt", failures), zap.Error(err))
+
+				if failures < maxApplyAttempts {
+					return c.requeue(ctx, ch, raw, reset)
+				}
+
+				failed := make([]sqlstorage.DeadLetter, 0, len(raw))
+				for _, d := range raw {
+					failed = append(failed, letterFromDelivery(d, "can't apply: "+err.Error()))
+				}
+				if err := c.deadLetters.SaveDeadLetters(ctx, failed); err != nil {
+					c.logger.Error("[-] Failed to save dead letters", zap.Error(err))
+					return c.requeue(ctx, ch, raw, reset)
+				}
+			}
+		}
+
+		// пачка применена или сохранена в dead letter: повтор посчитал бы ее второй раз
+		for _, d := range raw {
+			if err := ch.Ack(d.DeliveryTag, false); err != nil {
+				return fmt.Errorf("failed to ack events: %w", err)
+			}
+		}
+
+		failures = 0
+		reset()
+
+		return nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-closed:
+			return closeError(err)
+		case <-ticker.C:
+			if err := flush(); err != nil {
+				return err
+			}
+		case d, ok := <-deliveries:
+			if !ok {
+				return errors.New("deliveries channel closed")
+			}
+
+			received++
+
+			event, err := decode(d)
+			if err != nil {
+				c.logger.Error("[-] Invalid event, moving to dead letter", zap.Uint64("tag", d.DeliveryTag), zap.Error(err))
+				invalid = append(invalid, d)
+				letters = append(letters, letterFromDelivery(d, "can't decode: "+err.Error()))
+			} else {
+				batch = append(batch, event)
+				raw = append(raw, d)
+			}
+
+			if received >= c.batchSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// rejectInvalid сохраняет нечитаемые события в dead letter и подтверждает их. Если сохранить
+// не удалось, события отклоняются без возврата в очередь.
+func (c *Consumer) rejectInvalid(ctx context.Context, ch *rq.Channel, invalid []rq.Delivery,
+	letters []sqlstorage.DeadLetter,
+) error {
+	if len(invalid) == 0 {
+		return nil
+	}
+
+	saved := true
+	if err := c.deadLetters.SaveDeadLetters(ctx, letters); err != nil {
+		c.logger.Error("[-] Failed to save dead letters, dropping invalid events",
+			zap.Int("events", len(invalid)), zap.Error(err))
+		saved = false
+	}
+
+	for _, d := range invalid {
+		var err error
+		if saved {
+			err = ch.Ack(d.DeliveryTag, false)
+		} else {
+			err = ch.Reject(d.DeliveryTag, false)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to settle invalid events: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// requeue возвращает события пачки в очередь и делает паузу перед следующей попыткой.
+func (c *Consumer) requeue(ctx context.Context, ch *rq.Channel, deliveries []rq.Delivery, reset func()) error {
+	for _, d := range deliveries {
+		if err := ch.Nack(d.DeliveryTag, false, true); err != nil {
+			return fmt.Errorf("failed to nack events: %w", err)
+		}
+	}
+
+	reset()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(c.reconnectDelay):
+	}
+
+	return nil
+}
+
+func letterFromDelivery(d rq.Delivery, reason string) sqlstorage.DeadLetter {
+	return sqlstorage.DeadLetter{
+		Body:          d.Body,
+		ContentType:   d.ContentType,
+		SchemaVersion: schemaVersion(d.Headers),
+		Reason:        reason,
+	}
+}
+
+func decode(d rq.Delivery) (events.Event, error) {
+	if err := events.CheckVersion(d.Headers[events.HeaderSchemaVersion]); err != nil {
+		return events.Event{}, err
+	}
+
+	codec, err := events.CodecFor(d.ContentType)
+	if err != nil {
+		return events.Event{}, err
+	}
+
+	event, err := codec.Decode(d.Body)
+	if err != nil {
+		return events.Event{}, err
+	}
+
+	if err := event.Validate(); err != nil {
+		return events.Event{}, err
+	}
+
+	return event, nil
+}
@@ -0,0 +1,49 @@
+package rq
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"rotator/internal/metrics"
+)
+
+// publisherCollector снимает счетчики издателя в момент сбора метрик.
+type publisherCollector struct {
+	rabbit *Rabbit
+
+	published, connected, buffered *prometheus.Desc
+}
+
+// Collector метрики отправки событий: результат отправки (success, dead_letter или failure -
+// событие потеряно, каждое событие учитывается один раз), состояние соединения с брокером
+// и заполненность буфера.
+func (r *Rabbit) Collector() prometheus.Collector {
+	return &publisherCollector{
+		rabbit: r,
+		published: prometheus.NewDesc(prometheus.BuildFQName(metrics.Namespace, "events", "published_total"),
+			"Event publish attempts by result.", []string{"result"}, nil),
+		connected: prometheus.NewDesc(prometheus.BuildFQName(metrics.Namespace, "events", "publisher_connected"),
+			"1 if the publisher is connected to the broker.", nil, nil),
+		buffered: prometheus.NewDesc(prometheus.BuildFQName(metrics.Namespace, "events", "buffered"),
+			"Events waiting to be published.", nil, nil),
+	}
+}
+
+func (c *publisherCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.published
+	ch <- c.connected
+	ch <- c.buffered
+}
+
+func (c *publisherCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.rabbit.Stats()
+
+	var connected float64
+	if s.Connected {
+		connected = 1
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.published, prometheus.CounterValue, float64(s.Published), "success")
+	ch <- prometheus.MustNewConstMetric(c.published, prometheus.CounterValue, float64(s.Failed), "failure")
+	ch <- prometheus.MustNewConstMetric(c.published, prometheus.CounterValue, float64(s.DeadLettered), "dead_letter")
+	ch <- prometheus.MustNewConstMetric(c.connected, prometheus.GaugeValue, connected)
+	ch <- prometheus.MustNewConstMetric(c.buffered, prometheus.GaugeValue, float64(s.Buffered))
+}
@@ -0,0 +1,53 @@
+package rq
+
+import (
+	"context"
+	"fmt"
+	rq "github.com/rabbitmq/amqp091-go"
+	"rotator/internal/app"
+	"rotator/internal/config"
+	"rotator/internal/events"
+	sqlstorage "rotator/internal/storage/sql"
+	"time"
+)
+
+// Replay заново публикует события из dead letter. done вызывается для каждого
+// события после подтверждения брокера. Возвращает число переотправленных событий.
+func Replay(ctx context.Context, conf config.RabbitConf, letters []sqlstorage.DeadLetter,
+	done func(letter sqlstorage.DeadLetter) error, logger app.Logger,
+) (int, error) {
+	c := newConnector(conf, logger)
+	conn, ch, err := c.connect(true)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	defer ch.Close()
+
+	for i, letter := range letters {
+		if ctx.Err() != nil {
+			return i, ctx.Err()
+		}
+
+		confirm, err := ch.PublishWithDeferredConfirm(c.exchange, c.queue, false, false, rq.Publishing{
+			Headers:      rq.Table{events.HeaderSchemaVersion: int32(letter.SchemaVersion)},
+			ContentType:  letter.ContentType,
+			DeliveryMode: rq.Persistent,
+			Timestamp:    time.Now(),
+			Body:         letter.Body,
+		})
+		if err != nil {
+			return i, fmt.Errorf("failed to publish dead letter %d: %w", letter.ID, err)
+		}
+
+		if !confirm.Wait() {
+			return i, fmt.Errorf("dead letter %d was rejected by broker", letter.ID)
+		}
+
+		if err := done(letter); err != nil {
+			return i, err
+		}
+	}
+
+	return len(letters), nil
+}
@@ -2,33 +2,334 @@ package rq
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	rq "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
 	"rotator/internal/app"
+	"rotator/internal/config"
+	"rotator/internal/events"
+	sqlstorage "rotator/internal/storage/sql"
+	"rotator/internal/tracing"
+	"sync/atomic"
+	"time"
 )
 
+const (
+	defaultBufferSize        = 10000
+	defaultReconnectDelay    = time.Second
+	defaultMaxReconnectDelay = time.Second * 30
+	maxPublishAttempts       = 3
+	deadLetterTimeout        = time.Second * 5
+)
+
+var ErrBufferFull = errors.New("publish buffer is full")
+
+// Stats состояние паблишера для мониторинга. Каждое событие учитывается в одном счетчике:
+// Published - отправлено, DeadLettered - сохранено в dead letter, Failed - потеряно.
+type Stats struct {
+	Connected    bool  `json:"connected"`
+	Published    int64 `json:"published"`
+	Failed       int64 `json:"failed"`
+	DeadLettered int64 `json:"dead_lettered"`
+	Buffered     int64 `json:"buffered"`
+}
+
+// DeadLetters хранилище событий, которые не удалось опубликовать или применить.
+type DeadLetters interface {
+	SaveDeadLetters(ctx context.Context, letters []sqlstorage.DeadLetter) error
+}
+
+// connector устанавливает соединение с RabbitMQ и восстанавливает его при обрыве,
+// каждый раз заново объявляя exchange и очередь.
+type connector struct {
+	url               string
+	exchange          string
+	queue             string
+	reconnectDelay    time.Duration
+	maxReconnectDelay time.Duration
+	logger            app.Logger
+	connected         int32
+}
+
+func newConnector(conf config.RabbitConf, logger app.Logger) connector {
+	c := connector{
+		url:               conf.Url,
+		exchange:          conf.Exchange,
+		queue:             conf.Queue,
+		reconnectDelay:    time.Duration(conf.ReconnectDelay),
+		maxReconnectDelay: time.Duration(conf.MaxReconnectDelay),
+		logger:            logger,
+	}
+
+	if c.reconnectDelay <= 0 {
+		c.reconnectDelay = defaultReconnectDelay
+	}
+	if c.maxReconnectDelay < c.reconnectDelay {
+		c.maxReconnectDelay = defaultMaxReconnectDelay
+	}
+
+	return c
+}
+
+// Rabbit публикует события с подтверждениями. Пока соединения нет,
+// события копятся в буфере в памяти.
 type Rabbit struct {
-	exchange string
-	queue    string
-	consumer string
-	channel  *rq.Channel
-	logger   app.Logger
+	connector
+	codec       events.Codec
+	deadLetters DeadLetters
+
+	buffer  chan rq.Publishing
+	pending *rq.Publishing
+	attempt int
+	done    chan struct{}
+
+	published    int64
+	failed       int64
+	deadLettered int64
+}
+
+// NewRabbit deadLetters может быть nil, тогда недоставленные события только считаются.
+func NewRabbit(ctx context.Context, conf config.RabbitConf, deadLetters DeadLetters, logger app.Logger) (*Rabbit, error) {
+	codec, err := events.NewCodec(conf.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Rabbit{
+		connector:   newConnector(conf, logger),
+		codec:       codec,
+		deadLetters: deadLetters,
+		done:        make(chan struct{}),
+	}
+
+	bufferSize := conf.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = defaultBufferSize
+	}
+	r.buffer = make(chan rq.Publishing, bufferSize)
+
+	go func() {
+		defer close(r.done)
+
+		r.keepConnected(ctx, true, r.publishLoop)
+		r.drain()
+	}()
+
+	return r, nil
 }
 
-func NewRabbit(ctx context.Context, url, exchange, queue string, logger app.Logger) (*Rabbit, error) {
-	conn, err := rq.Dial(url)
+// Publish кладет событие в буфер, отправка происходит в фоне.
+// Если буфер переполнен, событие сразу уходит в dead letter.
+// Контекст трассировки из ctx передается в заголовках сообщения.
+func (r *Rabbit) Publish(ctx context.Context, event events.Event) error {
+	ctx, span := tracing.Start(ctx, "Rabbit.Publish", trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "rabbitmq"),
+			attribute.String("messaging.destination.name", r.exchange),
+			attribute.String("event.type", string(event.Type)),
+		))
+	defer span.End()
+
+	body, err := r.codec.Encode(event)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to RabbitMQ on %s: %w", url, err)
+		atomic.AddInt64(&r.failed, 1)
+		tracing.Error(span, err)
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	msg := rq.Publishing{
+		Headers:      rq.Table{events.HeaderSchemaVersion: int32(events.SchemaVersion)},
+		ContentType:  r.codec.ContentType(),
+		DeliveryMode: rq.Persistent,
+		Timestamp:    time.Now(),
+		Type:         string(event.Type),
+		Body:         body,
+	}
+	tracing.Inject(ctx, headerCarrier(msg.Headers))
+
+	select {
+	case r.buffer <- msg:
+		return nil
+	default:
+		span.SetAttributes(attribute.Bool("dead_letter", true))
+		if err := r.deadLetter(ctx, ErrBufferFull.Error(), msg); err != nil {
+			atomic.AddInt64(&r.failed, 1)
+			err = fmt.Errorf("%w: %s", ErrBufferFull, err)
+			tracing.Error(span, err)
+			return err
+		}
+		return nil
+	}
+}
+
+// headerCarrier заголовки сообщения как носитель контекста трассировки (traceparent, tracestate).
+type headerCarrier rq.Table
+
+func (c headerCarrier) Get(key string) string {
+	value, _ := c[key].(string)
+
+	return value
+}
+
+func (c headerCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// Done закрывается, когда после отмены контекста публикация остановлена, а неотправленные
+// события сохранены в dead letter.
+func (r *Rabbit) Done() <-chan struct{} {
+	return r.done
+}
+
+// Connected есть ли сейчас соединение с брокером.
+func (r *Rabbit) Connected() bool {
+	return atomic.LoadInt32(&r.connected) == 1
+}
+
+func (r *Rabbit) Stats() Stats {
+	return Stats{
+		Connected:    r.Connected(),
+		Published:    atomic.LoadInt64(&r.published),
+		Failed:       atomic.LoadInt64(&r.failed),
+		DeadLettered: atomic.LoadInt64(&r.deadLettered),
+		Buffered:     int64(len(r.buffer)),
+	}
+}
+
+// drain при остановке сохраняет неотправленные события в dead letter, чтобы не потерять их.
+func (r *Rabbit) drain() {
+	msgs := make([]rq.Publishing, 0, len(r.buffer)+1)
+	if r.pending != nil {
+		msgs = append(msgs, *r.pending)
+		r.pending = nil
+	}
+
+	for len(r.buffer) > 0 {
+		msgs = append(msgs, <-r.buffer)
+	}
+
+	if len(msgs) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), deadLetterTimeout)
+	defer cancel()
+
+	if err := r.deadLetter(ctx, "publisher stopped", msgs...); err != nil {
+		atomic.AddInt64(&r.failed, int64(len(msgs)))
+		r.logger.Error("[-] Lost unpublished events", zap.Int("events", len(msgs)), zap.Error(err))
+	}
+}
+
+func (r *Rabbit) deadLetter(ctx context.Context, reason string, msgs ...rq.Publishing) error {
+	if r.deadLetters == nil {
+		return errors.New("dead letter store is not configured")
+	}
+
+	letters := make([]sqlstorage.DeadLetter, len(msgs))
+	for i, msg := range msgs {
+		letters[i] = sqlstorage.DeadLetter{
+			Body:          msg.Body,
+			ContentType:   msg.ContentType,
+			SchemaVersion: schemaVersion(msg.Headers),
+			Reason:        reason,
+		}
+	}
+
+	if err := r.deadLetters.SaveDeadLetters(ctx, letters); err != nil {
+		return err
+	}
+
+	atomic.AddInt64(&r.deadLettered, int64(len(letters)))
+
+	return nil
+}
+
+// keepConnected держит соединение и запускает на нем work, пока не закончится контекст.
+func (c *connector) keepConnected(ctx context.Context, confirm bool, work func(context.Context, *rq.Channel, chan *rq.Error) error) {
+	delay := c.reconnectDelay
+
+	for {
+		conn, ch, err := c.connect(confirm)
+		if err != nil {
+			c.logger.Error("[-] Failed to connect to RabbitMQ, retrying",
+				zap.Error(err), zap.Duration("delay", delay))
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+
+			delay *= 2
+			if delay > c.maxReconnectDelay {
+				delay = c.maxReconnectDelay
+			}
+
+			continue
+		}
+
+		delay = c.reconnectDelay
+		atomic.StoreInt32(&c.connected, 1)
+		c.logger.Info("[+] Connected to RabbitMQ", zap.String("exchange", c.exchange), zap.String("queue", c.queue))
+
+		err = work(ctx, ch, conn.NotifyClose(make(chan *rq.Error, 1)))
+
+		atomic.StoreInt32(&c.connected, 0)
+		ch.Close()
+		conn.Close()
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		c.logger.Error("[-] Lost connection to RabbitMQ, reconnecting", zap.Error(err))
+	}
+}
+
+func (c *connector) connect(confirm bool) (*rq.Connection, *rq.Channel, error) {
+	conn, err := rq.Dial(c.url)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to RabbitMQ on %s: %w", c.url, err)
 	}
 
 	ch, err := conn.Channel()
 	if err != nil {
-		return nil, fmt.Errorf("failed to open RabbitMQ Channel on %s: %w", url, err)
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to open RabbitMQ Channel on %s: %w", c.url, err)
 	}
 
-	if len(exchange) > 0 {
-		err = ch.ExchangeDeclare(
-			exchange,
+	if err := c.declare(ch); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	if confirm {
+		if err := ch.Confirm(false); err != nil {
+			conn.Close()
+			return nil, nil, fmt.Errorf("failed to enable publisher confirms: %w", err)
+		}
+	}
+
+	return conn, ch, nil
+}
+
+func (c *connector) declare(ch *rq.Channel) error {
+	if len(c.exchange) > 0 {
+		err := ch.ExchangeDeclare(
+			c.exchange,
 			rq.ExchangeDirect,
 			true,
 			false,
@@ -37,12 +338,12 @@ func NewRabbit(ctx context.Context, url, exchange, queue string, logger app.Logg
 			nil,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to declare an exchange %s: %w", exchange, err)
+			return fmt.Errorf("failed to declare an exchange %s: %w", c.exchange, err)
 		}
 	}
 
 	q, err := ch.QueueDeclare(
-		queue,
+		c.queue,
 		false,
 		false,
 		false,
@@ -50,31 +351,83 @@ func NewRabbit(ctx context.Context, url, exchange, queue string, logger app.Logg
 		nil,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to declare a queue %s: %w", queue, err)
+		return fmt.Errorf("failed to declare a queue %s: %w", c.queue, err)
 	}
 
 	err = ch.QueueBind(
 		q.Name,
 		q.Name,
-		exchange,
+		c.exchange,
 		false,
 		nil,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to bind queue: %w", err)
+		return fmt.Errorf("failed to bind queue: %w", err)
 	}
 
-	go func() {
-		<-ctx.Done()
-		ch.Close()
-		conn.Close()
-	}()
+	return nil
+}
+
+// publishLoop отправляет события из буфера, пока живо соединение.
+// Неподтвержденное событие остается в pending и уходит первым после переподключения.
+func (r *Rabbit) publishLoop(ctx context.Context, ch *rq.Channel, closed chan *rq.Error) error {
+	for {
+		if r.pending == nil {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case err := <-closed:
+				return closeError(err)
+			case msg := <-r.buffer:
+				r.pending = &msg
+				r.attempt = 0
+			}
+		}
 
-	return &Rabbit{
-		exchange: exchange,
-		queue:    queue,
-		consumer: "rotator-consumer",
-		channel:  &rq.Channel{},
-		logger:   logger,
-	}, nil
+		r.attempt++
+		confirm, err := ch.PublishWithDeferredConfirm(r.exchange, r.queue, false, false, *r.pending)
+		if err != nil {
+			return fmt.Errorf("failed to publish: %w", err)
+		}
+
+		if confirm.Wait() {
+			atomic.AddInt64(&r.published, 1)
+			r.pending = nil
+			continue
+		}
+
+		if ch.IsClosed() {
+			return errors.New("channel closed before publish was confirmed")
+		}
+
+		if r.attempt >= maxPublishAttempts {
+			r.logger.Error("[-] Event was rejected by RabbitMQ, moving to dead letter", zap.Int("attempts", r.attempt))
+			if err := r.deadLetter(ctx, "rejected by broker", *r.pending); err != nil {
+				atomic.AddInt64(&r.failed, 1)
+				r.logger.Error("[-] Lost rejected event", zap.Error(err))
+			}
+			r.pending = nil
+		}
+	}
+}
+
+func closeError(err *rq.Error) error {
+	if err == nil {
+		return errors.New("connection closed")
+	}
+
+	return err
+}
+
+func schemaVersion(headers rq.Table) int {
+	switch v := headers[events.HeaderSchemaVersion].(type) {
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case int:
+		return v
+	default:
+		return events.SchemaVersion
+	}
 }
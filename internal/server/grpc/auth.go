@@ -0,0 +1,108 @@
+package internalgrpc
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"rotator/internal/app"
+	sqlstorage "rotator/internal/storage/sql"
+	"strings"
+)
+
+// methodRoles роль, которая нужна для метода. Остальные методы доступны только admin.
+var methodRoles = map[string]string{
+	"ChooseBanner":       app.RoleServing,
+	"ChooseBannerStream": app.RoleServing,
+	"CountTransition":    app.RoleServing,
+	"ApplyEvents":        app.RoleServing,
+	"ExplainBanner":      app.RoleAnalytics,
+	"GetStats":           app.RoleAnalytics,
+	"GetStatsSeries":     app.RoleAnalytics,
+}
+
+func methodRole(fullMethod string) string {
+	if role, ok := methodRoles[fullMethod[strings.LastIndex(fullMethod, "/")+1:]]; ok {
+		return role
+	}
+
+	return app.RoleAdmin
+}
+
+// apiKeyFromMetadata ключ передается в authorization: Bearer <key> или x-api-key.
+func apiKeyFromMetadata(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	for _, auth := range md.Get("authorization") {
+		if strings.HasPrefix(auth, "Bearer ") {
+			return strings.TrimPrefix(auth, "Bearer ")
+		}
+	}
+
+	return metadataValue(ctx, "x-api-key")
+}
+
+func metadataValue(ctx context.Context, name string) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(name); len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+// authenticate проверяет ключ, если аутентификация включена, и определяет арендатора:
+// по ключу или по метаданным x-tenant-id.
+func authenticate(ctx context.Context, a *app.App, logger Logger, fullMethod string) (context.Context, error) {
+	var key *sqlstorage.APIKey
+	if a.AuthEnabled {
+		var err error
+		key, err = a.Authenticate(ctx, apiKeyFromMetadata(ctx))
+		if err == nil {
+			err = app.Authorize(key, methodRole(fullMethod))
+		}
+		if err != nil {
+			return nil, toStatus(logger, fullMethod, err).Err()
+		}
+
+		ctx = app.WithAPIKey(ctx, key)
+	}
+
+	tenantID, err := app.ResolveTenant(key, metadataValue(ctx, "x-tenant-id"))
+	if err != nil {
+		return nil, toStatus(logger, fullMethod, err).Err()
+	}
+
+	return app.WithTenant(ctx, tenantID), nil
+}
+
+func authInterceptor(a *app.App, logger Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, a, logger, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func authStreamInterceptor(a *app.App, logger Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), a, logger, info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// contextStream поток с контекстом, дополненным перехватчиком: ключом запроса, спаном.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,70 @@
+package internalgrpc
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+	"rotator/internal/app"
+	"rotator/internal/server/grpc/pb"
+	sqlstorage "rotator/internal/storage/sql"
+	"testing"
+)
+
+// keyStorage знает только ключ "serving" с ролью serving.
+type keyStorage struct {
+	fakeStorage
+}
+
+func (keyStorage) GetAPIKeyByHash(_ context.Context, keyHash []byte) (*sqlstorage.APIKey, error) {
+	if bytes.Equal(app.HashAPIKey(app.RoleServing), keyHash) {
+		return &sqlstorage.APIKey{ID: 1, Name: "site", Role: app.RoleServing}, nil
+	}
+
+	return nil, nil
+}
+
+func TestAuth(t *testing.T) {
+	a := app.New(nopLogger{}, keyStorage{}, nil)
+	a.AuthEnabled = true
+
+	lis := bufconn.Listen(1024 * 1024)
+	server := NewServer("", "", 4, nil, a, nopLogger{})
+	go server.server.Serve(lis)
+	defer server.server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client := pb.NewRotatorClient(conn)
+	withKey := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer serving")
+
+	t.Run("missing key", func(t *testing.T) {
+		_, err := client.ChooseBanner(context.Background(), &pb.ChooseBannerRequest{SlotId: 1, SocialGroupId: 1})
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("serving role", func(t *testing.T) {
+		_, err := client.ChooseBanner(withKey, &pb.ChooseBannerRequest{SlotId: 1, SocialGroupId: 1})
+		require.NoError(t, err)
+
+		_, err = client.AddBannerToSlot(withKey, &pb.BannerToSlotRequest{BannerId: 1, SlotId: 1})
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		stream, err := client.ChooseBannerStream(context.Background())
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
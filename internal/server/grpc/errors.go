@@ -0,0 +1,68 @@
+package internalgrpc
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"rotator/internal/app"
+)
+
+// errorDomain домен для ErrorInfo, по reason клиенты различают ошибки.
+const errorDomain = "rotator"
+
+var errorCodes = map[app.ErrorKind]codes.Code{
+	app.KindInternal:         codes.Internal,
+	app.KindNotFound:         codes.NotFound,
+	app.KindAlreadyExists:    codes.AlreadyExists,
+	app.KindValidation:       codes.InvalidArgument,
+	app.KindUnavailable:      codes.Unavailable,
+	app.KindUnauthenticated:  codes.Unauthenticated,
+	app.KindPermissionDenied: codes.PermissionDenied,
+}
+
+// errorsInterceptor переводит ошибки приложения в gRPC статусы.
+func errorsInterceptor(logger Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return nil, toStatus(logger, info.FullMethod, err).Err()
+		}
+
+		return resp, nil
+	}
+}
+
+// toStatus ошибки, которые уже являются статусом, возвращаются как есть.
+// Подробности внутренних ошибок только логируются.
+func toStatus(logger Logger, method string, err error) *status.Status {
+	if st, ok := status.FromError(err); ok {
+		return st
+	}
+
+	appErr := app.AsError(err)
+	if appErr.Kind == app.KindInternal || appErr.Kind == app.KindUnavailable {
+		logger.Error("[-] Request failed", zap.String("method", method), zap.Error(err))
+	}
+
+	st := status.New(errorCodes[appErr.Kind], appErr.PublicMessage())
+	withDetails, detailsErr := st.WithDetails(&errdetails.ErrorInfo{Reason: appErr.Code, Domain: errorDomain})
+	if detailsErr != nil {
+		return st
+	}
+
+	return withDetails
+}
+
+// errorReason достает стабильный код ошибки из статуса.
+func errorReason(st *status.Status) string {
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			return info.GetReason()
+		}
+	}
+
+	return ""
+}
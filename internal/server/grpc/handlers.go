@@ -0,0 +1,443 @@
+package internalgrpc
+
+import (
+	"context"
+	"fmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"math"
+	bandit "rotator/internal/alghoritms"
+	"rotator/internal/app"
+	"rotator/internal/events"
+	"rotator/internal/server/grpc/pb"
+)
+
+// maxEventsBatch ограничение на размер пачки событий в одном запросе, как в REST.
+const maxEventsBatch = 1000
+
+// maxImportRows ограничение на число строк статистики в одном запросе импорта, как в REST.
+const maxImportRows = 1000
+
+type Handlers struct {
+	pb.UnimplementedRotatorServer
+	app               *app.App
+	logger            Logger
+	streamConcurrency int
+}
+
+func NewHandlers(a *app.App, streamConcurrency int, logger Logger) *Handlers {
+	if streamConcurrency <= 0 {
+		streamConcurrency = defaultStreamConcurrency
+	}
+
+	return &Handlers{app: a, logger: logger, streamConcurrency: streamConcurrency}
+}
+
+func (h *Handlers) AddBannerToSlot(ctx context.Context, req *pb.BannerToSlotRequest) (*emptypb.Empty, error) {
+	warmStart := app.WarmStart{
+		Source:   req.GetWarmStart().GetSource(),
+		BannerID: req.GetWarmStart().GetBannerId(),
+		Displays: req.GetWarmStart().GetDisplays(),
+	}
+	err := h.app.AddBannerToSlot(ctx, req.GetBannerId(), req.GetSlotId(), warmStart, schedule(req.GetSchedule()))
+	if err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (h *Handlers) RemoveBannerFromSlot(ctx context.Context, req *pb.BannerToSlotRequest) (*emptypb.Empty, error) {
+	if err := h.app.RemoveBannerToSlot(ctx, req.GetBannerId(), req.GetSlotId()); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (h *Handlers) CountTransition(ctx context.Context, req *pb.CountTransitionRequest) (*emptypb.Empty, error) {
+	err := h.app.CountTransition(ctx, req.GetBannerId(), req.GetSlotId(), req.GetSocialGroupId(), req.GetImpressionId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (h *Handlers) ChooseBanner(ctx context.Context, req *pb.ChooseBannerRequest) (*pb.ChooseBannerResponse, error) {
+	choice, err := h.app.ChooseBanner(ctx, req.GetSlotId(), req.GetSocialGroupId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ChooseBannerResponse{
+		BannerId:     choice.BannerID,
+		ImpressionId: choice.ImpressionID,
+	}, nil
+}
+
+func (h *Handlers) ExplainBanner(ctx context.Context, req *pb.ChooseBannerRequest) (*pb.ExplainBannerResponse, error) {
+	explanation, err := h.app.ExplainBanner(ctx, req.GetSlotId(), req.GetSocialGroupId())
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]*pb.BannerEstimate, len(explanation.Candidates))
+	for i, c := range explanation.Candidates {
+		candidates[i] = &pb.BannerEstimate{
+			BannerId:         int64(c.ID),
+			Display:          int64(c.Trials),
+			Click:            int64(c.Reward),
+			Ctr:              c.CTR(),
+			Mean:             c.Mean,
+			ExplorationBonus: c.Bonus,
+			Score:            c.Score,
+			Unexplored:       math.IsInf(c.Score, 1),
+			PriorAlpha:       c.Alpha,
+			PriorBeta:        c.Beta,
+		}
+	}
+
+	return &pb.ExplainBannerResponse{
+		Strategy:     explanation.Strategy,
+		TotalDisplay: int64(explanation.TotalDisplay),
+		BannerId:     explanation.WinnerID,
+		Candidates:   candidates,
+	}, nil
+}
+
+func (h *Handlers) ApplyEvents(ctx context.Context, req *pb.ApplyEventsRequest) (*pb.ApplyEventsResponse, error) {
+	if len(req.GetEvents()) > maxEventsBatch {
+		return nil, status.Errorf(codes.InvalidArgument, "batch is limited to %d events", maxEventsBatch)
+	}
+
+	batch := make([]events.Event, len(req.GetEvents()))
+	for i, e := range req.GetEvents() {
+		batch[i] = events.Event{
+			Type:          events.Type(e.GetType()),
+			SlotID:        e.GetSlotId(),
+			BannerID:      e.GetBannerId(),
+			SocialGroupID: e.GetSocialGroupId(),
+			ImpressionID:  e.GetImpressionId(),
+		}
+		if e.GetTimestamp() != nil {
+			batch[i].Timestamp = e.GetTimestamp().AsTime()
+		}
+	}
+
+	results, err := h.app.ApplyEvents(ctx, batch)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &pb.ApplyEventsResponse{Results: make([]*pb.EventResult, len(results))}
+	for i, err := range results {
+		response.Results[i] = &pb.EventResult{Index: int32(i), Success: err == nil}
+		if err != nil {
+			appErr := app.AsError(err)
+			response.Results[i].Error = appErr.PublicMessage()
+			response.Results[i].Code = appErr.Code
+		}
+	}
+
+	return response, nil
+}
+
+func (h *Handlers) ImportStats(ctx context.Context, req *pb.ImportStatsRequest) (*pb.ImportStatsResponse, error) {
+	if len(req.GetRows()) > maxImportRows {
+		return nil, status.Errorf(codes.InvalidArgument, "import is limited to %d rows", maxImportRows)
+	}
+
+	mode := req.GetMode()
+	if mode == "" {
+		mode = app.ImportReplace
+	}
+
+	rows := make([]app.ImportRow, len(req.GetRows()))
+	for i, r := range req.GetRows() {
+		rows[i] = app.ImportRow{
+			SlotID:        r.GetSlotId(),
+			BannerID:      r.GetBannerId(),
+			SocialGroupID: r.GetSocialGroupId(),
+			Display:       r.GetDisplay(),
+			Click:         r.GetClick(),
+		}
+	}
+
+	results, err := h.app.ImportStats(ctx, mode, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &pb.ImportStatsResponse{Results: make([]*pb.EventResult, len(results))}
+	for i, err := range results {
+		response.Results[i] = &pb.EventResult{Index: int32(i), Success: err == nil}
+		if err != nil {
+			appErr := app.AsError(err)
+			response.Results[i].Error = appErr.PublicMessage()
+			response.Results[i].Code = appErr.Code
+		}
+	}
+
+	return response, nil
+}
+
+func statsQuery(req *pb.GetStatsRequest) app.StatsQuery {
+	return app.StatsQuery{
+		GroupBy:       req.GetGroupBy(),
+		SlotID:        req.GetSlotId(),
+		BannerID:      req.GetBannerId(),
+		SocialGroupID: req.GetSocialGroupId(),
+		Confidence:    req.GetConfidence(),
+	}
+}
+
+func statsRow(r app.StatsRow) *pb.StatsRow {
+	return &pb.StatsRow{
+		SlotId:        r.SlotID,
+		BannerId:      r.BannerID,
+		SocialGroupId: r.SocialGroupID,
+		Display:       r.Display,
+		Click:         r.Click,
+		Ctr:           r.CTR,
+		CtrLower:      r.CTRLower,
+		CtrUpper:      r.CTRUpper,
+	}
+}
+
+func (h *Handlers) GetStats(ctx context.Context, req *pb.GetStatsRequest) (*pb.GetStatsResponse, error) {
+	stats, err := h.app.GetStats(ctx, statsQuery(req))
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]*pb.StatsRow, len(stats.Rows))
+	for i, r := range stats.Rows {
+		rows[i] = statsRow(r)
+	}
+
+	return &pb.GetStatsResponse{
+		GroupBy:    stats.GroupBy,
+		Confidence: stats.Confidence,
+		Rows:       rows,
+	}, nil
+}
+
+func (h *Handlers) GetStatsSeries(ctx context.Context, req *pb.GetStatsSeriesRequest) (*pb.GetStatsSeriesResponse, error) {
+	query := app.SeriesQuery{StatsQuery: statsQuery(req.GetStats()), Interval: req.GetInterval()}
+	if req.GetFrom() != nil {
+		query.From = req.GetFrom().AsTime()
+	}
+	if req.GetTo() != nil {
+		query.To = req.GetTo().AsTime()
+	}
+
+	series, err := h.app.GetStatsSeries(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]*pb.StatsPoint, len(series.Points))
+	for i, p := range series.Points {
+		points[i] = &pb.StatsPoint{Time: timestamppb.New(p.Time), Stats: statsRow(p.StatsRow)}
+	}
+
+	return &pb.GetStatsSeriesResponse{
+		GroupBy:    series.GroupBy,
+		Confidence: series.Confidence,
+		Interval:   series.Interval,
+		From:       timestamppb.New(series.From),
+		To:         timestamppb.New(series.To),
+		Points:     points,
+	}, nil
+}
+
+func (h *Handlers) CreateCatalogItem(ctx context.Context, req *pb.CreateCatalogItemRequest) (*pb.CatalogItem, error) {
+	catalog, err := catalogName(req.GetCatalog())
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := h.app.CreateCatalogItem(ctx, catalog, req.GetDescription())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CatalogItem{Id: id, Description: req.GetDescription()}, nil
+}
+
+func (h *Handlers) GetCatalogItem(ctx context.Context, req *pb.CatalogItemRequest) (*pb.CatalogItem, error) {
+	catalog, err := catalogName(req.GetCatalog())
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := h.app.GetCatalogItem(ctx, catalog, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CatalogItem{Id: item.ID, Description: item.Description}, nil
+}
+
+func (h *Handlers) ListCatalogItems(ctx context.Context, req *pb.ListCatalogItemsRequest) (*pb.ListCatalogItemsResponse, error) {
+	catalog, err := catalogName(req.GetCatalog())
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := h.app.ListCatalogItems(ctx, catalog)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &pb.ListCatalogItemsResponse{Items: make([]*pb.CatalogItem, len(items))}
+	for i, item := range items {
+		response.Items[i] = &pb.CatalogItem{Id: item.ID, Description: item.Description}
+	}
+
+	return response, nil
+}
+
+func (h *Handlers) UpdateCatalogItem(ctx context.Context, req *pb.UpdateCatalogItemRequest) (*pb.CatalogItem, error) {
+	catalog, err := catalogName(req.GetCatalog())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.app.UpdateCatalogItem(ctx, catalog, req.GetId(), req.GetDescription()); err != nil {
+		return nil, err
+	}
+
+	return &pb.CatalogItem{Id: req.GetId(), Description: req.GetDescription()}, nil
+}
+
+func (h *Handlers) DeleteCatalogItem(ctx context.Context, req *pb.CatalogItemRequest) (*emptypb.Empty, error) {
+	catalog, err := catalogName(req.GetCatalog())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.app.DeleteCatalogItem(ctx, catalog, req.GetId()); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func catalogName(catalog pb.Catalog) (string, error) {
+	switch catalog {
+	case pb.Catalog_CATALOG_BANNER:
+		return app.CatalogBanner, nil
+	case pb.Catalog_CATALOG_SLOT:
+		return app.CatalogSlot, nil
+	case pb.Catalog_CATALOG_SOCIAL_GROUP:
+		return app.CatalogSocialGroup, nil
+	default:
+		return "", status.Error(codes.InvalidArgument, fmt.Sprintf("unknown catalog %s", catalog))
+	}
+}
+
+func slotPrior(prior app.SlotPrior) *pb.SlotPrior {
+	return &pb.SlotPrior{
+		SlotId:  prior.SlotID,
+		Prior:   &pb.Prior{Alpha: prior.Alpha, Beta: prior.Beta},
+		Default: prior.Default,
+	}
+}
+
+func (h *Handlers) GetSlotPrior(ctx context.Context, req *pb.SlotPriorRequest) (*pb.SlotPrior, error) {
+	prior, err := h.app.GetSlotPrior(ctx, req.GetSlotId())
+	if err != nil {
+		return nil, err
+	}
+
+	return slotPrior(prior), nil
+}
+
+func (h *Handlers) SetSlotPrior(ctx context.Context, req *pb.SetSlotPriorRequest) (*pb.SlotPrior, error) {
+	var prior *bandit.Prior
+	if req.GetPrior() != nil {
+		prior = &bandit.Prior{Alpha: req.GetPrior().GetAlpha(), Beta: req.GetPrior().GetBeta()}
+	}
+
+	result, err := h.app.SetSlotPrior(ctx, req.GetSlotId(), prior)
+	if err != nil {
+		return nil, err
+	}
+
+	return slotPrior(result), nil
+}
+
+func schedule(req *pb.Schedule) app.Schedule {
+	var result app.Schedule
+	if req.GetFrom() != nil {
+		result.From = req.GetFrom().AsTime()
+	}
+	if req.GetTo() != nil {
+		result.To = req.GetTo().AsTime()
+	}
+	for _, day := range req.GetWeekdays() {
+		result.Weekdays = append(result.Weekdays, int(day))
+	}
+	for _, hour := range req.GetHours() {
+		result.Hours = append(result.Hours, int(hour))
+	}
+
+	return result
+}
+
+func bannerSchedule(bannerID, slotID int64, schedule app.Schedule) *pb.BannerSchedule {
+	result := &pb.Schedule{}
+	if !schedule.From.IsZero() {
+		result.From = timestamppb.New(schedule.From)
+	}
+	if !schedule.To.IsZero() {
+		result.To = timestamppb.New(schedule.To)
+	}
+	for _, day := range schedule.Weekdays {
+		result.Weekdays = append(result.Weekdays, int32(day))
+	}
+	for _, hour := range schedule.Hours {
+		result.Hours = append(result.Hours, int32(hour))
+	}
+
+	return &pb.BannerSchedule{BannerId: bannerID, SlotId: slotID, Schedule: result}
+}
+
+func (h *Handlers) GetBannerSchedule(ctx context.Context, req *pb.BannerToSlotRequest) (*pb.BannerSchedule, error) {
+	result, err := h.app.GetBannerSchedule(ctx, req.GetBannerId(), req.GetSlotId())
+	if err != nil {
+		return nil, err
+	}
+
+	return bannerSchedule(req.GetBannerId(), req.GetSlotId(), result), nil
+}
+
+func (h *Handlers) SetBannerSchedule(ctx context.Context, req *pb.BannerSchedule) (*pb.BannerSchedule, error) {
+	result := schedule(req.GetSchedule())
+	if err := h.app.SetBannerSchedule(ctx, req.GetBannerId(), req.GetSlotId(), result); err != nil {
+		return nil, err
+	}
+
+	return bannerSchedule(req.GetBannerId(), req.GetSlotId(), result), nil
+}
+
+func (h *Handlers) GetBannerStatus(ctx context.Context, req *pb.BannerToSlotRequest) (*pb.BannerStatus, error) {
+	status, err := h.app.GetBannerStatus(ctx, req.GetBannerId(), req.GetSlotId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.BannerStatus{BannerId: req.GetBannerId(), SlotId: req.GetSlotId(), Status: status}, nil
+}
+
+func (h *Handlers) SetBannerStatus(ctx context.Context, req *pb.BannerStatus) (*pb.BannerStatus, error) {
+	if err := h.app.SetBannerStatus(ctx, req.GetBannerId(), req.GetSlotId(), req.GetStatus()); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
@@ -0,0 +1,156 @@
+package internalgrpc
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"rotator/internal/metrics"
+	"rotator/internal/tracing"
+	"strings"
+	"time"
+)
+
+func loggingInterceptor(logger Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logRequest(ctx, logger, info.FullMethod, start, err)
+
+		return resp, err
+	}
+}
+
+func loggingStreamInterceptor(logger Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logRequest(ss.Context(), logger, info.FullMethod, start, err)
+
+		return err
+	}
+}
+
+func logRequest(ctx context.Context, logger Logger, method string, start time.Time, err error) {
+	var addr string
+	if p, ok := peer.FromContext(ctx); ok {
+		addr = p.Addr.String()
+	}
+
+	logger.Info("gRPC logger:",
+		zap.String("peer", addr),
+		zap.String("method", method),
+		zap.String("code", status.Code(err).String()),
+		zap.Duration("duration", time.Since(start)))
+}
+
+const (
+	callUnary  = "unary"
+	callStream = "stream"
+)
+
+// metricsInterceptor считает вызовы и их длительность по методам.
+func metricsInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		metrics.ObserveGRPC(info.FullMethod, callUnary, status.Code(err).String(), time.Since(start))
+
+		return resp, err
+	}
+}
+
+func metricsStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		metrics.ObserveGRPC(info.FullMethod, callStream, status.Code(err).String(), time.Since(start))
+
+		return err
+	}
+}
+
+// tracingInterceptor начинает спан вызова, продолжая трассу из метаданных traceparent и tracestate.
+func tracingInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, span := startSpan(ctx, info.FullMethod)
+		defer span.End()
+
+		resp, err := handler(ctx, req)
+		endSpan(span, err)
+
+		return resp, err
+	}
+}
+
+func tracingStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, span := startSpan(ss.Context(), info.FullMethod)
+		defer span.End()
+
+		err := handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+		endSpan(span, err)
+
+		return err
+	}
+}
+
+func startSpan(ctx context.Context, fullMethod string) (context.Context, trace.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = tracing.Extract(ctx, metadataCarrier(md))
+
+	name := strings.TrimPrefix(fullMethod, "/")
+	service, method, _ := strings.Cut(name, "/")
+
+	return tracing.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "grpc"),
+			attribute.String("rpc.service", service),
+			attribute.String("rpc.method", method),
+		))
+}
+
+// endSpan ошибкой спана считаются только коды, которые означают сбой сервера,
+// а не неверный запрос клиента.
+func endSpan(span trace.Span, err error) {
+	code := status.Code(err)
+	span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(code)))
+
+	switch code {
+	case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented, codes.Internal, codes.Unavailable,
+		codes.DataLoss:
+		tracing.Error(span, err)
+	default:
+	}
+}
+
+// metadataCarrier метаданные gRPC как носитель контекста трассировки.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
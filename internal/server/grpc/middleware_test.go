@@ -0,0 +1,122 @@
+package internalgrpc
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"io"
+	"net"
+	"rotator/internal/app"
+	"rotator/internal/config"
+	"rotator/internal/events"
+	"rotator/internal/metrics"
+	"rotator/internal/ratelimit"
+	"rotator/internal/server/grpc/pb"
+	"rotator/internal/tracing"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, limiter *ratelimit.Limiter, a *app.App) pb.RotatorClient {
+	t.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+	server := NewServer("", "", 4, limiter, a, nopLogger{})
+	go server.server.Serve(lis)
+	t.Cleanup(server.server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewRotatorClient(conn)
+}
+
+func TestMetricsInterceptor(t *testing.T) {
+	client := newTestClient(t, nil, app.New(nopLogger{}, fakeStorage{}, nil))
+	ctx := context.Background()
+
+	t.Run("unary", func(t *testing.T) {
+		ok := metrics.GRPCRequests.WithLabelValues("/rotator.v1.Rotator/ChooseBanner", callUnary, "OK")
+		notFound := metrics.GRPCRequests.WithLabelValues("/rotator.v1.Rotator/ChooseBanner", callUnary, "NotFound")
+		okBefore, notFoundBefore := testutil.ToFloat64(ok), testutil.ToFloat64(notFound)
+
+		_, err := client.ChooseBanner(ctx, &pb.ChooseBannerRequest{SlotId: 1, SocialGroupId: 1})
+		require.NoError(t, err)
+		_, err = client.ChooseBanner(ctx, &pb.ChooseBannerRequest{SlotId: 3, SocialGroupId: 1})
+		require.Equal(t, codes.NotFound, status.Code(err))
+
+		require.Equal(t, okBefore+1, testutil.ToFloat64(ok))
+		require.Equal(t, notFoundBefore+1, testutil.ToFloat64(notFound))
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		counter := metrics.GRPCRequests.WithLabelValues("/rotator.v1.Rotator/ChooseBannerStream", callStream, "OK")
+		before := testutil.ToFloat64(counter)
+
+		stream, err := client.ChooseBannerStream(ctx)
+		require.NoError(t, err)
+		require.NoError(t, stream.CloseSend())
+		_, err = stream.Recv()
+		require.ErrorIs(t, err, io.EOF)
+
+		// клиент получает конец потока раньше, чем перехватчик на сервере учтет вызов
+		require.Eventually(t, func() bool {
+			return testutil.ToFloat64(counter) == before+1
+		}, time.Second, time.Millisecond*10)
+	})
+}
+
+// tracePublisher запоминает трассу, в которой публиковалось событие.
+type tracePublisher struct {
+	traceID *trace.TraceID
+}
+
+func (p tracePublisher) Publish(ctx context.Context, _ events.Event) error {
+	*p.traceID = trace.SpanContextFromContext(ctx).TraceID()
+
+	return nil
+}
+
+func TestTracingInterceptor(t *testing.T) {
+	_, err := tracing.Init(context.Background(), config.TracingConf{}, "rotator")
+	require.NoError(t, err)
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	var published trace.TraceID
+	client := newTestClient(t, nil, app.New(nopLogger{}, fakeStorage{}, tracePublisher{traceID: &published}))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(),
+		"traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, err = client.ChooseBanner(ctx, &pb.ChooseBannerRequest{SlotId: 1, SocialGroupId: 1})
+	require.NoError(t, err)
+
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", published.String())
+
+	// спан вызова заканчивается на сервере уже после отправки ответа
+	require.Eventually(t, func() bool { return len(recorder.Ended()) >= 2 }, time.Second, time.Millisecond*10)
+
+	names := make([]string, 0)
+	for _, span := range recorder.Ended() {
+		require.Equal(t, published, span.SpanContext().TraceID())
+		names = append(names, span.Name())
+	}
+	require.Contains(t, names, "rotator.v1.Rotator/ChooseBanner")
+	require.Contains(t, names, "App.ChooseBanner")
+}
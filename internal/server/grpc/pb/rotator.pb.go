@@ -0,0 +1,2787 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: rotator.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Catalog int32
+
+const (
+	Catalog_CATALOG_UNSPECIFIED  Catalog = 0
+	Catalog_CATALOG_BANNER       Catalog = 1
+	Catalog_CATALOG_SLOT         Catalog = 2
+	Catalog_CATALOG_SOCIAL_GROUP Catalog = 3
+)
+
+// Enum value maps for Catalog.
+var (
+	Catalog_name = map[int32]string{
+		0: "CATALOG_UNSPECIFIED",
+		1: "CATALOG_BANNER",
+		2: "CATALOG_SLOT",
+		3: "CATALOG_SOCIAL_GROUP",
+	}
+	Catalog_value = map[string]int32{
+		"CATALOG_UNSPECIFIED":  0,
+		"CATALOG_BANNER":       1,
+		"CATALOG_SLOT":         2,
+		"CATALOG_SOCIAL_GROUP": 3,
+	}
+)
+
+func (x Catalog) Enum() *Catalog {
+	p := new(Catalog)
+	*p = x
+	return p
+}
+
+func (x Catalog) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Catalog) Descriptor() protoreflect.EnumDescriptor {
+	return file_rotator_proto_enumTypes[0].Descriptor()
+}
+
+func (Catalog) Type() protoreflect.EnumType {
+	return &file_rotator_proto_enumTypes[0]
+}
+
+func (x Catalog) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Catalog.Descriptor instead.
+func (Catalog) EnumDescriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{0}
+}
+
+type BannerToSlotRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	BannerId int64                  `protobuf:"varint,1,opt,name=banner_id,json=bannerId,proto3" json:"banner_id,omitempty"`
+	SlotId   int64                  `protobuf:"varint,2,opt,name=slot_id,json=slotId,proto3" json:"slot_id,omitempty"`
+	// только для AddBannerToSlot, без него у баннера только псевдосчетчики слота
+	WarmStart *WarmStart `protobuf:"bytes,3,opt,name=warm_start,json=warmStart,proto3" json:"warm_start,omitempty"`
+	// только для AddBannerToSlot, без него баннер участвует в выборе всегда
+	Schedule      *Schedule `protobuf:"bytes,4,opt,name=schedule,proto3" json:"schedule,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BannerToSlotRequest) Reset() {
+	*x = BannerToSlotRequest{}
+	mi := &file_rotator_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BannerToSlotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BannerToSlotRequest) ProtoMessage() {}
+
+func (x *BannerToSlotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rotator_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BannerToSlotRequest.ProtoReflect.Descriptor instead.
+func (*BannerToSlotRequest) Descriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *BannerToSlotRequest) GetBannerId() int64 {
+	if x != nil {
+		return x.BannerId
+	}
+	return 0
+}
+
+func (x *BannerToSlotRequest) GetSlotId() int64 {
+	if x != nil {
+		return x.SlotId
+	}
+	return 0
+}
+
+func (x *BannerToSlotRequest) GetWarmStart() *WarmStart {
+	if x != nil {
+		return x.WarmStart
+	}
+	return nil
+}
+
+func (x *BannerToSlotRequest) GetSchedule() *Schedule {
+	if x != nil {
+		return x.Schedule
+	}
+	return nil
+}
+
+type WarmStart struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// banner - CTR похожего баннера banner_id по всем его слотам, slot - CTR среднего баннера слота
+	Source   string `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	BannerId int64  `protobuf:"varint,2,opt,name=banner_id,json=bannerId,proto3" json:"banner_id,omitempty"`
+	// сколько показов заимствовать, 0 - 100
+	Displays      int64 `protobuf:"varint,3,opt,name=displays,proto3" json:"displays,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WarmStart) Reset() {
+	*x = WarmStart{}
+	mi := &file_rotator_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WarmStart) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WarmStart) ProtoMessage() {}
+
+func (x *WarmStart) ProtoReflect() protoreflect.Message {
+	mi := &file_rotator_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WarmStart.ProtoReflect.Descriptor instead.
+func (*WarmStart) Descriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *WarmStart) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *WarmStart) GetBannerId() int64 {
+	if x != nil {
+		return x.BannerId
+	}
+	return 0
+}
+
+func (x *WarmStart) GetDisplays() int64 {
+	if x != nil {
+		return x.Displays
+	}
+	return 0
+}
+
+type Schedule struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// окно [from, to), без границы - не ограничено
+	From *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To   *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	// дни недели от 1 (понедельник) до 7 и часы от 0 до 23 в часовом поясе сервиса, пусто - любые
+	Weekdays      []int32 `protobuf:"varint,3,rep,packed,name=weekdays,proto3" json:"weekdays,omitempty"`
+	Hours         []int32 `protobuf:"varint,4,rep,packed,name=hours,proto3" json:"hours,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Schedule) Reset() {
+	*x = Schedule{}
+	mi := &file_rotator_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Schedule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Schedule) ProtoMessage() {}
+
+func (x *Schedule) ProtoReflect() protoreflect.Message {
+	mi := &file_rotator_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Schedule.ProtoReflect.Descriptor instead.
+func (*Schedule) Descriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Schedule) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *Schedule) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *Schedule) GetWeekdays() []int32 {
+	if x != nil {
+		return x.Weekdays
+	}
+	return nil
+}
+
+func (x *Schedule) GetHours() []int32 {
+	if x != nil {
+		return x.Hours
+	}
+	return nil
+}
+
+type BannerSchedule struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BannerId      int64                  `protobuf:"varint,1,opt,name=banner_id,json=bannerId,proto3" json:"banner_id,omitempty"`
+	SlotId        int64                  `protobuf:"varint,2,opt,name=slot_id,json=slotId,proto3" json:"slot_id,omitempty"`
+	Schedule      *Schedule              `protobuf:"bytes,3,opt,name=schedule,proto3" json:"schedule,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BannerSchedule) Reset() {
+	*x = BannerSchedule{}
+	mi := &file_rotator_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BannerSchedule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BannerSchedule) ProtoMessage() {}
+
+func (x *BannerSchedule) ProtoReflect() protoreflect.Message {
+	mi := &file_rotator_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BannerSchedule.ProtoReflect.Descriptor instead.
+func (*BannerSchedule) Descriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BannerSchedule) GetBannerId() int64 {
+	if x != nil {
+		return x.BannerId
+	}
+	return 0
+}
+
+func (x *BannerSchedule) GetSlotId() int64 {
+	if x != nil {
+		return x.SlotId
+	}
+	return 0
+}
+
+func (x *BannerSchedule) GetSchedule() *Schedule {
+	if x != nil {
+		return x.Schedule
+	}
+	return nil
+}
+
+type BannerStatus struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	BannerId int64                  `protobuf:"varint,1,opt,name=banner_id,json=bannerId,proto3" json:"banner_id,omitempty"`
+	SlotId   int64                  `protobuf:"varint,2,opt,name=slot_id,json=slotId,proto3" json:"slot_id,omitempty"`
+	// active - участвует в выборе, paused - временно снят, archived - снят окончательно
+	Status        string `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BannerStatus) Reset() {
+	*x = BannerStatus{}
+	mi := &file_rotator_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BannerStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BannerStatus) ProtoMessage() {}
+
+func (x *BannerStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_rotator_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BannerStatus.ProtoReflect.Descriptor instead.
+func (*BannerStatus) Descriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BannerStatus) GetBannerId() int64 {
+	if x != nil {
+		return x.BannerId
+	}
+	return 0
+}
+
+func (x *BannerStatus) GetSlotId() int64 {
+	if x != nil {
+		return x.SlotId
+	}
+	return 0
+}
+
+func (x *BannerStatus) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type CountTransitionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BannerId      int64                  `protobuf:"varint,1,opt,name=banner_id,json=bannerId,proto3" json:"banner_id,omitempty"`
+	SlotId        int64                  `protobuf:"varint,2,opt,name=slot_id,json=slotId,proto3" json:"slot_id,omitempty"`
+	SocialGroupId int64                  `protobuf:"varint,3,opt,name=social_group_id,json=socialGroupId,proto3" json:"social_group_id,omitempty"`
+	ImpressionId  string                 `protobuf:"bytes,4,opt,name=impression_id,json=impressionId,proto3" json:"impression_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CountTransitionRequest) Reset() {
+	*x = CountTransitionRequest{}
+	mi := &file_rotator_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CountTransitionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CountTransitionRequest) ProtoMessage() {}
+
+func (x *CountTransitionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rotator_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CountTransitionRequest.ProtoReflect.Descriptor instead.
+func (*CountTransitionRequest) Descriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CountTransitionRequest) GetBannerId() int64 {
+	if x != nil {
+		return x.BannerId
+	}
+	return 0
+}
+
+func (x *CountTransitionRequest) GetSlotId() int64 {
+	if x != nil {
+		return x.SlotId
+	}
+	return 0
+}
+
+func (x *CountTransitionRequest) GetSocialGroupId() int64 {
+	if x != nil {
+		return x.SocialGroupId
+	}
+	return 0
+}
+
+func (x *CountTransitionRequest) GetImpressionId() string {
+	if x != nil {
+		return x.ImpressionId
+	}
+	return ""
+}
+
+type ChooseBannerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SlotId        int64                  `protobuf:"varint,1,opt,name=slot_id,json=slotId,proto3" json:"slot_id,omitempty"`
+	SocialGroupId int64                  `protobuf:"varint,2,opt,name=social_group_id,json=socialGroupId,proto3" json:"social_group_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChooseBannerRequest) Reset() {
+	*x = ChooseBannerRequest{}
+	mi := &file_rotator_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChooseBannerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChooseBannerRequest) ProtoMessage() {}
+
+func (x *ChooseBannerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rotator_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChooseBannerRequest.ProtoReflect.Descriptor instead.
+func (*ChooseBannerRequest) Descriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ChooseBannerRequest) GetSlotId() int64 {
+	if x != nil {
+		return x.SlotId
+	}
+	return 0
+}
+
+func (x *ChooseBannerRequest) GetSocialGroupId() int64 {
+	if x != nil {
+		return x.SocialGroupId
+	}
+	return 0
+}
+
+type ChooseBannerResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BannerId      int64                  `protobuf:"varint,1,opt,name=banner_id,json=bannerId,proto3" json:"banner_id,omitempty"`
+	ImpressionId  string                 `protobuf:"bytes,2,opt,name=impression_id,json=impressionId,proto3" json:"impression_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChooseBannerResponse) Reset() {
+	*x = ChooseBannerResponse{}
+	mi := &file_rotator_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChooseBannerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChooseBannerResponse) ProtoMessage() {}
+
+func (x *ChooseBannerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rotator_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChooseBannerResponse.ProtoReflect.Descriptor instead.
+func (*ChooseBannerResponse) Descriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ChooseBannerResponse) GetBannerId() int64 {
+	if x != nil {
+		return x.BannerId
+	}
+	return 0
+}
+
+func (x *ChooseBannerResponse) GetImpressionId() string {
+	if x != nil {
+		return x.ImpressionId
+	}
+	return ""
+}
+
+type BannerEstimate struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	BannerId int64                  `protobuf:"varint,1,opt,name=banner_id,json=bannerId,proto3" json:"banner_id,omitempty"`
+	Display  int64                  `protobuf:"varint,2,opt,name=display,proto3" json:"display,omitempty"`
+	Click    int64                  `protobuf:"varint,3,opt,name=click,proto3" json:"click,omitempty"`
+	// наблюдаемый CTR: click / display, 0 - показов не было
+	Ctr float64 `protobuf:"fixed64,4,opt,name=ctr,proto3" json:"ctr,omitempty"`
+	// бонус за исследование и итоговая оценка mean + exploration_bonus, у баннера без показов бесконечны
+	ExplorationBonus float64 `protobuf:"fixed64,5,opt,name=exploration_bonus,json=explorationBonus,proto3" json:"exploration_bonus,omitempty"`
+	Score            float64 `protobuf:"fixed64,6,opt,name=score,proto3" json:"score,omitempty"`
+	Unexplored       bool    `protobuf:"varint,7,opt,name=unexplored,proto3" json:"unexplored,omitempty"`
+	// псевдосчетчики слота и теплого старта баннера, входят в mean
+	PriorAlpha float64 `protobuf:"fixed64,8,opt,name=prior_alpha,json=priorAlpha,proto3" json:"prior_alpha,omitempty"`
+	PriorBeta  float64 `protobuf:"fixed64,9,opt,name=prior_beta,json=priorBeta,proto3" json:"prior_beta,omitempty"`
+	// CTR с псевдосчетчиками: (click + prior_alpha) / (display + prior_alpha + prior_beta)
+	Mean          float64 `protobuf:"fixed64,10,opt,name=mean,proto3" json:"mean,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BannerEstimate) Reset() {
+	*x = BannerEstimate{}
+	mi := &file_rotator_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BannerEstimate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BannerEstimate) ProtoMessage() {}
+
+func (x *BannerEstimate) ProtoReflect() protoreflect.Message {
+	mi := &file_rotator_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BannerEstimate.ProtoReflect.Descriptor instead.
+func (*BannerEstimate) Descriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *BannerEstimate) GetBannerId() int64 {
+	if x != nil {
+		return x.BannerId
+	}
+	return 0
+}
+
+func (x *BannerEstimate) GetDisplay() int64 {
+	if x != nil {
+		return x.Display
+	}
+	return 0
+}
+
+func (x *BannerEstimate) GetClick() int64 {
+	if x != nil {
+		return x.Click
+	}
+	return 0
+}
+
+func (x *BannerEstimate) GetCtr() float64 {
+	if x != nil {
+		return x.Ctr
+	}
+	return 0
+}
+
+func (x *BannerEstimate) GetExplorationBonus() float64 {
+	if x != nil {
+		return x.ExplorationBonus
+	}
+	return 0
+}
+
+func (x *BannerEstimate) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *BannerEstimate) GetUnexplored() bool {
+	if x != nil {
+		return x.Unexplored
+	}
+	return false
+}
+
+func (x *BannerEstimate) GetPriorAlpha() float64 {
+	if x != nil {
+		return x.PriorAlpha
+	}
+	return 0
+}
+
+func (x *BannerEstimate) GetPriorBeta() float64 {
+	if x != nil {
+		return x.PriorBeta
+	}
+	return 0
+}
+
+func (x *BannerEstimate) GetMean() float64 {
+	if x != nil {
+		return x.Mean
+	}
+	return 0
+}
+
+type ExplainBannerResponse struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Strategy     string                 `protobuf:"bytes,1,opt,name=strategy,proto3" json:"strategy,omitempty"`
+	TotalDisplay int64                  `protobuf:"varint,2,opt,name=total_display,json=totalDisplay,proto3" json:"total_display,omitempty"`
+	// баннер, который был бы выбран
+	BannerId int64 `protobuf:"varint,3,opt,name=banner_id,json=bannerId,proto3" json:"banner_id,omitempty"`
+	// по убыванию оценки, победитель первый
+	Candidates    []*BannerEstimate `protobuf:"bytes,4,rep,name=candidates,proto3" json:"candidates,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExplainBannerResponse) Reset() {
+	*x = ExplainBannerResponse{}
+	mi := &file_rotator_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExplainBannerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExplainBannerResponse) ProtoMessage() {}
+
+func (x *ExplainBannerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rotator_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExplainBannerResponse.ProtoReflect.Descriptor instead.
+func (*ExplainBannerResponse) Descriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ExplainBannerResponse) GetStrategy() string {
+	if x != nil {
+		return x.Strategy
+	}
+	return ""
+}
+
+func (x *ExplainBannerResponse) GetTotalDisplay() int64 {
+	if x != nil {
+		return x.TotalDisplay
+	}
+	return 0
+}
+
+func (x *ExplainBannerResponse) GetBannerId() int64 {
+	if x != nil {
+		return x.BannerId
+	}
+	return 0
+}
+
+func (x *ExplainBannerResponse) GetCandidates() []*BannerEstimate {
+	if x != nil {
+		return x.Candidates
+	}
+	return nil
+}
+
+type StreamRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	RequestId string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	// Types that are valid to be assigned to Request:
+	//
+	//	*StreamRequest_Choose
+	//	*StreamRequest_Click
+	Request       isStreamRequest_Request `protobuf_oneof:"request"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamRequest) Reset() {
+	*x = StreamRequest{}
+	mi := &file_rotator_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamRequest) ProtoMessage() {}
+
+func (x *StreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rotator_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamRequest.ProtoReflect.Descriptor instead.
+func (*StreamRequest) Descriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *StreamRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *StreamRequest) GetRequest() isStreamRequest_Request {
+	if x != nil {
+		return x.Request
+	}
+	return nil
+}
+
+func (x *StreamRequest) GetChoose() *ChooseBannerRequest {
+	if x != nil {
+		if x, ok := x.Request.(*StreamRequest_Choose); ok {
+			return x.Choose
+		}
+	}
+	return nil
+}
+
+func (x *StreamRequest) GetClick() *CountTransitionRequest {
+	if x != nil {
+		if x, ok := x.Request.(*StreamRequest_Click); ok {
+			return x.Click
+		}
+	}
+	return nil
+}
+
+type isStreamRequest_Request interface {
+	isStreamRequest_Request()
+}
+
+type StreamRequest_Choose struct {
+	Choose *ChooseBannerRequest `protobuf:"bytes,2,opt,name=choose,proto3,oneof"`
+}
+
+type StreamRequest_Click struct {
+	Click *CountTransitionRequest `protobuf:"bytes,3,opt,name=click,proto3,oneof"`
+}
+
+func (*StreamRequest_Choose) isStreamRequest_Request() {}
+
+func (*StreamRequest_Click) isStreamRequest_Request() {}
+
+type StreamError struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// код из google.golang.org/grpc/codes
+	Code    int32  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// стабильный код ошибки приложения, например slot_not_found
+	Reason string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	// для rate_limited - через сколько повторить запрос
+	RetryDelay    *durationpb.Duration `protobuf:"bytes,4,opt,name=retry_delay,json=retryDelay,proto3" json:"retry_delay,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamError) Reset() {
+	*x = StreamError{}
+	mi := &file_rotator_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamError) ProtoMessage() {}
+
+func (x *StreamError) ProtoReflect() protoreflect.Message {
+	mi := &file_rotator_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamError.ProtoReflect.Descriptor instead.
+func (*StreamError) Descriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *StreamError) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *StreamError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *StreamError) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *StreamError) GetRetryDelay() *durationpb.Duration {
+	if x != nil {
+		return x.RetryDelay
+	}
+	return nil
+}
+
+type StreamResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	RequestId string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	// Types that are valid to be assigned to Response:
+	//
+	//	*StreamResponse_Choose
+	//	*StreamResponse_Click
+	//	*StreamResponse_Error
+	Response      isStreamResponse_Response `protobuf_oneof:"response"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamResponse) Reset() {
+	*x = StreamResponse{}
+	mi := &file_rotator_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamResponse) ProtoMessage() {}
+
+func (x *StreamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rotator_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamResponse.ProtoReflect.Descriptor instead.
+func (*StreamResponse) Descriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *StreamResponse) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *StreamResponse) GetResponse() isStreamResponse_Response {
+	if x != nil {
+		return x.Response
+	}
+	return nil
+}
+
+func (x *StreamResponse) GetChoose() *ChooseBannerResponse {
+	if x != nil {
+		if x, ok := x.Response.(*StreamResponse_Choose); ok {
+			return x.Choose
+		}
+	}
+	return nil
+}
+
+func (x *StreamResponse) GetClick() *emptypb.Empty {
+	if x != nil {
+		if x, ok := x.Response.(*StreamResponse_Click); ok {
+			return x.Click
+		}
+	}
+	return nil
+}
+
+func (x *StreamResponse) GetError() *StreamError {
+	if x != nil {
+		if x, ok := x.Response.(*StreamResponse_Error); ok {
+			return x.Error
+		}
+	}
+	return nil
+}
+
+type isStreamResponse_Response interface {
+	isStreamResponse_Response()
+}
+
+type StreamResponse_Choose struct {
+	Choose *ChooseBannerResponse `protobuf:"bytes,2,opt,name=choose,proto3,oneof"`
+}
+
+type StreamResponse_Click struct {
+	Click *emptypb.Empty `protobuf:"bytes,3,opt,name=click,proto3,oneof"`
+}
+
+type StreamResponse_Error struct {
+	Error *StreamError `protobuf:"bytes,4,opt,name=error,proto3,oneof"`
+}
+
+func (*StreamResponse_Choose) isStreamResponse_Response() {}
+
+func (*StreamResponse_Click) isStreamResponse_Response() {}
+
+func (*StreamResponse_Error) isStreamResponse_Response() {}
+
+type Event struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// click или display
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	BannerId      int64                  `protobuf:"varint,2,opt,name=banner_id,json=bannerId,proto3" json:"banner_id,omitempty"`
+	SlotId        int64                  `protobuf:"varint,3,opt,name=slot_id,json=slotId,proto3" json:"slot_id,omitempty"`
+	SocialGroupId int64                  `protobuf:"varint,4,opt,name=social_group_id,json=socialGroupId,proto3" json:"social_group_id,omitempty"`
+	ImpressionId  string                 `protobuf:"bytes,5,opt,name=impression_id,json=impressionId,proto3" json:"impression_id,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_rotator_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_rotator_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Event) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Event) GetBannerId() int64 {
+	if x != nil {
+		return x.BannerId
+	}
+	return 0
+}
+
+func (x *Event) GetSlotId() int64 {
+	if x != nil {
+		return x.SlotId
+	}
+	return 0
+}
+
+func (x *Event) GetSocialGroupId() int64 {
+	if x != nil {
+		return x.SocialGroupId
+	}
+	return 0
+}
+
+func (x *Event) GetImpressionId() string {
+	if x != nil {
+		return x.ImpressionId
+	}
+	return ""
+}
+
+func (x *Event) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+type ApplyEventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Events        []*Event               `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApplyEventsRequest) Reset() {
+	*x = ApplyEventsRequest{}
+	mi := &file_rotator_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApplyEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplyEventsRequest) ProtoMessage() {}
+
+func (x *ApplyEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rotator_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApplyEventsRequest.ProtoReflect.Descriptor instead.
+func (*ApplyEventsRequest) Descriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ApplyEventsRequest) GetEvents() []*Event {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+type EventResult struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Index   int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Success bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+	Error   string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	// стабильный код ошибки приложения
+	Code          string `protobuf:"bytes,4,opt,name=code,proto3" json:"code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EventResult) Reset() {
+	*x = EventResult{}
+	mi := &file_rotator_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EventResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EventResult) ProtoMessage() {}
+
+func (x *EventResult) ProtoReflect() protoreflect.Message {
+	mi := &file_rotator_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EventResult.ProtoReflect.Descriptor instead.
+func (*EventResult) Descriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *EventResult) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *EventResult) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *EventResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *EventResult) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+type ApplyEventsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*EventResult         `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApplyEventsResponse) Reset() {
+	*x = ApplyEventsResponse{}
+	mi := &file_rotator_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApplyEventsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplyEventsResponse) ProtoMessage() {}
+
+func (x *ApplyEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rotator_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApplyEventsResponse.ProtoReflect.Descriptor instead.
+func (*ApplyEventsResponse) Descriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ApplyEventsResponse) GetResults() []*EventResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type GetStatsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// slot, banner, social_group; пусто - итог одной строкой
+	GroupBy []string `protobuf:"bytes,1,rep,name=group_by,json=groupBy,proto3" json:"group_by,omitempty"`
+	// фильтры, 0 - без фильтра
+	SlotId        int64 `protobuf:"varint,2,opt,name=slot_id,json=slotId,proto3" json:"slot_id,omitempty"`
+	BannerId      int64 `protobuf:"varint,3,opt,name=banner_id,json=bannerId,proto3" json:"banner_id,omitempty"`
+	SocialGroupId int64 `protobuf:"varint,4,opt,name=social_group_id,json=socialGroupId,proto3" json:"social_group_id,omitempty"`
+	// уровень доверия интервала CTR, 0 - 0.95
+	Confidence    float64 `protobuf:"fixed64,5,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatsRequest) Reset() {
+	*x = GetStatsRequest{}
+	mi := &file_rotator_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsRequest) ProtoMessage() {}
+
+func (x *GetStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rotator_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetStatsRequest) Descriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *GetStatsRequest) GetGroupBy() []string {
+	if x != nil {
+		return x.GroupBy
+	}
+	return nil
+}
+
+func (x *GetStatsRequest) GetSlotId() int64 {
+	if x != nil {
+		return x.SlotId
+	}
+	return 0
+}
+
+func (x *GetStatsRequest) GetBannerId() int64 {
+	if x != nil {
+		return x.BannerId
+	}
+	return 0
+}
+
+func (x *GetStatsRequest) GetSocialGroupId() int64 {
+	if x != nil {
+		return x.SocialGroupId
+	}
+	return 0
+}
+
+func (x *GetStatsRequest) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+type StatsRow struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 0, если по измерению не группировали
+	SlotId        int64   `protobuf:"varint,1,opt,name=slot_id,json=slotId,proto3" json:"slot_id,omitempty"`
+	BannerId      int64   `protobuf:"varint,2,opt,name=banner_id,json=bannerId,proto3" json:"banner_id,omitempty"`
+	SocialGroupId int64   `protobuf:"varint,3,opt,name=social_group_id,json=socialGroupId,proto3" json:"social_group_id,omitempty"`
+	Display       int64   `protobuf:"varint,4,opt,name=display,proto3" json:"display,omitempty"`
+	Click         int64   `protobuf:"varint,5,opt,name=click,proto3" json:"click,omitempty"`
+	Ctr           float64 `protobuf:"fixed64,6,opt,name=ctr,proto3" json:"ctr,omitempty"`
+	// интервал Уилсона
+	CtrLower      float64 `protobuf:"fixed64,7,opt,name=ctr_lower,json=ctrLower,proto3" json:"ctr_lower,omitempty"`
+	CtrUpper      float64 `protobuf:"fixed64,8,opt,name=ctr_upper,json=ctrUpper,proto3" json:"ctr_upper,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatsRow) Reset() {
+	*x = StatsRow{}
+	mi := &file_rotator_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatsRow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatsRow) ProtoMessage() {}
+
+func (x *StatsRow) ProtoReflect() protoreflect.Message {
+	mi := &file_rotator_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatsRow.ProtoReflect.Descriptor instead.
+func (*StatsRow) Descriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *StatsRow) GetSlotId() int64 {
+	if x != nil {
+		return x.SlotId
+	}
+	return 0
+}
+
+func (x *StatsRow) GetBannerId() int64 {
+	if x != nil {
+		return x.BannerId
+	}
+	return 0
+}
+
+func (x *StatsRow) GetSocialGroupId() int64 {
+	if x != nil {
+		return x.SocialGroupId
+	}
+	return 0
+}
+
+func (x *StatsRow) GetDisplay() int64 {
+	if x != nil {
+		return x.Display
+	}
+	return 0
+}
+
+func (x *StatsRow) GetClick() int64 {
+	if x != nil {
+		return x.Click
+	}
+	return 0
+}
+
+func (x *StatsRow) GetCtr() float64 {
+	if x != nil {
+		return x.Ctr
+	}
+	return 0
+}
+
+func (x *StatsRow) GetCtrLower() float64 {
+	if x != nil {
+		return x.CtrLower
+	}
+	return 0
+}
+
+func (x *StatsRow) GetCtrUpper() float64 {
+	if x != nil {
+		return x.CtrUpper
+	}
+	return 0
+}
+
+type GetStatsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GroupBy       []string               `protobuf:"bytes,1,rep,name=group_by,json=groupBy,proto3" json:"group_by,omitempty"`
+	Confidence    float64                `protobuf:"fixed64,2,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	Rows          []*StatsRow            `protobuf:"bytes,3,rep,name=rows,proto3" json:"rows,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatsResponse) Reset() {
+	*x = GetStatsResponse{}
+	mi := &file_rotator_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsResponse) ProtoMessage() {}
+
+func (x *GetStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rotator_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetStatsResponse) Descriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *GetStatsResponse) GetGroupBy() []string {
+	if x != nil {
+		return x.GroupBy
+	}
+	return nil
+}
+
+func (x *GetStatsResponse) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetRows() []*StatsRow {
+	if x != nil {
+		return x.Rows
+	}
+	return nil
+}
+
+type GetStatsSeriesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Stats *GetStatsRequest       `protobuf:"bytes,1,opt,name=stats,proto3" json:"stats,omitempty"`
+	// hour (по умолчанию) или day
+	Interval string `protobuf:"bytes,2,opt,name=interval,proto3" json:"interval,omitempty"`
+	// по умолчанию to - сейчас, from - 24 периода назад
+	From          *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=from,proto3" json:"from,omitempty"`
+	To            *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatsSeriesRequest) Reset() {
+	*x = GetStatsSeriesRequest{}
+	mi := &file_rotator_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsSeriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsSeriesRequest) ProtoMessage() {}
+
+func (x *GetStatsSeriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rotator_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsSeriesRequest.ProtoReflect.Descriptor instead.
+func (*GetStatsSeriesRequest) Descriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *GetStatsSeriesRequest) GetStats() *GetStatsRequest {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+func (x *GetStatsSeriesRequest) GetInterval() string {
+	if x != nil {
+		return x.Interval
+	}
+	return ""
+}
+
+func (x *GetStatsSeriesRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *GetStatsSeriesRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+type StatsPoint struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// начало периода
+	Time          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	Stats         *StatsRow              `protobuf:"bytes,2,opt,name=stats,proto3" json:"stats,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatsPoint) Reset() {
+	*x = StatsPoint{}
+	mi := &file_rotator_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatsPoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatsPoint) ProtoMessage() {}
+
+func (x *StatsPoint) ProtoReflect() protoreflect.Message {
+	mi := &file_rotator_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatsPoint.ProtoReflect.Descriptor instead.
+func (*StatsPoint) Descriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *StatsPoint) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *StatsPoint) GetStats() *StatsRow {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+type GetStatsSeriesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GroupBy       []string               `protobuf:"bytes,1,rep,name=group_by,json=groupBy,proto3" json:"group_by,omitempty"`
+	Confidence    float64                `protobuf:"fixed64,2,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	Interval      string                 `protobuf:"bytes,3,opt,name=interval,proto3" json:"interval,omitempty"`
+	From          *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=from,proto3" json:"from,omitempty"`
+	To            *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=to,proto3" json:"to,omitempty"`
+	Points        []*StatsPoint          `protobuf:"bytes,6,rep,name=points,proto3" json:"points,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatsSeriesResponse) Reset() {
+	*x = GetStatsSeriesResponse{}
+	mi := &file_rotator_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsSeriesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsSeriesResponse) ProtoMessage() {}
+
+func (x *GetStatsSeriesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rotator_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsSeriesResponse.ProtoReflect.Descriptor instead.
+func (*GetStatsSeriesResponse) Descriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *GetStatsSeriesResponse) GetGroupBy() []string {
+	if x != nil {
+		return x.GroupBy
+	}
+	return nil
+}
+
+func (x *GetStatsSeriesResponse) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+func (x *GetStatsSeriesResponse) GetInterval() string {
+	if x != nil {
+		return x.Interval
+	}
+	return ""
+}
+
+func (x *GetStatsSeriesResponse) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *GetStatsSeriesResponse) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *GetStatsSeriesResponse) GetPoints() []*StatsPoint {
+	if x != nil {
+		return x.Points
+	}
+	return nil
+}
+
+type ImportRow struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SlotId        int64                  `protobuf:"varint,1,opt,name=slot_id,json=slotId,proto3" json:"slot_id,omitempty"`
+	BannerId      int64                  `protobuf:"varint,2,opt,name=banner_id,json=bannerId,proto3" json:"banner_id,omitempty"`
+	SocialGroupId int64                  `protobuf:"varint,3,opt,name=social_group_id,json=socialGroupId,proto3" json:"social_group_id,omitempty"`
+	Display       int64                  `protobuf:"varint,4,opt,name=display,proto3" json:"display,omitempty"`
+	Click         int64                  `protobuf:"varint,5,opt,name=click,proto3" json:"click,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportRow) Reset() {
+	*x = ImportRow{}
+	mi := &file_rotator_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportRow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportRow) ProtoMessage() {}
+
+func (x *ImportRow) ProtoReflect() protoreflect.Message {
+	mi := &file_rotator_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportRow.ProtoReflect.Descriptor instead.
+func (*ImportRow) Descriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ImportRow) GetSlotId() int64 {
+	if x != nil {
+		return x.SlotId
+	}
+	return 0
+}
+
+func (x *ImportRow) GetBannerId() int64 {
+	if x != nil {
+		return x.BannerId
+	}
+	return 0
+}
+
+func (x *ImportRow) GetSocialGroupId() int64 {
+	if x != nil {
+		return x.SocialGroupId
+	}
+	return 0
+}
+
+func (x *ImportRow) GetDisplay() int64 {
+	if x != nil {
+		return x.Display
+	}
+	return 0
+}
+
+func (x *ImportRow) GetClick() int64 {
+	if x != nil {
+		return x.Click
+	}
+	return 0
+}
+
+type ImportStatsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// replace (по умолчанию) - заменить накопленные счетчики, add - прибавить к ним
+	Mode          string       `protobuf:"bytes,1,opt,name=mode,proto3" json:"mode,omitempty"`
+	Rows          []*ImportRow `protobuf:"bytes,2,rep,name=rows,proto3" json:"rows,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportStatsRequest) Reset() {
+	*x = ImportStatsRequest{}
+	mi := &file_rotator_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportStatsRequest) ProtoMessage() {}
+
+func (x *ImportStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rotator_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportStatsRequest.ProtoReflect.Descriptor instead.
+func (*ImportStatsRequest) Descriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ImportStatsRequest) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *ImportStatsRequest) GetRows() []*ImportRow {
+	if x != nil {
+		return x.Rows
+	}
+	return nil
+}
+
+type ImportStatsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*EventResult         `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportStatsResponse) Reset() {
+	*x = ImportStatsResponse{}
+	mi := &file_rotator_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportStatsResponse) ProtoMessage() {}
+
+func (x *ImportStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rotator_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportStatsResponse.ProtoReflect.Descriptor instead.
+func (*ImportStatsResponse) Descriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ImportStatsResponse) GetResults() []*EventResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type CatalogItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CatalogItem) Reset() {
+	*x = CatalogItem{}
+	mi := &file_rotator_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CatalogItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CatalogItem) ProtoMessage() {}
+
+func (x *CatalogItem) ProtoReflect() protoreflect.Message {
+	mi := &file_rotator_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CatalogItem.ProtoReflect.Descriptor instead.
+func (*CatalogItem) Descriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *CatalogItem) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *CatalogItem) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+type CreateCatalogItemRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Catalog       Catalog                `protobuf:"varint,1,opt,name=catalog,proto3,enum=rotator.v1.Catalog" json:"catalog,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateCatalogItemRequest) Reset() {
+	*x = CreateCatalogItemRequest{}
+	mi := &file_rotator_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateCatalogItemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateCatalogItemRequest) ProtoMessage() {}
+
+func (x *CreateCatalogItemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rotator_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateCatalogItemRequest.ProtoReflect.Descriptor instead.
+func (*CreateCatalogItemRequest) Descriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *CreateCatalogItemRequest) GetCatalog() Catalog {
+	if x != nil {
+		return x.Catalog
+	}
+	return Catalog_CATALOG_UNSPECIFIED
+}
+
+func (x *CreateCatalogItemRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+type CatalogItemRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Catalog       Catalog                `protobuf:"varint,1,opt,name=catalog,proto3,enum=rotator.v1.Catalog" json:"catalog,omitempty"`
+	Id            int64                  `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CatalogItemRequest) Reset() {
+	*x = CatalogItemRequest{}
+	mi := &file_rotator_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CatalogItemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CatalogItemRequest) ProtoMessage() {}
+
+func (x *CatalogItemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rotator_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CatalogItemRequest.ProtoReflect.Descriptor instead.
+func (*CatalogItemRequest) Descriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *CatalogItemRequest) GetCatalog() Catalog {
+	if x != nil {
+		return x.Catalog
+	}
+	return Catalog_CATALOG_UNSPECIFIED
+}
+
+func (x *CatalogItemRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ListCatalogItemsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Catalog       Catalog                `protobuf:"varint,1,opt,name=catalog,proto3,enum=rotator.v1.Catalog" json:"catalog,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCatalogItemsRequest) Reset() {
+	*x = ListCatalogItemsRequest{}
+	mi := &file_rotator_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCatalogItemsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCatalogItemsRequest) ProtoMessage() {}
+
+func (x *ListCatalogItemsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rotator_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCatalogItemsRequest.ProtoReflect.Descriptor instead.
+func (*ListCatalogItemsRequest) Descriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *ListCatalogItemsRequest) GetCatalog() Catalog {
+	if x != nil {
+		return x.Catalog
+	}
+	return Catalog_CATALOG_UNSPECIFIED
+}
+
+type ListCatalogItemsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*CatalogItem         `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCatalogItemsResponse) Reset() {
+	*x = ListCatalogItemsResponse{}
+	mi := &file_rotator_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCatalogItemsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCatalogItemsResponse) ProtoMessage() {}
+
+func (x *ListCatalogItemsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rotator_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCatalogItemsResponse.ProtoReflect.Descriptor instead.
+func (*ListCatalogItemsResponse) Descriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ListCatalogItemsResponse) GetItems() []*CatalogItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type UpdateCatalogItemRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Catalog       Catalog                `protobuf:"varint,1,opt,name=catalog,proto3,enum=rotator.v1.Catalog" json:"catalog,omitempty"`
+	Id            int64                  `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateCatalogItemRequest) Reset() {
+	*x = UpdateCatalogItemRequest{}
+	mi := &file_rotator_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateCatalogItemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateCatalogItemRequest) ProtoMessage() {}
+
+func (x *UpdateCatalogItemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rotator_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateCatalogItemRequest.ProtoReflect.Descriptor instead.
+func (*UpdateCatalogItemRequest) Descriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *UpdateCatalogItemRequest) GetCatalog() Catalog {
+	if x != nil {
+		return x.Catalog
+	}
+	return Catalog_CATALOG_UNSPECIFIED
+}
+
+func (x *UpdateCatalogItemRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateCatalogItemRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+type SlotPriorRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SlotId        int64                  `protobuf:"varint,1,opt,name=slot_id,json=slotId,proto3" json:"slot_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SlotPriorRequest) Reset() {
+	*x = SlotPriorRequest{}
+	mi := &file_rotator_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SlotPriorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SlotPriorRequest) ProtoMessage() {}
+
+func (x *SlotPriorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rotator_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SlotPriorRequest.ProtoReflect.Descriptor instead.
+func (*SlotPriorRequest) Descriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *SlotPriorRequest) GetSlotId() int64 {
+	if x != nil {
+		return x.SlotId
+	}
+	return 0
+}
+
+type Prior struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// псевдосчетчики кликов и показов без клика
+	Alpha         float64 `protobuf:"fixed64,1,opt,name=alpha,proto3" json:"alpha,omitempty"`
+	Beta          float64 `protobuf:"fixed64,2,opt,name=beta,proto3" json:"beta,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Prior) Reset() {
+	*x = Prior{}
+	mi := &file_rotator_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Prior) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Prior) ProtoMessage() {}
+
+func (x *Prior) ProtoReflect() protoreflect.Message {
+	mi := &file_rotator_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Prior.ProtoReflect.Descriptor instead.
+func (*Prior) Descriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *Prior) GetAlpha() float64 {
+	if x != nil {
+		return x.Alpha
+	}
+	return 0
+}
+
+func (x *Prior) GetBeta() float64 {
+	if x != nil {
+		return x.Beta
+	}
+	return 0
+}
+
+type SlotPrior struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	SlotId int64                  `protobuf:"varint,1,opt,name=slot_id,json=slotId,proto3" json:"slot_id,omitempty"`
+	Prior  *Prior                 `protobuf:"bytes,2,opt,name=prior,proto3" json:"prior,omitempty"`
+	// у слота нет своих псевдосчетчиков, действуют значения из конфигурации
+	Default       bool `protobuf:"varint,3,opt,name=default,proto3" json:"default,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SlotPrior) Reset() {
+	*x = SlotPrior{}
+	mi := &file_rotator_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SlotPrior) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SlotPrior) ProtoMessage() {}
+
+func (x *SlotPrior) ProtoReflect() protoreflect.Message {
+	mi := &file_rotator_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SlotPrior.ProtoReflect.Descriptor instead.
+func (*SlotPrior) Descriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *SlotPrior) GetSlotId() int64 {
+	if x != nil {
+		return x.SlotId
+	}
+	return 0
+}
+
+func (x *SlotPrior) GetPrior() *Prior {
+	if x != nil {
+		return x.Prior
+	}
+	return nil
+}
+
+func (x *SlotPrior) GetDefault() bool {
+	if x != nil {
+		return x.Default
+	}
+	return false
+}
+
+type SetSlotPriorRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SlotId        int64                  `protobuf:"varint,1,opt,name=slot_id,json=slotId,proto3" json:"slot_id,omitempty"`
+	Prior         *Prior                 `protobuf:"bytes,2,opt,name=prior,proto3" json:"prior,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetSlotPriorRequest) Reset() {
+	*x = SetSlotPriorRequest{}
+	mi := &file_rotator_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetSlotPriorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetSlotPriorRequest) ProtoMessage() {}
+
+func (x *SetSlotPriorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rotator_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetSlotPriorRequest.ProtoReflect.Descriptor instead.
+func (*SetSlotPriorRequest) Descriptor() ([]byte, []int) {
+	return file_rotator_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *SetSlotPriorRequest) GetSlotId() int64 {
+	if x != nil {
+		return x.SlotId
+	}
+	return 0
+}
+
+func (x *SetSlotPriorRequest) GetPrior() *Prior {
+	if x != nil {
+		return x.Prior
+	}
+	return nil
+}
+
+var File_rotator_proto protoreflect.FileDescriptor
+
+const file_rotator_proto_rawDesc = "" +
+	"\n" +
+	"\rrotator.proto\x12\n" +
+	"rotator.v1\x1a\x1egoogle/protobuf/duration.proto\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xb3\x01\n" +
+	"\x13BannerToSlotRequest\x12\x1b\n" +
+	"\tbanner_id\x18\x01 \x01(\x03R\bbannerId\x12\x17\n" +
+	"\aslot_id\x18\x02 \x01(\x03R\x06slotId\x124\n" +
+	"\n" +
+	"warm_start\x18\x03 \x01(\v2\x15.rotator.v1.WarmStartR\twarmStart\x120\n" +
+	"\bschedule\x18\x04 \x01(\v2\x14.rotator.v1.ScheduleR\bschedule\"\\\n" +
+	"\tWarmStart\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x1b\n" +
+	"\tbanner_id\x18\x02 \x01(\x03R\bbannerId\x12\x1a\n" +
+	"\bdisplays\x18\x03 \x01(\x03R\bdisplays\"\x98\x01\n" +
+	"\bSchedule\x12.\n" +
+	"\x04from\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x12\x1a\n" +
+	"\bweekdays\x18\x03 \x03(\x05R\bweekdays\x12\x14\n" +
+	"\x05hours\x18\x04 \x03(\x05R\x05hours\"x\n" +
+	"\x0eBannerSchedule\x12\x1b\n" +
+	"\tbanner_id\x18\x01 \x01(\x03R\bbannerId\x12\x17\n" +
+	"\aslot_id\x18\x02 \x01(\x03R\x06slotId\x120\n" +
+	"\bschedule\x18\x03 \x01(\v2\x14.rotator.v1.ScheduleR\bschedule\"\\\n" +
+	"\fBannerStatus\x12\x1b\n" +
+	"\tbanner_id\x18\x01 \x01(\x03R\bbannerId\x12\x17\n" +
+	"\aslot_id\x18\x02 \x01(\x03R\x06slotId\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\"\x9b\x01\n" +
+	"\x16CountTransitionRequest\x12\x1b\n" +
+	"\tbanner_id\x18\x01 \x01(\x03R\bbannerId\x12\x17\n" +
+	"\aslot_id\x18\x02 \x01(\x03R\x06slotId\x12&\n" +
+	"\x0fsocial_group_id\x18\x03 \x01(\x03R\rsocialGroupId\x12#\n" +
+	"\rimpression_id\x18\x04 \x01(\tR\fimpressionId\"V\n" +
+	"\x13ChooseBannerRequest\x12\x17\n" +
+	"\aslot_id\x18\x01 \x01(\x03R\x06slotId\x12&\n" +
+	"\x0fsocial_group_id\x18\x02 \x01(\x03R\rsocialGroupId\"X\n" +
+	"\x14ChooseBannerResponse\x12\x1b\n" +
+	"\tbanner_id\x18\x01 \x01(\x03R\bbannerId\x12#\n" +
+	"\rimpression_id\x18\x02 \x01(\tR\fimpressionId\"\xa6\x02\n" +
+	"\x0eBannerEstimate\x12\x1b\n" +
+	"\tbanner_id\x18\x01 \x01(\x03R\bbannerId\x12\x18\n" +
+	"\adisplay\x18\x02 \x01(\x03R\adisplay\x12\x14\n" +
+	"\x05click\x18\x03 \x01(\x03R\x05click\x12\x10\n" +
+	"\x03ctr\x18\x04 \x01(\x01R\x03ctr\x12+\n" +
+	"\x11exploration_bonus\x18\x05 \x01(\x01R\x10explorationBonus\x12\x14\n" +
+	"\x05score\x18\x06 \x01(\x01R\x05score\x12\x1e\n" +
+	"\n" +
+	"unexplored\x18\a \x01(\bR\n" +
+	"unexplored\x12\x1f\n" +
+	"\vprior_alpha\x18\b \x01(\x01R\n" +
+	"priorAlpha\x12\x1d\n" +
+	"\n" +
+	"prior_beta\x18\t \x01(\x01R\tpriorBeta\x12\x12\n" +
+	"\x04mean\x18\n" +
+	" \x01(\x01R\x04mean\"\xb1\x01\n" +
+	"\x15ExplainBannerResponse\x12\x1a\n" +
+	"\bstrategy\x18\x01 \x01(\tR\bstrategy\x12#\n" +
+	"\rtotal_display\x18\x02 \x01(\x03R\ftotalDisplay\x12\x1b\n" +
+	"\tbanner_id\x18\x03 \x01(\x03R\bbannerId\x12:\n" +
+	"\n" +
+	"candidates\x18\x04 \x03(\v2\x1a.rotator.v1.BannerEstimateR\n" +
+	"candidates\"\xb0\x01\n" +
+	"\rStreamRequest\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\x129\n" +
+	"\x06choose\x18\x02 \x01(\v2\x1f.rotator.v1.ChooseBannerRequestH\x00R\x06choose\x12:\n" +
+	"\x05click\x18\x03 \x01(\v2\".rotator.v1.CountTransitionRequestH\x00R\x05clickB\t\n" +
+	"\arequest\"\x8f\x01\n" +
+	"\vStreamError\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12:\n" +
+	"\vretry_delay\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\n" +
+	"retryDelay\"\xd8\x01\n" +
+	"\x0eStreamResponse\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\x12:\n" +
+	"\x06choose\x18\x02 \x01(\v2 .rotator.v1.ChooseBannerResponseH\x00R\x06choose\x12.\n" +
+	"\x05click\x18\x03 \x01(\v2\x16.google.protobuf.EmptyH\x00R\x05click\x12/\n" +
+	"\x05error\x18\x04 \x01(\v2\x17.rotator.v1.StreamErrorH\x00R\x05errorB\n" +
+	"\n" +
+	"\bresponse\"\xd8\x01\n" +
+	"\x05Event\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x1b\n" +
+	"\tbanner_id\x18\x02 \x01(\x03R\bbannerId\x12\x17\n" +
+	"\aslot_id\x18\x03 \x01(\x03R\x06slotId\x12&\n" +
+	"\x0fsocial_group_id\x18\x04 \x01(\x03R\rsocialGroupId\x12#\n" +
+	"\rimpression_id\x18\x05 \x01(\tR\fimpressionId\x128\n" +
+	"\ttimestamp\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"?\n" +
+	"\x12ApplyEventsRequest\x12)\n" +
+	"\x06events\x18\x01 \x03(\v2\x11.rotator.v1.EventR\x06events\"g\n" +
+	"\vEventResult\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12\x18\n" +
+	"\asuccess\x18\x02 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x12\x12\n" +
+	"\x04code\x18\x04 \x01(\tR\x04code\"H\n" +
+	"\x13ApplyEventsResponse\x121\n" +
+	"\aresults\x18\x01 \x03(\v2\x17.rotator.v1.EventResultR\aresults\"\xaa\x01\n" +
+	"\x0fGetStatsRequest\x12\x19\n" +
+	"\bgroup_by\x18\x01 \x03(\tR\agroupBy\x12\x17\n" +
+	"\aslot_id\x18\x02 \x01(\x03R\x06slotId\x12\x1b\n" +
+	"\tbanner_id\x18\x03 \x01(\x03R\bbannerId\x12&\n" +
+	"\x0fsocial_group_id\x18\x04 \x01(\x03R\rsocialGroupId\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\x05 \x01(\x01R\n" +
+	"confidence\"\xe4\x01\n" +
+	"\bStatsRow\x12\x17\n" +
+	"\aslot_id\x18\x01 \x01(\x03R\x06slotId\x12\x1b\n" +
+	"\tbanner_id\x18\x02 \x01(\x03R\bbannerId\x12&\n" +
+	"\x0fsocial_group_id\x18\x03 \x01(\x03R\rsocialGroupId\x12\x18\n" +
+	"\adisplay\x18\x04 \x01(\x03R\adisplay\x12\x14\n" +
+	"\x05click\x18\x05 \x01(\x03R\x05click\x12\x10\n" +
+	"\x03ctr\x18\x06 \x01(\x01R\x03ctr\x12\x1b\n" +
+	"\tctr_lower\x18\a \x01(\x01R\bctrLower\x12\x1b\n" +
+	"\tctr_upper\x18\b \x01(\x01R\bctrUpper\"w\n" +
+	"\x10GetStatsResponse\x12\x19\n" +
+	"\bgroup_by\x18\x01 \x03(\tR\agroupBy\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\x02 \x01(\x01R\n" +
+	"confidence\x12(\n" +
+	"\x04rows\x18\x03 \x03(\v2\x14.rotator.v1.StatsRowR\x04rows\"\xc2\x01\n" +
+	"\x15GetStatsSeriesRequest\x121\n" +
+	"\x05stats\x18\x01 \x01(\v2\x1b.rotator.v1.GetStatsRequestR\x05stats\x12\x1a\n" +
+	"\binterval\x18\x02 \x01(\tR\binterval\x12.\n" +
+	"\x04from\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\"h\n" +
+	"\n" +
+	"StatsPoint\x12.\n" +
+	"\x04time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12*\n" +
+	"\x05stats\x18\x02 \x01(\v2\x14.rotator.v1.StatsRowR\x05stats\"\xfb\x01\n" +
+	"\x16GetStatsSeriesResponse\x12\x19\n" +
+	"\bgroup_by\x18\x01 \x03(\tR\agroupBy\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\x02 \x01(\x01R\n" +
+	"confidence\x12\x1a\n" +
+	"\binterval\x18\x03 \x01(\tR\binterval\x12.\n" +
+	"\x04from\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x12.\n" +
+	"\x06points\x18\x06 \x03(\v2\x16.rotator.v1.StatsPointR\x06points\"\x99\x01\n" +
+	"\tImportRow\x12\x17\n" +
+	"\aslot_id\x18\x01 \x01(\x03R\x06slotId\x12\x1b\n" +
+	"\tbanner_id\x18\x02 \x01(\x03R\bbannerId\x12&\n" +
+	"\x0fsocial_group_id\x18\x03 \x01(\x03R\rsocialGroupId\x12\x18\n" +
+	"\adisplay\x18\x04 \x01(\x03R\adisplay\x12\x14\n" +
+	"\x05click\x18\x05 \x01(\x03R\x05click\"S\n" +
+	"\x12ImportStatsRequest\x12\x12\n" +
+	"\x04mode\x18\x01 \x01(\tR\x04mode\x12)\n" +
+	"\x04rows\x18\x02 \x03(\v2\x15.rotator.v1.ImportRowR\x04rows\"H\n" +
+	"\x13ImportStatsResponse\x121\n" +
+	"\aresults\x18\x01 \x03(\v2\x17.rotator.v1.EventResultR\aresults\"?\n" +
+	"\vCatalogItem\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\"k\n" +
+	"\x18CreateCatalogItemRequest\x12-\n" +
+	"\acatalog\x18\x01 \x01(\x0e2\x13.rotator.v1.CatalogR\acatalog\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\"S\n" +
+	"\x12CatalogItemRequest\x12-\n" +
+	"\acatalog\x18\x01 \x01(\x0e2\x13.rotator.v1.CatalogR\acatalog\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\x03R\x02id\"H\n" +
+	"\x17ListCatalogItemsRequest\x12-\n" +
+	"\acatalog\x18\x01 \x01(\x0e2\x13.rotator.v1.CatalogR\acatalog\"I\n" +
+	"\x18ListCatalogItemsResponse\x12-\n" +
+	"\x05items\x18\x01 \x03(\v2\x17.rotator.v1.CatalogItemR\x05items\"{\n" +
+	"\x18UpdateCatalogItemRequest\x12-\n" +
+	"\acatalog\x18\x01 \x01(\x0e2\x13.rotator.v1.CatalogR\acatalog\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\x03R\x02id\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\"+\n" +
+	"\x10SlotPriorRequest\x12\x17\n" +
+	"\aslot_id\x18\x01 \x01(\x03R\x06slotId\"1\n" +
+	"\x05Prior\x12\x14\n" +
+	"\x05alpha\x18\x01 \x01(\x01R\x05alpha\x12\x12\n" +
+	"\x04beta\x18\x02 \x01(\x01R\x04beta\"g\n" +
+	"\tSlotPrior\x12\x17\n" +
+	"\aslot_id\x18\x01 \x01(\x03R\x06slotId\x12'\n" +
+	"\x05prior\x18\x02 \x01(\v2\x11.rotator.v1.PriorR\x05prior\x12\x18\n" +
+	"\adefault\x18\x03 \x01(\bR\adefault\"W\n" +
+	"\x13SetSlotPriorRequest\x12\x17\n" +
+	"\aslot_id\x18\x01 \x01(\x03R\x06slotId\x12'\n" +
+	"\x05prior\x18\x02 \x01(\v2\x11.rotator.v1.PriorR\x05prior*b\n" +
+	"\aCatalog\x12\x17\n" +
+	"\x13CATALOG_UNSPECIFIED\x10\x00\x12\x12\n" +
+	"\x0eCATALOG_BANNER\x10\x01\x12\x10\n" +
+	"\fCATALOG_SLOT\x10\x02\x12\x18\n" +
+	"\x14CATALOG_SOCIAL_GROUP\x10\x032\x8e\r\n" +
+	"\aRotator\x12J\n" +
+	"\x0fAddBannerToSlot\x12\x1f.rotator.v1.BannerToSlotRequest\x1a\x16.google.protobuf.Empty\x12O\n" +
+	"\x14RemoveBannerFromSlot\x12\x1f.rotator.v1.BannerToSlotRequest\x1a\x16.google.protobuf.Empty\x12M\n" +
+	"\x0fCountTransition\x12\".rotator.v1.CountTransitionRequest\x1a\x16.google.protobuf.Empty\x12Q\n" +
+	"\fChooseBanner\x12\x1f.rotator.v1.ChooseBannerRequest\x1a .rotator.v1.ChooseBannerResponse\x12S\n" +
+	"\rExplainBanner\x12\x1f.rotator.v1.ChooseBannerRequest\x1a!.rotator.v1.ExplainBannerResponse\x12O\n" +
+	"\x12ChooseBannerStream\x12\x19.rotator.v1.StreamRequest\x1a\x1a.rotator.v1.StreamResponse(\x010\x01\x12N\n" +
+	"\vApplyEvents\x12\x1e.rotator.v1.ApplyEventsRequest\x1a\x1f.rotator.v1.ApplyEventsResponse\x12E\n" +
+	"\bGetStats\x12\x1b.rotator.v1.GetStatsRequest\x1a\x1c.rotator.v1.GetStatsResponse\x12W\n" +
+	"\x0eGetStatsSeries\x12!.rotator.v1.GetStatsSeriesRequest\x1a\".rotator.v1.GetStatsSeriesResponse\x12N\n" +
+	"\vImportStats\x12\x1e.rotator.v1.ImportStatsRequest\x1a\x1f.rotator.v1.ImportStatsResponse\x12R\n" +
+	"\x11CreateCatalogItem\x12$.rotator.v1.CreateCatalogItemRequest\x1a\x17.rotator.v1.CatalogItem\x12I\n" +
+	"\x0eGetCatalogItem\x12\x1e.rotator.v1.CatalogItemRequest\x1a\x17.rotator.v1.CatalogItem\x12]\n" +
+	"\x10ListCatalogItems\x12#.rotator.v1.ListCatalogItemsRequest\x1a$.rotator.v1.ListCatalogItemsResponse\x12R\n" +
+	"\x11UpdateCatalogItem\x12$.rotator.v1.UpdateCatalogItemRequest\x1a\x17.rotator.v1.CatalogItem\x12K\n" +
+	"\x11DeleteCatalogItem\x12\x1e.rotator.v1.CatalogItemRequest\x1a\x16.google.protobuf.Empty\x12C\n" +
+	"\fGetSlotPrior\x12\x1c.rotator.v1.SlotPriorRequest\x1a\x15.rotator.v1.SlotPrior\x12F\n" +
+	"\fSetSlotPrior\x12\x1f.rotator.v1.SetSlotPriorRequest\x1a\x15.rotator.v1.SlotPrior\x12P\n" +
+	"\x11GetBannerSchedule\x12\x1f.rotator.v1.BannerToSlotRequest\x1a\x1a.rotator.v1.BannerSchedule\x12K\n" +
+	"\x11SetBannerSchedule\x12\x1a.rotator.v1.BannerSchedule\x1a\x1a.rotator.v1.BannerSchedule\x12L\n" +
+	"\x0fGetBannerStatus\x12\x1f.rotator.v1.BannerToSlotRequest\x1a\x18.rotator.v1.BannerStatus\x12E\n" +
+	"\x0fSetBannerStatus\x12\x18.rotator.v1.BannerStatus\x1a\x18.rotator.v1.BannerStatusB!Z\x1frotator/internal/server/grpc/pbb\x06proto3"
+
+var (
+	file_rotator_proto_rawDescOnce sync.Once
+	file_rotator_proto_rawDescData []byte
+)
+
+func file_rotator_proto_rawDescGZIP() []byte {
+	file_rotator_proto_rawDescOnce.Do(func() {
+		file_rotator_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_rotator_proto_rawDesc), len(file_rotator_proto_rawDesc)))
+	})
+	return file_rotator_proto_rawDescData
+}
+
+var file_rotator_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_rotator_proto_msgTypes = make([]protoimpl.MessageInfo, 36)
+var file_rotator_proto_goTypes = []any{
+	(Catalog)(0),                     // 0: rotator.v1.Catalog
+	(*BannerToSlotRequest)(nil),      // 1: rotator.v1.BannerToSlotRequest
+	(*WarmStart)(nil),                // 2: rotator.v1.WarmStart
+	(*Schedule)(nil),                 // 3: rotator.v1.Schedule
+	(*BannerSchedule)(nil),           // 4: rotator.v1.BannerSchedule
+	(*BannerStatus)(nil),             // 5: rotator.v1.BannerStatus
+	(*CountTransitionRequest)(nil),   // 6: rotator.v1.CountTransitionRequest
+	(*ChooseBannerRequest)(nil),      // 7: rotator.v1.ChooseBannerRequest
+	(*ChooseBannerResponse)(nil),     // 8: rotator.v1.ChooseBannerResponse
+	(*BannerEstimate)(nil),           // 9: rotator.v1.BannerEstimate
+	(*ExplainBannerResponse)(nil),    // 10: rotator.v1.ExplainBannerResponse
+	(*StreamRequest)(nil),            // 11: rotator.v1.StreamRequest
+	(*StreamError)(nil),              // 12: rotator.v1.StreamError
+	(*StreamResponse)(nil),           // 13: rotator.v1.StreamResponse
+	(*Event)(nil),                    // 14: rotator.v1.Event
+	(*ApplyEventsRequest)(nil),       // 15: rotator.v1.ApplyEventsRequest
+	(*EventResult)(nil),              // 16: rotator.v1.EventResult
+	(*ApplyEventsResponse)(nil),      // 17: rotator.v1.ApplyEventsResponse
+	(*GetStatsRequest)(nil),          // 18: rotator.v1.GetStatsRequest
+	(*StatsRow)(nil),                 // 19: rotator.v1.StatsRow
+	(*GetStatsResponse)(nil),         // 20: rotator.v1.GetStatsResponse
+	(*GetStatsSeriesRequest)(nil),    // 21: rotator.v1.GetStatsSeriesRequest
+	(*StatsPoint)(nil),               // 22: rotator.v1.StatsPoint
+	(*GetStatsSeriesResponse)(nil),   // 23: rotator.v1.GetStatsSeriesResponse
+	(*ImportRow)(nil),                // 24: rotator.v1.ImportRow
+	(*ImportStatsRequest)(nil),       // 25: rotator.v1.ImportStatsRequest
+	(*ImportStatsResponse)(nil),      // 26: rotator.v1.ImportStatsResponse
+	(*CatalogItem)(nil),              // 27: rotator.v1.CatalogItem
+	(*CreateCatalogItemRequest)(nil), // 28: rotator.v1.CreateCatalogItemRequest
+	(*CatalogItemRequest)(nil),       // 29: rotator.v1.CatalogItemRequest
+	(*ListCatalogItemsRequest)(nil),  // 30: rotator.v1.ListCatalogItemsRequest
+	(*ListCatalogItemsResponse)(nil), // 31: rotator.v1.ListCatalogItemsResponse
+	(*UpdateCatalogItemRequest)(nil), // 32: rotator.v1.UpdateCatalogItemRequest
+	(*SlotPriorRequest)(nil),         // 33: rotator.v1.SlotPriorRequest
+	(*Prior)(nil),                    // 34: rotator.v1.Prior
+	(*SlotPrior)(nil),                // 35: rotator.v1.SlotPrior
+	(*SetSlotPriorRequest)(nil),      // 36: rotator.v1.SetSlotPriorRequest
+	(*timestamppb.Timestamp)(nil),    // 37: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),      // 38: google.protobuf.Duration
+	(*emptypb.Empty)(nil),            // 39: google.protobuf.Empty
+}
+var file_rotator_proto_depIdxs = []int32{
+	2,  // 0: rotator.v1.BannerToSlotRequest.warm_start:type_name -> rotator.v1.WarmStart
+	3,  // 1: rotator.v1.BannerToSlotRequest.schedule:type_name -> rotator.v1.Schedule
+	37, // 2: rotator.v1.Schedule.from:type_name -> google.protobuf.Timestamp
+	37, // 3: rotator.v1.Schedule.to:type_name -> google.protobuf.Timestamp
+	3,  // 4: rotator.v1.BannerSchedule.schedule:type_name -> rotator.v1.Schedule
+	9,  // 5: rotator.v1.ExplainBannerResponse.candidates:type_name -> rotator.v1.BannerEstimate
+	7,  // 6: rotator.v1.StreamRequest.choose:type_name -> rotator.v1.ChooseBannerRequest
+	6,  // 7: rotator.v1.StreamRequest.click:type_name -> rotator.v1.CountTransitionRequest
+	38, // 8: rotator.v1.StreamError.retry_delay:type_name -> google.protobuf.Duration
+	8,  // 9: rotator.v1.StreamResponse.choose:type_name -> rotator.v1.ChooseBannerResponse
+	39, // 10: rotator.v1.StreamResponse.click:type_name -> google.protobuf.Empty
+	12, // 11: rotator.v1.StreamResponse.error:type_name -> rotator.v1.StreamError
+	37, // 12: rotator.v1.Event.timestamp:type_name -> google.protobuf.Timestamp
+	14, // 13: rotator.v1.ApplyEventsRequest.events:type_name -> rotator.v1.Event
+	16, // 14: rotator.v1.ApplyEventsResponse.results:type_name -> rotator.v1.EventResult
+	19, // 15: rotator.v1.GetStatsResponse.rows:type_name -> rotator.v1.StatsRow
+	18, // 16: rotator.v1.GetStatsSeriesRequest.stats:type_name -> rotator.v1.GetStatsRequest
+	37, // 17: rotator.v1.GetStatsSeriesRequest.from:type_name -> google.protobuf.Timestamp
+	37, // 18: rotator.v1.GetStatsSeriesRequest.to:type_name -> google.protobuf.Timestamp
+	37, // 19: rotator.v1.StatsPoint.time:type_name -> google.protobuf.Timestamp
+	19, // 20: rotator.v1.StatsPoint.stats:type_name -> rotator.v1.StatsRow
+	37, // 21: rotator.v1.GetStatsSeriesResponse.from:type_name -> google.protobuf.Timestamp
+	37, // 22: rotator.v1.GetStatsSeriesResponse.to:type_name -> google.protobuf.Timestamp
+	22, // 23: rotator.v1.GetStatsSeriesResponse.points:type_name -> rotator.v1.StatsPoint
+	24, // 24: rotator.v1.ImportStatsRequest.rows:type_name -> rotator.v1.ImportRow
+	16, // 25: rotator.v1.ImportStatsResponse.results:type_name -> rotator.v1.EventResult
+	0,  // 26: rotator.v1.CreateCatalogItemRequest.catalog:type_name -> rotator.v1.Catalog
+	0,  // 27: rotator.v1.CatalogItemRequest.catalog:type_name -> rotator.v1.Catalog
+	0,  // 28: rotator.v1.ListCatalogItemsRequest.catalog:type_name -> rotator.v1.Catalog
+	27, // 29: rotator.v1.ListCatalogItemsResponse.items:type_name -> rotator.v1.CatalogItem
+	0,  // 30: rotator.v1.UpdateCatalogItemRequest.catalog:type_name -> rotator.v1.Catalog
+	34, // 31: rotator.v1.SlotPrior.prior:type_name -> rotator.v1.Prior
+	34, // 32: rotator.v1.SetSlotPriorRequest.prior:type_name -> rotator.v1.Prior
+	1,  // 33: rotator.v1.Rotator.AddBannerToSlot:input_type -> rotator.v1.BannerToSlotRequest
+	1,  // 34: rotator.v1.Rotator.RemoveBannerFromSlot:input_type -> rotator.v1.BannerToSlotRequest
+	6,  // 35: rotator.v1.Rotator.CountTransition:input_type -> rotator.v1.CountTransitionRequest
+	7,  // 36: rotator.v1.Rotator.ChooseBanner:input_type -> rotator.v1.ChooseBannerRequest
+	7,  // 37: rotator.v1.Rotator.ExplainBanner:input_type -> rotator.v1.ChooseBannerRequest
+	11, // 38: rotator.v1.Rotator.ChooseBannerStream:input_type -> rotator.v1.StreamRequest
+	15, // 39: rotator.v1.Rotator.ApplyEvents:input_type -> rotator.v1.ApplyEventsRequest
+	18, // 40: rotator.v1.Rotator.GetStats:input_type -> rotator.v1.GetStatsRequest
+	21, // 41: rotator.v1.Rotator.GetStatsSeries:input_type -> rotator.v1.GetStatsSeriesRequest
+	25, // 42: rotator.v1.Rotator.ImportStats:input_type -> rotator.v1.ImportStatsRequest
+	28, // 43: rotator.v1.Rotator.CreateCatalogItem:input_type -> rotator.v1.CreateCatalogItemRequest
+	29, // 44: rotator.v1.Rotator.GetCatalogItem:input_type -> rotator.v1.CatalogItemRequest
+	30, // 45: rotator.v1.Rotator.ListCatalogItems:input_type -> rotator.v1.ListCatalogItemsRequest
+	32, // 46: rotator.v1.Rotator.UpdateCatalogItem:input_type -> rotator.v1.UpdateCatalogItemRequest
+	29, // 47: rotator.v1.Rotator.DeleteCatalogItem:input_type -> rotator.v1.CatalogItemRequest
+	33, // 48: rotator.v1.Rotator.GetSlotPrior:input_type -> rotator.v1.SlotPriorRequest
+	36, // 49: rotator.v1.Rotator.SetSlotPrior:input_type -> rotator.v1.SetSlotPriorRequest
+	1,  // 50: rotator.v1.Rotator.GetBannerSchedule:input_type -> rotator.v1.BannerToSlotRequest
+	4,  // 51: rotator.v1.Rotator.SetBannerSchedule:input_type -> rotator.v1.BannerSchedule
+	1,  // 52: rotator.v1.Rotator.GetBannerStatus:input_type -> rotator.v1.BannerToSlotRequest
+	5,  // 53: rotator.v1.Rotator.SetBannerStatus:input_type -> rotator.v1.BannerStatus
+	39, // 54: rotator.v1.Rotator.AddBannerToSlot:output_type -> google.protobuf.Empty
+	39, // 55: rotator.v1.Rotator.RemoveBannerFromSlot:output_type -> google.protobuf.Empty
+	39, // 56: rotator.v1.Rotator.CountTransition:output_type -> google.protobuf.Empty
+	8,  // 57: rotator.v1.Rotator.ChooseBanner:output_type -> rotator.v1.ChooseBannerResponse
+	10, // 58: rotator.v1.Rotator.ExplainBanner:output_type -> rotator.v1.ExplainBannerResponse
+	13, // 59: rotator.v1.Rotator.ChooseBannerStream:output_type -> rotator.v1.StreamResponse
+	17, // 60: rotator.v1.Rotator.ApplyEvents:output_type -> rotator.v1.ApplyEventsResponse
+	20, // 61: rotator.v1.Rotator.GetStats:output_type -> rotator.v1.GetStatsResponse
+	23, // 62: rotator.v1.Rotator.GetStatsSeries:output_type -> rotator.v1.GetStatsSeriesResponse
+	26, // 63: rotator.v1.Rotator.ImportStats:output_type -> rotator.v1.ImportStatsResponse
+	27, // 64: rotator.v1.Rotator.CreateCatalogItem:output_type -> rotator.v1.CatalogItem
+	27, // 65: rotator.v1.Rotator.GetCatalogItem:output_type -> rotator.v1.CatalogItem
+	31, // 66: rotator.v1.Rotator.ListCatalogItems:output_type -> rotator.v1.ListCatalogItemsResponse
+	27, // 67: rotator.v1.Rotator.UpdateCatalogItem:output_type -> rotator.v1.CatalogItem
+	39, // 68: rotator.v1.Rotator.DeleteCatalogItem:output_type -> google.protobuf.Empty
+	35, // 69: rotator.v1.Rotator.GetSlotPrior:output_type -> rotator.v1.SlotPrior
+	35, // 70: rotator.v1.Rotator.SetSlotPrior:output_type -> rotator.v1.SlotPrior
+	4,  // 71: rotator.v1.Rotator.GetBannerSchedule:output_type -> rotator.v1.BannerSchedule
+	4,  // 72: rotator.v1.Rotator.SetBannerSchedule:output_type -> rotator.v1.BannerSchedule
+	5,  // 73: rotator.v1.Rotator.GetBannerStatus:output_type -> rotator.v1.BannerStatus
+	5,  // 74: rotator.v1.Rotator.SetBannerStatus:output_type -> rotator.v1.BannerStatus
+	54, // [54:75] is the sub-list for method output_type
+	33, // [33:54] is the sub-list for method input_type
+	33, // [33:33] is the sub-list for extension type_name
+	33, // [33:33] is the sub-list for extension extendee
+	0,  // [0:33] is the sub-list for field type_name
+}
+
+func init() { file_rotator_proto_init() }
+func file_rotator_proto_init() {
+	if File_rotator_proto != nil {
+		return
+	}
+	file_rotator_proto_msgTypes[10].OneofWrappers = []any{
+		(*StreamRequest_Choose)(nil),
+		(*StreamRequest_Click)(nil),
+	}
+	file_rotator_proto_msgTypes[12].OneofWrappers = []any{
+		(*StreamResponse_Choose)(nil),
+		(*StreamResponse_Click)(nil),
+		(*StreamResponse_Error)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_rotator_proto_rawDesc), len(file_rotator_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   36,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_rotator_proto_goTypes,
+		DependencyIndexes: file_rotator_proto_depIdxs,
+		EnumInfos:         file_rotator_proto_enumTypes,
+		MessageInfos:      file_rotator_proto_msgTypes,
+	}.Build()
+	File_rotator_proto = out.File
+	file_rotator_proto_goTypes = nil
+	file_rotator_proto_depIdxs = nil
+}
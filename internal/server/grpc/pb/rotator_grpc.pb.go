@@ -0,0 +1,928 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             (unknown)
+// source: rotator.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	Rotator_AddBannerToSlot_FullMethodName      = "/rotator.v1.Rotator/AddBannerToSlot"
+	Rotator_RemoveBannerFromSlot_FullMethodName = "/rotator.v1.Rotator/RemoveBannerFromSlot"
+	Rotator_CountTransition_FullMethodName      = "/rotator.v1.Rotator/CountTransition"
+	Rotator_ChooseBanner_FullMethodName         = "/rotator.v1.Rotator/ChooseBanner"
+	Rotator_ExplainBanner_FullMethodName        = "/rotator.v1.Rotator/ExplainBanner"
+	Rotator_ChooseBannerStream_FullMethodName   = "/rotator.v1.Rotator/ChooseBannerStream"
+	Rotator_ApplyEvents_FullMethodName          = "/rotator.v1.Rotator/ApplyEvents"
+	Rotator_GetStats_FullMethodName             = "/rotator.v1.Rotator/GetStats"
+	Rotator_GetStatsSeries_FullMethodName       = "/rotator.v1.Rotator/GetStatsSeries"
+	Rotator_ImportStats_FullMethodName          = "/rotator.v1.Rotator/ImportStats"
+	Rotator_CreateCatalogItem_FullMethodName    = "/rotator.v1.Rotator/CreateCatalogItem"
+	Rotator_GetCatalogItem_FullMethodName       = "/rotator.v1.Rotator/GetCatalogItem"
+	Rotator_ListCatalogItems_FullMethodName     = "/rotator.v1.Rotator/ListCatalogItems"
+	Rotator_UpdateCatalogItem_FullMethodName    = "/rotator.v1.Rotator/UpdateCatalogItem"
+	Rotator_DeleteCatalogItem_FullMethodName    = "/rotator.v1.Rotator/DeleteCatalogItem"
+	Rotator_GetSlotPrior_FullMethodName         = "/rotator.v1.Rotator/GetSlotPrior"
+	Rotator_SetSlotPrior_FullMethodName         = "/rotator.v1.Rotator/SetSlotPrior"
+	Rotator_GetBannerSchedule_FullMethodName    = "/rotator.v1.Rotator/GetBannerSchedule"
+	Rotator_SetBannerSchedule_FullMethodName    = "/rotator.v1.Rotator/SetBannerSchedule"
+	Rotator_GetBannerStatus_FullMethodName      = "/rotator.v1.Rotator/GetBannerStatus"
+	Rotator_SetBannerStatus_FullMethodName      = "/rotator.v1.Rotator/SetBannerStatus"
+)
+
+// RotatorClient is the client API for Rotator service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Rotator повторяет REST API сервиса.
+type RotatorClient interface {
+	AddBannerToSlot(ctx context.Context, in *BannerToSlotRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	RemoveBannerFromSlot(ctx context.Context, in *BannerToSlotRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	CountTransition(ctx context.Context, in *CountTransitionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	ChooseBanner(ctx context.Context, in *ChooseBannerRequest, opts ...grpc.CallOption) (*ChooseBannerResponse, error)
+	// ExplainBanner оценки всех баннеров слота, по которым выбирается баннер. Показ не засчитывается.
+	ExplainBanner(ctx context.Context, in *ChooseBannerRequest, opts ...grpc.CallOption) (*ExplainBannerResponse, error)
+	// ChooseBannerStream выбор баннеров и клики в одном потоке. Ответы могут
+	// приходить не в порядке запросов, сопоставляются по request_id.
+	ChooseBannerStream(ctx context.Context, opts ...grpc.CallOption) (Rotator_ChooseBannerStreamClient, error)
+	ApplyEvents(ctx context.Context, in *ApplyEventsRequest, opts ...grpc.CallOption) (*ApplyEventsResponse, error)
+	// GetStats показы, клики и CTR с доверительным интервалом по слотам, баннерам и соц.группам.
+	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error)
+	// GetStatsSeries то же по часам или дням.
+	GetStatsSeries(ctx context.Context, in *GetStatsSeriesRequest, opts ...grpc.CallOption) (*GetStatsSeriesResponse, error)
+	// ImportStats загружает накопленные показы и клики, например из прежнего ротатора.
+	ImportStats(ctx context.Context, in *ImportStatsRequest, opts ...grpc.CallOption) (*ImportStatsResponse, error)
+	CreateCatalogItem(ctx context.Context, in *CreateCatalogItemRequest, opts ...grpc.CallOption) (*CatalogItem, error)
+	GetCatalogItem(ctx context.Context, in *CatalogItemRequest, opts ...grpc.CallOption) (*CatalogItem, error)
+	ListCatalogItems(ctx context.Context, in *ListCatalogItemsRequest, opts ...grpc.CallOption) (*ListCatalogItemsResponse, error)
+	UpdateCatalogItem(ctx context.Context, in *UpdateCatalogItemRequest, opts ...grpc.CallOption) (*CatalogItem, error)
+	DeleteCatalogItem(ctx context.Context, in *CatalogItemRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// GetSlotPrior псевдосчетчики слота, SetSlotPrior задает их, без prior - возвращает значения по умолчанию.
+	GetSlotPrior(ctx context.Context, in *SlotPriorRequest, opts ...grpc.CallOption) (*SlotPrior, error)
+	SetSlotPrior(ctx context.Context, in *SetSlotPriorRequest, opts ...grpc.CallOption) (*SlotPrior, error)
+	// GetBannerSchedule окно активности баннера в слоте, SetBannerSchedule заменяет его целиком.
+	GetBannerSchedule(ctx context.Context, in *BannerToSlotRequest, opts ...grpc.CallOption) (*BannerSchedule, error)
+	SetBannerSchedule(ctx context.Context, in *BannerSchedule, opts ...grpc.CallOption) (*BannerSchedule, error)
+	// GetBannerStatus состояние баннера в слоте, SetBannerStatus приостанавливает, возобновляет или
+	// архивирует баннер без потери статистики.
+	GetBannerStatus(ctx context.Context, in *BannerToSlotRequest, opts ...grpc.CallOption) (*BannerStatus, error)
+	SetBannerStatus(ctx context.Context, in *BannerStatus, opts ...grpc.CallOption) (*BannerStatus, error)
+}
+
+type rotatorClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRotatorClient(cc grpc.ClientConnInterface) RotatorClient {
+	return &rotatorClient{cc}
+}
+
+func (c *rotatorClient) AddBannerToSlot(ctx context.Context, in *BannerToSlotRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Rotator_AddBannerToSlot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rotatorClient) RemoveBannerFromSlot(ctx context.Context, in *BannerToSlotRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Rotator_RemoveBannerFromSlot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rotatorClient) CountTransition(ctx context.Context, in *CountTransitionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Rotator_CountTransition_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rotatorClient) ChooseBanner(ctx context.Context, in *ChooseBannerRequest, opts ...grpc.CallOption) (*ChooseBannerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChooseBannerResponse)
+	err := c.cc.Invoke(ctx, Rotator_ChooseBanner_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rotatorClient) ExplainBanner(ctx context.Context, in *ChooseBannerRequest, opts ...grpc.CallOption) (*ExplainBannerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExplainBannerResponse)
+	err := c.cc.Invoke(ctx, Rotator_ExplainBanner_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rotatorClient) ChooseBannerStream(ctx context.Context, opts ...grpc.CallOption) (Rotator_ChooseBannerStreamClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Rotator_ServiceDesc.Streams[0], Rotator_ChooseBannerStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &rotatorChooseBannerStreamClient{ClientStream: stream}
+	return x, nil
+}
+
+type Rotator_ChooseBannerStreamClient interface {
+	Send(*StreamRequest) error
+	Recv() (*StreamResponse, error)
+	grpc.ClientStream
+}
+
+type rotatorChooseBannerStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *rotatorChooseBannerStreamClient) Send(m *StreamRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *rotatorChooseBannerStreamClient) Recv() (*StreamResponse, error) {
+	m := new(StreamResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *rotatorClient) ApplyEvents(ctx context.Context, in *ApplyEventsRequest, opts ...grpc.CallOption) (*ApplyEventsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ApplyEventsResponse)
+	err := c.cc.Invoke(ctx, Rotator_ApplyEvents_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rotatorClient) GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetStatsResponse)
+	err := c.cc.Invoke(ctx, Rotator_GetStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rotatorClient) GetStatsSeries(ctx context.Context, in *GetStatsSeriesRequest, opts ...grpc.CallOption) (*GetStatsSeriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetStatsSeriesResponse)
+	err := c.cc.Invoke(ctx, Rotator_GetStatsSeries_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rotatorClient) ImportStats(ctx context.Context, in *ImportStatsRequest, opts ...grpc.CallOption) (*ImportStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ImportStatsResponse)
+	err := c.cc.Invoke(ctx, Rotator_ImportStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rotatorClient) CreateCatalogItem(ctx context.Context, in *CreateCatalogItemRequest, opts ...grpc.CallOption) (*CatalogItem, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CatalogItem)
+	err := c.cc.Invoke(ctx, Rotator_CreateCatalogItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rotatorClient) GetCatalogItem(ctx context.Context, in *CatalogItemRequest, opts ...grpc.CallOption) (*CatalogItem, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CatalogItem)
+	err := c.cc.Invoke(ctx, Rotator_GetCatalogItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rotatorClient) ListCatalogItems(ctx context.Context, in *ListCatalogItemsRequest, opts ...grpc.CallOption) (*ListCatalogItemsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListCatalogItemsResponse)
+	err := c.cc.Invoke(ctx, Rotator_ListCatalogItems_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rotatorClient) UpdateCatalogItem(ctx context.Context, in *UpdateCatalogItemRequest, opts ...grpc.CallOption) (*CatalogItem, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CatalogItem)
+	err := c.cc.Invoke(ctx, Rotator_UpdateCatalogItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rotatorClient) DeleteCatalogItem(ctx context.Context, in *CatalogItemRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Rotator_DeleteCatalogItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rotatorClient) GetSlotPrior(ctx context.Context, in *SlotPriorRequest, opts ...grpc.CallOption) (*SlotPrior, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SlotPrior)
+	err := c.cc.Invoke(ctx, Rotator_GetSlotPrior_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rotatorClient) SetSlotPrior(ctx context.Context, in *SetSlotPriorRequest, opts ...grpc.CallOption) (*SlotPrior, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SlotPrior)
+	err := c.cc.Invoke(ctx, Rotator_SetSlotPrior_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rotatorClient) GetBannerSchedule(ctx context.Context, in *BannerToSlotRequest, opts ...grpc.CallOption) (*BannerSchedule, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BannerSchedule)
+	err := c.cc.Invoke(ctx, Rotator_GetBannerSchedule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rotatorClient) SetBannerSchedule(ctx context.Context, in *BannerSchedule, opts ...grpc.CallOption) (*BannerSchedule, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BannerSchedule)
+	err := c.cc.Invoke(ctx, Rotator_SetBannerSchedule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rotatorClient) GetBannerStatus(ctx context.Context, in *BannerToSlotRequest, opts ...grpc.CallOption) (*BannerStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BannerStatus)
+	err := c.cc.Invoke(ctx, Rotator_GetBannerStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rotatorClient) SetBannerStatus(ctx context.Context, in *BannerStatus, opts ...grpc.CallOption) (*BannerStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BannerStatus)
+	err := c.cc.Invoke(ctx, Rotator_SetBannerStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RotatorServer is the server API for Rotator service.
+// All implementations must embed UnimplementedRotatorServer
+// for forward compatibility
+//
+// Rotator повторяет REST API сервиса.
+type RotatorServer interface {
+	AddBannerToSlot(context.Context, *BannerToSlotRequest) (*emptypb.Empty, error)
+	RemoveBannerFromSlot(context.Context, *BannerToSlotRequest) (*emptypb.Empty, error)
+	CountTransition(context.Context, *CountTransitionRequest) (*emptypb.Empty, error)
+	ChooseBanner(context.Context, *ChooseBannerRequest) (*ChooseBannerResponse, error)
+	// ExplainBanner оценки всех баннеров слота, по которым выбирается баннер. Показ не засчитывается.
+	ExplainBanner(context.Context, *ChooseBannerRequest) (*ExplainBannerResponse, error)
+	// ChooseBannerStream выбор баннеров и клики в одном потоке. Ответы могут
+	// приходить не в порядке запросов, сопоставляются по request_id.
+	ChooseBannerStream(Rotator_ChooseBannerStreamServer) error
+	ApplyEvents(context.Context, *ApplyEventsRequest) (*ApplyEventsResponse, error)
+	// GetStats показы, клики и CTR с доверительным интервалом по слотам, баннерам и соц.группам.
+	GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error)
+	// GetStatsSeries то же по часам или дням.
+	GetStatsSeries(context.Context, *GetStatsSeriesRequest) (*GetStatsSeriesResponse, error)
+	// ImportStats загружает накопленные показы и клики, например из прежнего ротатора.
+	ImportStats(context.Context, *ImportStatsRequest) (*ImportStatsResponse, error)
+	CreateCatalogItem(context.Context, *CreateCatalogItemRequest) (*CatalogItem, error)
+	GetCatalogItem(context.Context, *CatalogItemRequest) (*CatalogItem, error)
+	ListCatalogItems(context.Context, *ListCatalogItemsRequest) (*ListCatalogItemsResponse, error)
+	UpdateCatalogItem(context.Context, *UpdateCatalogItemRequest) (*CatalogItem, error)
+	DeleteCatalogItem(context.Context, *CatalogItemRequest) (*emptypb.Empty, error)
+	// GetSlotPrior псевдосчетчики слота, SetSlotPrior задает их, без prior - возвращает значения по умолчанию.
+	GetSlotPrior(context.Context, *SlotPriorRequest) (*SlotPrior, error)
+	SetSlotPrior(context.Context, *SetSlotPriorRequest) (*SlotPrior, error)
+	// GetBannerSchedule окно активности баннера в слоте, SetBannerSchedule заменяет его целиком.
+	GetBannerSchedule(context.Context, *BannerToSlotRequest) (*BannerSchedule, error)
+	SetBannerSchedule(context.Context, *BannerSchedule) (*BannerSchedule, error)
+	// GetBannerStatus состояние баннера в слоте, SetBannerStatus приостанавливает, возобновляет или
+	// архивирует баннер без потери статистики.
+	GetBannerStatus(context.Context, *BannerToSlotRequest) (*BannerStatus, error)
+	SetBannerStatus(context.Context, *BannerStatus) (*BannerStatus, error)
+	mustEmbedUnimplementedRotatorServer()
+}
+
+// UnimplementedRotatorServer must be embedded to have forward compatible implementations.
+type UnimplementedRotatorServer struct {
+}
+
+func (UnimplementedRotatorServer) AddBannerToSlot(context.Context, *BannerToSlotRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddBannerToSlot not implemented")
+}
+func (UnimplementedRotatorServer) RemoveBannerFromSlot(context.Context, *BannerToSlotRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveBannerFromSlot not implemented")
+}
+func (UnimplementedRotatorServer) CountTransition(context.Context, *CountTransitionRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CountTransition not implemented")
+}
+func (UnimplementedRotatorServer) ChooseBanner(context.Context, *ChooseBannerRequest) (*ChooseBannerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChooseBanner not implemented")
+}
+func (UnimplementedRotatorServer) ExplainBanner(context.Context, *ChooseBannerRequest) (*ExplainBannerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExplainBanner not implemented")
+}
+func (UnimplementedRotatorServer) ChooseBannerStream(Rotator_ChooseBannerStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method ChooseBannerStream not implemented")
+}
+func (UnimplementedRotatorServer) ApplyEvents(context.Context, *ApplyEventsRequest) (*ApplyEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ApplyEvents not implemented")
+}
+func (UnimplementedRotatorServer) GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
+}
+func (UnimplementedRotatorServer) GetStatsSeries(context.Context, *GetStatsSeriesRequest) (*GetStatsSeriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatsSeries not implemented")
+}
+func (UnimplementedRotatorServer) ImportStats(context.Context, *ImportStatsRequest) (*ImportStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ImportStats not implemented")
+}
+func (UnimplementedRotatorServer) CreateCatalogItem(context.Context, *CreateCatalogItemRequest) (*CatalogItem, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateCatalogItem not implemented")
+}
+func (UnimplementedRotatorServer) GetCatalogItem(context.Context, *CatalogItemRequest) (*CatalogItem, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCatalogItem not implemented")
+}
+func (UnimplementedRotatorServer) ListCatalogItems(context.Context, *ListCatalogItemsRequest) (*ListCatalogItemsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCatalogItems not implemented")
+}
+func (UnimplementedRotatorServer) UpdateCatalogItem(context.Context, *UpdateCatalogItemRequest) (*CatalogItem, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateCatalogItem not implemented")
+}
+func (UnimplementedRotatorServer) DeleteCatalogItem(context.Context, *CatalogItemRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteCatalogItem not implemented")
+}
+func (UnimplementedRotatorServer) GetSlotPrior(context.Context, *SlotPriorRequest) (*SlotPrior, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSlotPrior not implemented")
+}
+func (UnimplementedRotatorServer) SetSlotPrior(context.Context, *SetSlotPriorRequest) (*SlotPrior, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetSlotPrior not implemented")
+}
+func (UnimplementedRotatorServer) GetBannerSchedule(context.Context, *BannerToSlotRequest) (*BannerSchedule, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBannerSchedule not implemented")
+}
+func (UnimplementedRotatorServer) SetBannerSchedule(context.Context, *BannerSchedule) (*BannerSchedule, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetBannerSchedule not implemented")
+}
+func (UnimplementedRotatorServer) GetBannerStatus(context.Context, *BannerToSlotRequest) (*BannerStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBannerStatus not implemented")
+}
+func (UnimplementedRotatorServer) SetBannerStatus(context.Context, *BannerStatus) (*BannerStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetBannerStatus not implemented")
+}
+func (UnimplementedRotatorServer) mustEmbedUnimplementedRotatorServer() {}
+
+// UnsafeRotatorServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RotatorServer will
+// result in compilation errors.
+type UnsafeRotatorServer interface {
+	mustEmbedUnimplementedRotatorServer()
+}
+
+func RegisterRotatorServer(s grpc.ServiceRegistrar, srv RotatorServer) {
+	s.RegisterService(&Rotator_ServiceDesc, srv)
+}
+
+func _Rotator_AddBannerToSlot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BannerToSlotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RotatorServer).AddBannerToSlot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Rotator_AddBannerToSlot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RotatorServer).AddBannerToSlot(ctx, req.(*BannerToSlotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Rotator_RemoveBannerFromSlot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BannerToSlotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RotatorServer).RemoveBannerFromSlot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Rotator_RemoveBannerFromSlot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RotatorServer).RemoveBannerFromSlot(ctx, req.(*BannerToSlotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Rotator_CountTransition_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CountTransitionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RotatorServer).CountTransition(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Rotator_CountTransition_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RotatorServer).CountTransition(ctx, req.(*CountTransitionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Rotator_ChooseBanner_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChooseBannerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RotatorServer).ChooseBanner(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Rotator_ChooseBanner_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RotatorServer).ChooseBanner(ctx, req.(*ChooseBannerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Rotator_ExplainBanner_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChooseBannerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RotatorServer).ExplainBanner(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Rotator_ExplainBanner_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RotatorServer).ExplainBanner(ctx, req.(*ChooseBannerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Rotator_ChooseBannerStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(RotatorServer).ChooseBannerStream(&rotatorChooseBannerStreamServer{ServerStream: stream})
+}
+
+type Rotator_ChooseBannerStreamServer interface {
+	Send(*StreamResponse) error
+	Recv() (*StreamRequest, error)
+	grpc.ServerStream
+}
+
+type rotatorChooseBannerStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *rotatorChooseBannerStreamServer) Send(m *StreamResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *rotatorChooseBannerStreamServer) Recv() (*StreamRequest, error) {
+	m := new(StreamRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Rotator_ApplyEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApplyEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RotatorServer).ApplyEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Rotator_ApplyEvents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RotatorServer).ApplyEvents(ctx, req.(*ApplyEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Rotator_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RotatorServer).GetStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Rotator_GetStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RotatorServer).GetStats(ctx, req.(*GetStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Rotator_GetStatsSeries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatsSeriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RotatorServer).GetStatsSeries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Rotator_GetStatsSeries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RotatorServer).GetStatsSeries(ctx, req.(*GetStatsSeriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Rotator_ImportStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RotatorServer).ImportStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Rotator_ImportStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RotatorServer).ImportStats(ctx, req.(*ImportStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Rotator_CreateCatalogItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateCatalogItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RotatorServer).CreateCatalogItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Rotator_CreateCatalogItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RotatorServer).CreateCatalogItem(ctx, req.(*CreateCatalogItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Rotator_GetCatalogItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CatalogItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RotatorServer).GetCatalogItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Rotator_GetCatalogItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RotatorServer).GetCatalogItem(ctx, req.(*CatalogItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Rotator_ListCatalogItems_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCatalogItemsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RotatorServer).ListCatalogItems(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Rotator_ListCatalogItems_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RotatorServer).ListCatalogItems(ctx, req.(*ListCatalogItemsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Rotator_UpdateCatalogItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateCatalogItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RotatorServer).UpdateCatalogItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Rotator_UpdateCatalogItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RotatorServer).UpdateCatalogItem(ctx, req.(*UpdateCatalogItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Rotator_DeleteCatalogItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CatalogItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RotatorServer).DeleteCatalogItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Rotator_DeleteCatalogItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RotatorServer).DeleteCatalogItem(ctx, req.(*CatalogItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Rotator_GetSlotPrior_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SlotPriorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RotatorServer).GetSlotPrior(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Rotator_GetSlotPrior_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RotatorServer).GetSlotPrior(ctx, req.(*SlotPriorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Rotator_SetSlotPrior_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetSlotPriorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RotatorServer).SetSlotPrior(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Rotator_SetSlotPrior_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RotatorServer).SetSlotPrior(ctx, req.(*SetSlotPriorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Rotator_GetBannerSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BannerToSlotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RotatorServer).GetBannerSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Rotator_GetBannerSchedule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RotatorServer).GetBannerSchedule(ctx, req.(*BannerToSlotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Rotator_SetBannerSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BannerSchedule)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RotatorServer).SetBannerSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Rotator_SetBannerSchedule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RotatorServer).SetBannerSchedule(ctx, req.(*BannerSchedule))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Rotator_GetBannerStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BannerToSlotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RotatorServer).GetBannerStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Rotator_GetBannerStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RotatorServer).GetBannerStatus(ctx, req.(*BannerToSlotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Rotator_SetBannerStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BannerStatus)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RotatorServer).SetBannerStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Rotator_SetBannerStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RotatorServer).SetBannerStatus(ctx, req.(*BannerStatus))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Rotator_ServiceDesc is the grpc.ServiceDesc for Rotator service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Rotator_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "rotator.v1.Rotator",
+	HandlerType: (*RotatorServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AddBannerToSlot",
+			Handler:    _Rotator_AddBannerToSlot_Handler,
+		},
+		{
+			MethodName: "RemoveBannerFromSlot",
+			Handler:    _Rotator_RemoveBannerFromSlot_Handler,
+		},
+		{
+			MethodName: "CountTransition",
+			Handler:    _Rotator_CountTransition_Handler,
+		},
+		{
+			MethodName: "ChooseBanner",
+			Handler:    _Rotator_ChooseBanner_Handler,
+		},
+		{
+			MethodName: "ExplainBanner",
+			Handler:    _Rotator_ExplainBanner_Handler,
+		},
+		{
+			MethodName: "ApplyEvents",
+			Handler:    _Rotator_ApplyEvents_Handler,
+		},
+		{
+			MethodName: "GetStats",
+			Handler:    _Rotator_GetStats_Handler,
+		},
+		{
+			MethodName: "GetStatsSeries",
+			Handler:    _Rotator_GetStatsSeries_Handler,
+		},
+		{
+			MethodName: "ImportStats",
+			Handler:    _Rotator_ImportStats_Handler,
+		},
+		{
+			MethodName: "CreateCatalogItem",
+			Handler:    _Rotator_CreateCatalogItem_Handler,
+		},
+		{
+			MethodName: "GetCatalogItem",
+			Handler:    _Rotator_GetCatalogItem_Handler,
+		},
+		{
+			MethodName: "ListCatalogItems",
+			Handler:    _Rotator_ListCatalogItems_Handler,
+		},
+		{
+			MethodName: "UpdateCatalogItem",
+			Handler:    _Rotator_UpdateCatalogItem_Handler,
+		},
+		{
+			MethodName: "DeleteCatalogItem",
+			Handler:    _Rotator_DeleteCatalogItem_Handler,
+		},
+		{
+			MethodName: "GetSlotPrior",
+			Handler:    _Rotator_GetSlotPrior_Handler,
+		},
+		{
+			MethodName: "SetSlotPrior",
+			Handler:    _Rotator_SetSlotPrior_Handler,
+		},
+		{
+			MethodName: "GetBannerSchedule",
+			Handler:    _Rotator_GetBannerSchedule_Handler,
+		},
+		{
+			MethodName: "SetBannerSchedule",
+			Handler:    _Rotator_SetBannerSchedule_Handler,
+		},
+		{
+			MethodName: "GetBannerStatus",
+			Handler:    _Rotator_GetBannerStatus_Handler,
+		},
+		{
+			MethodName: "SetBannerStatus",
+			Handler:    _Rotator_SetBannerStatus_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ChooseBannerStream",
+			Handler:       _Rotator_ChooseBannerStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "rotator.proto",
+}
@@ -0,0 +1,171 @@
+package internalgrpc
+
+import (
+	"context"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"rotator/internal/events"
+	"rotator/internal/ratelimit"
+	"rotator/internal/server/grpc/pb"
+	"strings"
+	"sync"
+	"time"
+)
+
+// reasonRateLimited код ошибки при превышении частоты запросов, как в REST.
+const reasonRateLimited = "rate_limited"
+
+// rateLimitMethods методы с ограничением частоты и их бюджеты.
+var rateLimitMethods = map[string]string{
+	"ChooseBanner":    ratelimit.RouteChoose,
+	"CountTransition": ratelimit.RouteTransition,
+}
+
+// reserve списывает токен из бюджетов клиента запроса, 0 - запрос можно выполнять.
+func reserve(ctx context.Context, limiter *ratelimit.Limiter, route string) time.Duration {
+	delay, _ := reserveN(ctx, limiter, map[string]int{route: 1})
+
+	return delay
+}
+
+// reserveN списывает из бюджетов клиента запроса по токену на каждый запрос пачки.
+func reserveN(ctx context.Context, limiter *ratelimit.Limiter, counts map[string]int) (time.Duration, error) {
+	var remoteAddr string
+	if p, ok := peer.FromContext(ctx); ok {
+		remoteAddr = p.Addr.String()
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	ip := limiter.ClientIP(remoteAddr, md.Get("x-forwarded-for"), metadataValue(ctx, "x-real-ip"))
+
+	return limiter.ReserveN(counts, ratelimit.Client(ctx), ip, time.Now())
+}
+
+// batchCounts бюджеты пачки событий ApplyEvents по типам событий.
+func batchCounts(req *pb.ApplyEventsRequest) map[string]int {
+	types := make([]events.Type, len(req.GetEvents()))
+	for i, e := range req.GetEvents() {
+		types[i] = events.Type(e.GetType())
+	}
+
+	return ratelimit.Events(types)
+}
+
+// rateLimitError без delay повтор не поможет, и RetryInfo не добавляется.
+func rateLimitError(message string, delay time.Duration) error {
+	st := status.New(codes.ResourceExhausted, message)
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: reasonRateLimited, Domain: errorDomain}}
+	if delay > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(delay)})
+	}
+	withDetails, err := st.WithDetails(details...)
+	if err != nil {
+		return st.Err()
+	}
+
+	return withDetails.Err()
+}
+
+// rateLimitInterceptor ограничивает частоту выбора баннера и переходов в общих с REST бюджетах,
+// ApplyEvents расходует их по каждому клику и показу пачки.
+// Стоит после authInterceptor: бюджет берется по проверенному ключу.
+func rateLimitInterceptor(limiter *ratelimit.Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if limiter == nil {
+			return handler(ctx, req)
+		}
+
+		var counts map[string]int
+		if batch, ok := req.(*pb.ApplyEventsRequest); ok {
+			counts = batchCounts(batch)
+		} else if route, ok := rateLimitMethods[info.FullMethod[strings.LastIndex(info.FullMethod, "/")+1:]]; ok {
+			counts = map[string]int{route: 1}
+		} else {
+			return handler(ctx, req)
+		}
+
+		delay, err := reserveN(ctx, limiter, counts)
+		if err != nil {
+			return nil, rateLimitError(err.Error(), 0)
+		}
+		if delay > 0 {
+			return nil, rateLimitError("rate limit exceeded", delay)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// rateLimitStreamInterceptor ограничивает каждый запрос в ChooseBannerStream. На запрос сверх
+// бюджета поток отвечает ошибкой rate_limited с retry_delay и продолжает работать.
+func rateLimitStreamInterceptor(limiter *ratelimit.Limiter) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if limiter == nil {
+			return handler(srv, ss)
+		}
+
+		return handler(srv, &limitedStream{ServerStream: ss, limiter: limiter})
+	}
+}
+
+// limitedStream отвечает на запросы сверх бюджета сам и не передает их обработчику.
+type limitedStream struct {
+	grpc.ServerStream
+	limiter *ratelimit.Limiter
+
+	// mu SendMsg нельзя вызывать одновременно, а отвечают и обработчик, и поток
+	mu sync.Mutex
+}
+
+func (s *limitedStream) SendMsg(m interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.ServerStream.SendMsg(m)
+}
+
+func (s *limitedStream) RecvMsg(m interface{}) error {
+	for {
+		if err := s.ServerStream.RecvMsg(m); err != nil {
+			return err
+		}
+
+		req, ok := m.(*pb.StreamRequest)
+		if !ok {
+			return nil
+		}
+
+		var route string
+		switch req.GetRequest().(type) {
+		case *pb.StreamRequest_Choose:
+			route = ratelimit.RouteChoose
+		case *pb.StreamRequest_Click:
+			route = ratelimit.RouteTransition
+		default:
+			return nil
+		}
+
+		delay := reserve(s.Context(), s.limiter, route)
+		if delay == 0 {
+			return nil
+		}
+
+		err := s.SendMsg(&pb.StreamResponse{
+			RequestId: req.GetRequestId(),
+			Response: &pb.StreamResponse_Error{Error: &pb.StreamError{
+				Code:       int32(codes.ResourceExhausted),
+				Message:    "rate limit exceeded",
+				Reason:     reasonRateLimited,
+				RetryDelay: durationpb.New(delay),
+			}},
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
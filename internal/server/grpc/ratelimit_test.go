@@ -0,0 +1,127 @@
+package internalgrpc
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+	"rotator/internal/app"
+	"rotator/internal/config"
+	"rotator/internal/ratelimit"
+	"rotator/internal/server/grpc/pb"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	limiter, err := ratelimit.New(config.RateLimitConf{
+		Enabled:    true,
+		Choose:     config.RateLimitRoute{PerKey: config.RateLimit{RPS: 0.5, Burst: 1}},
+		Transition: config.RateLimitRoute{PerIP: config.RateLimit{RPS: 0.5, Burst: 1}},
+	})
+	require.NoError(t, err)
+
+	lis := bufconn.Listen(1024 * 1024)
+	server := NewServer("", "", 4, limiter, app.New(nopLogger{}, fakeStorage{}, nil), nopLogger{})
+	go server.server.Serve(lis)
+	defer server.server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := pb.NewRotatorClient(conn)
+	ctx := context.Background()
+
+	t.Run("unary", func(t *testing.T) {
+		_, err := client.ChooseBanner(ctx, &pb.ChooseBannerRequest{SlotId: 1, SocialGroupId: 1})
+		require.NoError(t, err)
+
+		_, err = client.ChooseBanner(ctx, &pb.ChooseBannerRequest{SlotId: 1, SocialGroupId: 1})
+		st := status.Convert(err)
+		require.Equal(t, codes.ResourceExhausted, st.Code())
+		require.Equal(t, reasonRateLimited, errorReason(st))
+
+		var retry *errdetails.RetryInfo
+		for _, d := range st.Details() {
+			if info, ok := d.(*errdetails.RetryInfo); ok {
+				retry = info
+			}
+		}
+		require.NotNil(t, retry)
+		require.Greater(t, retry.GetRetryDelay().AsDuration(), time.Duration(0))
+	})
+
+	t.Run("stream shares budget and keeps working", func(t *testing.T) {
+		stream, err := client.ChooseBannerStream(ctx)
+		require.NoError(t, err)
+
+		click := &pb.CountTransitionRequest{BannerId: 7, SlotId: 1, SocialGroupId: 1}
+		require.NoError(t, stream.Send(&pb.StreamRequest{RequestId: "first", Request: &pb.StreamRequest_Click{Click: click}}))
+		resp, err := stream.Recv()
+		require.NoError(t, err)
+		require.NotNil(t, resp.GetClick())
+
+		require.NoError(t, stream.Send(&pb.StreamRequest{RequestId: "second", Request: &pb.StreamRequest_Click{Click: click}}))
+		resp, err = stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, "second", resp.GetRequestId())
+		require.Equal(t, int32(codes.ResourceExhausted), resp.GetError().GetCode())
+		require.Equal(t, reasonRateLimited, resp.GetError().GetReason())
+		require.Greater(t, resp.GetError().GetRetryDelay().AsDuration(), time.Duration(0))
+
+		_, err = client.CountTransition(ctx, click)
+		require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+		require.NoError(t, stream.CloseSend())
+		_, err = stream.Recv()
+		require.Error(t, err)
+	})
+}
+
+func TestRateLimitApplyEvents(t *testing.T) {
+	limiter, err := ratelimit.New(config.RateLimitConf{
+		Enabled:    true,
+		Choose:     config.RateLimitRoute{PerKey: config.RateLimit{RPS: 0.5, Burst: 2}},
+		Transition: config.RateLimitRoute{PerKey: config.RateLimit{RPS: 0.5, Burst: 3}},
+	})
+	require.NoError(t, err)
+
+	a := app.New(nopLogger{}, fakeStorage{}, nil)
+	a.StatsMode = app.StatsEvents
+	client := newTestClient(t, limiter, a)
+	ctx := context.Background()
+
+	click := &pb.Event{Type: "click", BannerId: 7, SlotId: 1, SocialGroupId: 1}
+	display := &pb.Event{Type: "display", BannerId: 7, SlotId: 1, SocialGroupId: 1}
+
+	_, err = client.ApplyEvents(ctx, &pb.ApplyEventsRequest{Events: []*pb.Event{click, click, display}})
+	require.NoError(t, err)
+
+	// в бюджете переходов остался один клик: пачка из двух отклоняется целиком
+	_, err = client.ApplyEvents(ctx, &pb.ApplyEventsRequest{Events: []*pb.Event{click, click}})
+	st := status.Convert(err)
+	require.Equal(t, codes.ResourceExhausted, st.Code())
+	require.Equal(t, reasonRateLimited, errorReason(st))
+
+	_, err = client.CountTransition(ctx, &pb.CountTransitionRequest{BannerId: 7, SlotId: 1, SocialGroupId: 1})
+	require.NoError(t, err)
+	_, err = client.CountTransition(ctx, &pb.CountTransitionRequest{BannerId: 7, SlotId: 1, SocialGroupId: 1})
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// пачка больше бюджета не пройдет никогда, повтор не поможет
+	_, err = client.ApplyEvents(ctx, &pb.ApplyEventsRequest{Events: []*pb.Event{display, display, display}})
+	st = status.Convert(err)
+	require.Equal(t, codes.ResourceExhausted, st.Code())
+	require.Equal(t, reasonRateLimited, errorReason(st))
+	for _, d := range st.Details() {
+		_, ok := d.(*errdetails.RetryInfo)
+		require.False(t, ok)
+	}
+}
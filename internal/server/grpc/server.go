@@ -0,0 +1,89 @@
+package internalgrpc
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"net"
+	"rotator/internal/app"
+	"rotator/internal/ratelimit"
+	"rotator/internal/server/grpc/pb"
+)
+
+type Server struct {
+	host   string
+	port   string
+	logger Logger
+	server *grpc.Server
+}
+
+type Logger interface {
+	Debug(message string, fields ...zap.Field)
+	Info(message string, fields ...zap.Field)
+	Error(message string, fields ...zap.Field)
+	Fatal(message string, fields ...zap.Field)
+}
+
+// NewServer limiter - бюджеты частоты запросов, общие с REST, nil - без ограничения.
+func NewServer(host, port string, streamConcurrency int, limiter *ratelimit.Limiter, app *app.App,
+	logger Logger,
+) *Server {
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			tracingInterceptor(),
+			metricsInterceptor(),
+			loggingInterceptor(logger),
+			authInterceptor(app, logger),
+			rateLimitInterceptor(limiter),
+			errorsInterceptor(logger),
+		),
+		grpc.ChainStreamInterceptor(
+			tracingStreamInterceptor(),
+			metricsStreamInterceptor(),
+			loggingStreamInterceptor(logger),
+			authStreamInterceptor(app, logger),
+			rateLimitStreamInterceptor(limiter),
+		),
+	)
+	pb.RegisterRotatorServer(server, NewHandlers(app, streamConcurrency, logger))
+
+	return &Server{
+		host:   host,
+		port:   port,
+		logger: logger,
+		server: server,
+	}
+}
+
+func (s *Server) Start(ctx context.Context) error {
+	s.logger.Info("[+] Staring grpc server and listen", zap.String("host:", s.host), zap.String("port", s.port))
+
+	lis, err := net.Listen("tcp", net.JoinHostPort(s.host, s.port))
+	if err != nil {
+		return err
+	}
+
+	if err := s.server.Serve(lis); err != nil {
+		return err
+	}
+
+	<-ctx.Done()
+
+	return nil
+}
+
+func (s *Server) Stop(ctx context.Context) error {
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.server.Stop()
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package internalgrpc
+
+import (
+	"context"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"io"
+	"rotator/internal/server/grpc/pb"
+	"sync"
+)
+
+const defaultStreamConcurrency = 16
+
+// ChooseBannerStream обрабатывает запросы из потока параллельно, не больше
+// streamConcurrency одновременно, поэтому ответы могут идти не по порядку.
+func (h *Handlers) ChooseBannerStream(stream pb.Rotator_ChooseBannerStreamServer) error {
+	ctx := stream.Context()
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	sem := make(chan struct{}, h.streamConcurrency)
+
+	send := func(resp *pb.StreamResponse) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		// ошибка отправки значит, что поток закрыт, ее вернет Recv
+		_ = stream.Send(resp)
+	}
+
+	for {
+		req, err := stream.Recv()
+		if err != nil {
+			wg.Wait()
+
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return ctx.Err()
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			send(h.handleStreamRequest(ctx, req))
+		}()
+	}
+}
+
+func (h *Handlers) handleStreamRequest(ctx context.Context, req *pb.StreamRequest) *pb.StreamResponse {
+	resp := &pb.StreamResponse{RequestId: req.GetRequestId()}
+
+	switch r := req.GetRequest().(type) {
+	case *pb.StreamRequest_Choose:
+		choice, err := h.ChooseBanner(ctx, r.Choose)
+		if err != nil {
+			resp.Response = h.streamError(err)
+			break
+		}
+		resp.Response = &pb.StreamResponse_Choose{Choose: choice}
+	case *pb.StreamRequest_Click:
+		if _, err := h.CountTransition(ctx, r.Click); err != nil {
+			resp.Response = h.streamError(err)
+			break
+		}
+		resp.Response = &pb.StreamResponse_Click{Click: &emptypb.Empty{}}
+	default:
+		resp.Response = h.streamError(status.Error(codes.InvalidArgument, "empty request"))
+	}
+
+	return resp
+}
+
+func (h *Handlers) streamError(err error) *pb.StreamResponse_Error {
+	st := toStatus(h.logger, "ChooseBannerStream", err)
+
+	return &pb.StreamResponse_Error{Error: &pb.StreamError{
+		Code:    int32(st.Code()),
+		Message: st.Message(),
+		Reason:  errorReason(st),
+	}}
+}
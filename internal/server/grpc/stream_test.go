@@ -0,0 +1,92 @@
+package internalgrpc
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+	"rotator/internal/app"
+	"rotator/internal/server/grpc/pb"
+	sqlstorage "rotator/internal/storage/sql"
+	"testing"
+	"time"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...zap.Field) {}
+func (nopLogger) Info(string, ...zap.Field)  {}
+func (nopLogger) Error(string, ...zap.Field) {}
+func (nopLogger) Fatal(string, ...zap.Field) {}
+
+// fakeStorage реализует только методы, которые нужны выбору баннера и клику.
+type fakeStorage struct {
+	app.Storage
+}
+
+func (fakeStorage) GetBannersStat(_ context.Context, slotID, _ int64, _ time.Time) ([]sqlstorage.BannerStats, int, error) {
+	switch slotID {
+	case 2:
+		return nil, 0, nil
+	case 3:
+		return nil, 0, sqlstorage.ErrNotFound
+	}
+
+	return []sqlstorage.BannerStats{{ID: 7, Display: 1, Click: 1}}, 1, nil
+}
+
+func (fakeStorage) CountDisplay(context.Context, int64, int64, int64) error {
+	return nil
+}
+
+func (fakeStorage) CountTransition(context.Context, int64, int64, int64) error {
+	return nil
+}
+
+func TestChooseBannerStream(t *testing.T) {
+	lis := bufconn.Listen(1024 * 1024)
+	server := NewServer("", "", 4, nil, app.New(nopLogger{}, fakeStorage{}, nil), nopLogger{})
+	go server.server.Serve(lis)
+	defer server.server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	stream, err := pb.NewRotatorClient(conn).ChooseBannerStream(context.Background())
+	require.NoError(t, err)
+
+	requests := []*pb.StreamRequest{
+		{RequestId: "choose", Request: &pb.StreamRequest_Choose{Choose: &pb.ChooseBannerRequest{SlotId: 1, SocialGroupId: 1}}},
+		{RequestId: "click", Request: &pb.StreamRequest_Click{Click: &pb.CountTransitionRequest{BannerId: 7, SlotId: 1, SocialGroupId: 1}}},
+		{RequestId: "empty slot", Request: &pb.StreamRequest_Choose{Choose: &pb.ChooseBannerRequest{SlotId: 2, SocialGroupId: 1}}},
+		{RequestId: "unknown slot", Request: &pb.StreamRequest_Choose{Choose: &pb.ChooseBannerRequest{SlotId: 3, SocialGroupId: 1}}},
+		{RequestId: "empty"},
+	}
+	for _, req := range requests {
+		require.NoError(t, stream.Send(req))
+	}
+	require.NoError(t, stream.CloseSend())
+
+	responses := make(map[string]*pb.StreamResponse)
+	for range requests {
+		resp, err := stream.Recv()
+		require.NoError(t, err)
+		responses[resp.GetRequestId()] = resp
+	}
+
+	require.Equal(t, int64(7), responses["choose"].GetChoose().GetBannerId())
+	require.NotEmpty(t, responses["choose"].GetChoose().GetImpressionId())
+	require.NotNil(t, responses["click"].GetClick())
+	require.Equal(t, int32(codes.NotFound), responses["empty slot"].GetError().GetCode())
+	require.Equal(t, app.ErrNoBannersInSlot.Code, responses["empty slot"].GetError().GetReason())
+	require.Equal(t, int32(codes.NotFound), responses["unknown slot"].GetError().GetCode())
+	require.Equal(t, app.ErrSlotNotFound.Code, responses["unknown slot"].GetError().GetReason())
+	require.Equal(t, int32(codes.InvalidArgument), responses["empty"].GetError().GetCode())
+}
@@ -0,0 +1,101 @@
+package internalhttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"rotator/internal/app"
+	sqlstorage "rotator/internal/storage/sql"
+	"strings"
+)
+
+const (
+	HeaderAPIKey   = "X-API-Key"
+	HeaderTenantID = "X-Tenant-ID"
+)
+
+// routeRoles роль, которая нужна для маршрута. Остальные маршруты доступны только admin.
+var routeRoles = map[string]string{
+	"/api/v1/banner/choose":     app.RoleServing,
+	"/api/v1/banner/transition": app.RoleServing,
+	"/api/v1/events/batch":      app.RoleServing,
+	"/api/v1/banner/explain":    app.RoleAnalytics,
+	"/api/v1/stats":             app.RoleAnalytics,
+	"/api/v1/stats/series":      app.RoleAnalytics,
+	"/api/v1/stats/export":      app.RoleAnalytics,
+	"/debug/vars":               app.RoleAnalytics,
+	"/metrics":                  app.RoleAnalytics,
+}
+
+// publicRoutes маршруты, доступные без ключа.
+var publicRoutes = map[string]bool{
+	"/api/openapi.json": true,
+	"/healthz":          true,
+	"/readyz":           true,
+}
+
+func routeRole(path string) string {
+	if role, ok := routeRoles[path]; ok {
+		return role
+	}
+
+	return app.RoleAdmin
+}
+
+// apiKeyFromRequest ключ передается в заголовке Authorization: Bearer <key> или X-API-Key.
+func apiKeyFromRequest(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimPrefix(auth, "Bearer ")
+	}
+
+	return r.Header.Get(HeaderAPIKey)
+}
+
+// authMiddleware проверяет ключ API и его роль, если аутентификация включена,
+// и определяет арендатора запроса: по ключу или по заголовку X-Tenant-ID.
+func authMiddleware(next http.Handler, a *app.App) http.Handler {
+	handlers := NewServerHandlers(a)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if publicRoutes[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx, err := authenticate(r, a)
+		if err != nil {
+			if errors.Is(err, app.ErrUnauthenticated) {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+			}
+			handlers.ResponseAppError(w, r, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func authenticate(r *http.Request, a *app.App) (context.Context, error) {
+	ctx := r.Context()
+
+	var key *sqlstorage.APIKey
+	if a.AuthEnabled {
+		var err error
+		if key, err = a.Authenticate(ctx, apiKeyFromRequest(r)); err != nil {
+			return nil, err
+		}
+
+		if err := app.Authorize(key, routeRole(r.URL.Path)); err != nil {
+			return nil, err
+		}
+
+		ctx = app.WithAPIKey(ctx, key)
+	}
+
+	tenantID, err := app.ResolveTenant(key, r.Header.Get(HeaderTenantID))
+	if err != nil {
+		return nil, err
+	}
+
+	return app.WithTenant(ctx, tenantID), nil
+}
@@ -0,0 +1,188 @@
+package internalhttp
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"rotator/internal/app"
+	sqlstorage "rotator/internal/storage/sql"
+	"strings"
+	"testing"
+	"time"
+)
+
+// keyStorage знает ключи "serving", "admin" и "analytics" с одноименными ролями.
+type keyStorage struct {
+	fakeStorage
+}
+
+func (keyStorage) GetAPIKeyByHash(_ context.Context, keyHash []byte) (*sqlstorage.APIKey, error) {
+	for _, role := range []string{app.RoleServing, app.RoleAdmin, app.RoleAnalytics} {
+		if bytes.Equal(app.HashAPIKey(role), keyHash) {
+			return &sqlstorage.APIKey{ID: 1, TenantID: 2, Name: role, Role: role}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func TestAuth(t *testing.T) {
+	a := app.New(nopLogger{}, keyStorage{}, nil)
+	a.AuthEnabled = true
+	handler := Routers(a)
+
+	do := func(method, path, key, body string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		return rec.Code
+	}
+	choose := `{"slot_id": 1, "social_group_id": 1}`
+	bannerToSlot := `{"banner_id": 1, "slot_id": 1}`
+
+	t.Run("missing key", func(t *testing.T) {
+		require.Equal(t, http.StatusUnauthorized, do(http.MethodPost, "/api/v1/banner/choose", "", choose))
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		require.Equal(t, http.StatusUnauthorized, do(http.MethodPost, "/api/v1/banner/choose", "nope", choose))
+	})
+
+	t.Run("serving", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, do(http.MethodPost, "/api/v1/banner/choose", "serving", choose))
+		require.Equal(t, http.StatusForbidden, do(http.MethodPost, "/api/v1/banner-slot/add", "serving", bannerToSlot))
+		require.Equal(t, http.StatusForbidden, do(http.MethodGet, "/debug/vars", "serving", ""))
+	})
+
+	t.Run("analytics", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, do(http.MethodGet, "/debug/vars", "analytics", ""))
+		require.Equal(t, http.StatusForbidden, do(http.MethodPost, "/api/v1/banner/choose", "analytics", choose))
+	})
+
+	t.Run("admin", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, do(http.MethodPost, "/api/v1/banner/choose", "admin", choose))
+		require.Equal(t, http.StatusOK, do(http.MethodGet, "/debug/vars", "admin", ""))
+	})
+
+	t.Run("public spec", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, do(http.MethodGet, "/api/openapi.json", "", ""))
+	})
+}
+
+// tenantStorage запоминает арендатора, в рамках которого выбирался баннер.
+type tenantStorage struct {
+	keyStorage
+	tenant *int64
+}
+
+func (s tenantStorage) GetBannersStat(ctx context.Context, slotID, groupID int64, at time.Time) ([]sqlstorage.BannerStats, int, error) {
+	*s.tenant = app.TenantFromContext(ctx)
+
+	return s.keyStorage.GetBannersStat(ctx, slotID, groupID, at)
+}
+
+func TestTenant(t *testing.T) {
+	var tenant int64
+	a := app.New(nopLogger{}, tenantStorage{tenant: &tenant}, nil)
+
+	do := func(key, tenantID string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/banner/choose",
+			strings.NewReader(`{"slot_id": 1, "social_group_id": 1}`))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		if tenantID != "" {
+			req.Header.Set(HeaderTenantID, tenantID)
+		}
+		rec := httptest.NewRecorder()
+		Routers(a).ServeHTTP(rec, req)
+
+		return rec.Code
+	}
+
+	t.Run("default tenant", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, do("", ""))
+		require.Equal(t, sqlstorage.DefaultTenant, tenant)
+	})
+
+	t.Run("tenant from header", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, do("", "3"))
+		require.Equal(t, int64(3), tenant)
+	})
+
+	t.Run("invalid header", func(t *testing.T) {
+		require.Equal(t, http.StatusUnprocessableEntity, do("", "site"))
+	})
+
+	a.AuthEnabled = true
+
+	t.Run("tenant from key", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, do("serving", ""))
+		require.Equal(t, int64(2), tenant)
+		require.Equal(t, http.StatusOK, do("serving", "2"))
+	})
+
+	t.Run("header does not match key", func(t *testing.T) {
+		require.Equal(t, http.StatusForbidden, do("serving", "3"))
+	})
+}
+
+// revocableStorage общая база нескольких экземпляров сервиса: отозванные ключи по ID.
+type revocableStorage struct {
+	keyStorage
+	revoked map[int64]bool
+}
+
+func (s revocableStorage) GetAPIKeyByHash(ctx context.Context, keyHash []byte) (*sqlstorage.APIKey, error) {
+	key, err := s.keyStorage.GetAPIKeyByHash(ctx, keyHash)
+	if key == nil || err != nil {
+		return key, err
+	}
+	if key.Role == app.RoleAdmin {
+		key.ID = 2
+	}
+	if s.revoked[key.ID] {
+		return nil, nil
+	}
+
+	return key, nil
+}
+
+func (s revocableStorage) RevokeAPIKey(_ context.Context, id int64) error {
+	s.revoked[id] = true
+
+	return nil
+}
+
+func TestRevokeOnAnotherInstance(t *testing.T) {
+	storage := revocableStorage{revoked: make(map[int64]bool)}
+	first := app.New(nopLogger{}, storage, nil)
+	second := app.New(nopLogger{}, storage, nil)
+	ctx := context.Background()
+
+	_, err := first.Authenticate(ctx, app.RoleAdmin)
+	require.NoError(t, err)
+	_, err = first.Authenticate(ctx, app.RoleServing)
+	require.NoError(t, err)
+
+	require.NoError(t, second.RevokeAPIKey(ctx, 2))
+	require.NoError(t, second.RevokeAPIKey(ctx, 1))
+
+	// ключ admin не кешируется и перестает работать сразу
+	_, err = first.Authenticate(ctx, app.RoleAdmin)
+	require.ErrorIs(t, err, app.ErrUnauthenticated)
+
+	// ключ serving действует до истечения кеша в первом экземпляре, во втором кеш сброшен
+	_, err = first.Authenticate(ctx, app.RoleServing)
+	require.NoError(t, err)
+	_, err = second.Authenticate(ctx, app.RoleServing)
+	require.ErrorIs(t, err, app.ErrUnauthenticated)
+}
@@ -0,0 +1,134 @@
+package internalhttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"net/http"
+	"rotator/internal/app"
+	"strconv"
+)
+
+// catalogPaths справочники и их пути в API.
+var catalogPaths = map[string]string{
+	"banners":       app.CatalogBanner,
+	"slots":         app.CatalogSlot,
+	"social-groups": app.CatalogSocialGroup,
+}
+
+func ResponseJSON(w http.ResponseWriter, code int, v interface{}) {
+	res, err := json.Marshal(v)
+	if err != nil {
+		ResponseError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(res)
+}
+
+func parseID(r *http.Request) (int64, error) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid id: %w", err)
+	}
+
+	return id, nil
+}
+
+func (s *ServerHandlers) CreateCatalogItem(catalog string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var dto CatalogItemDto
+
+		err := ParsingData(r, &dto)
+		if err != nil {
+			ResponseError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		id, err := s.app.CreateCatalogItem(r.Context(), catalog, dto.Description)
+		if err != nil {
+			s.ResponseAppError(w, r, err)
+			return
+		}
+
+		ResponseJSON(w, http.StatusCreated, CatalogItemDto{ID: id, Description: dto.Description})
+	}
+}
+
+func (s *ServerHandlers) GetCatalogItem(catalog string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseID(r)
+		if err != nil {
+			ResponseError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		item, err := s.app.GetCatalogItem(r.Context(), catalog, id)
+		if err != nil {
+			s.ResponseAppError(w, r, err)
+			return
+		}
+
+		ResponseJSON(w, http.StatusOK, CatalogItemDto{ID: item.ID, Description: item.Description})
+	}
+}
+
+func (s *ServerHandlers) ListCatalogItems(catalog string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		items, err := s.app.ListCatalogItems(r.Context(), catalog)
+		if err != nil {
+			s.ResponseAppError(w, r, err)
+			return
+		}
+
+		response := make([]CatalogItemDto, len(items))
+		for i, item := range items {
+			response[i] = CatalogItemDto{ID: item.ID, Description: item.Description}
+		}
+
+		ResponseJSON(w, http.StatusOK, response)
+	}
+}
+
+func (s *ServerHandlers) UpdateCatalogItem(catalog string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseID(r)
+		if err != nil {
+			ResponseError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		var dto CatalogItemDto
+		if err := ParsingData(r, &dto); err != nil {
+			ResponseError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := s.app.UpdateCatalogItem(r.Context(), catalog, id, dto.Description); err != nil {
+			s.ResponseAppError(w, r, err)
+			return
+		}
+
+		ResponseJSON(w, http.StatusOK, CatalogItemDto{ID: id, Description: dto.Description})
+	}
+}
+
+func (s *ServerHandlers) DeleteCatalogItem(catalog string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseID(r)
+		if err != nil {
+			ResponseError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := s.app.DeleteCatalogItem(r.Context(), catalog, id); err != nil {
+			s.ResponseAppError(w, r, err)
+			return
+		}
+
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
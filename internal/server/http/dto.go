@@ -1,8 +1,17 @@
 package internalhttp
 
+import "time"
+
 type ErrorDto struct {
-	Success bool   `json:"success"`
-	Error   string `json:"error"`
+	Success bool            `json:"success"`
+	Code    string          `json:"code"`
+	Error   string          `json:"error"`
+	Fields  []FieldErrorDto `json:"fields,omitempty"`
+}
+
+type FieldErrorDto struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
 }
 
 type BannerToSlotDto struct {
@@ -10,13 +19,163 @@ type BannerToSlotDto struct {
 	SlotID   int64 `json:"slot_id"`
 }
 
+// AddBannerToSlotDto WarmStart не задан - у баннера только псевдосчетчики слота,
+// Schedule не задан - баннер участвует в выборе всегда.
+type AddBannerToSlotDto struct {
+	BannerToSlotDto
+	WarmStart *WarmStartDto `json:"warm_start,omitempty"`
+	Schedule  *ScheduleDto  `json:"schedule,omitempty"`
+}
+
+// ScheduleDto окно [from, to), weekdays от 1 (понедельник) до 7, hours от 0 до 23.
+type ScheduleDto struct {
+	From     *time.Time `json:"from,omitempty"`
+	To       *time.Time `json:"to,omitempty"`
+	Weekdays []int      `json:"weekdays,omitempty"`
+	Hours    []int      `json:"hours,omitempty"`
+}
+
+type BannerScheduleDto struct {
+	BannerToSlotDto
+	ScheduleDto
+}
+
+// BannerStatusDto Status - active, paused или archived.
+type BannerStatusDto struct {
+	BannerToSlotDto
+	Status string `json:"status"`
+}
+
+type WarmStartDto struct {
+	Source   string `json:"source"`
+	BannerID int64  `json:"banner_id,omitempty"`
+	Displays int64  `json:"displays,omitempty"`
+}
+
 type CountTransitionDto struct {
-	BannerID      int64 `json:"banner_id"`
+	BannerID      int64  `json:"banner_id"`
+	SlotID        int64  `json:"slot_id"`
+	SocialGroupID int64  `json:"social_group_id"`
+	ImpressionID  string `json:"impression_id,omitempty"`
+}
+
+type ChooseBannerDto struct {
 	SlotID        int64 `json:"slot_id"`
 	SocialGroupID int64 `json:"social_group_id"`
 }
 
-type ChooseBannerDto struct {
+type ChosenBannerDto struct {
+	BannerID     int64  `json:"banner_id"`
+	ImpressionID string `json:"impression_id"`
+}
+
+type EventDto struct {
+	Type          string     `json:"type"`
+	BannerID      int64      `json:"banner_id"`
+	SlotID        int64      `json:"slot_id"`
+	SocialGroupID int64      `json:"social_group_id"`
+	ImpressionID  string     `json:"impression_id,omitempty"`
+	Timestamp     *time.Time `json:"timestamp,omitempty"`
+}
+
+type EventResultDto struct {
+	Index   int    `json:"index"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+	Code    string `json:"code,omitempty"`
+}
+
+type EventsBatchResultDto struct {
+	Results []EventResultDto `json:"results"`
+}
+
+type ImportStatsDto struct {
+	Mode string         `json:"mode"`
+	Rows []ImportRowDto `json:"rows"`
+}
+
+type ImportRowDto struct {
 	SlotID        int64 `json:"slot_id"`
+	BannerID      int64 `json:"banner_id"`
 	SocialGroupID int64 `json:"social_group_id"`
+	Display       int64 `json:"display"`
+	Click         int64 `json:"click"`
+}
+
+type PriorDto struct {
+	Alpha float64 `json:"alpha"`
+	Beta  float64 `json:"beta"`
+}
+
+// SlotPriorDto Default - у слота нет своих псевдосчетчиков, действуют значения из конфигурации.
+type SlotPriorDto struct {
+	SlotID  int64   `json:"slot_id"`
+	Alpha   float64 `json:"alpha"`
+	Beta    float64 `json:"beta"`
+	Default bool    `json:"default"`
+}
+
+type CatalogItemDto struct {
+	ID          int64  `json:"id"`
+	Description string `json:"description"`
+}
+
+type ExplanationDto struct {
+	Strategy     string `json:"strategy"`
+	TotalDisplay int    `json:"total_display"`
+	// BannerID баннер, который был бы выбран
+	BannerID   int64          `json:"banner_id"`
+	Candidates []CandidateDto `json:"candidates"`
+}
+
+// StatsDto статистика по группам измерений group_by.
+type StatsDto struct {
+	GroupBy    []string      `json:"group_by"`
+	Confidence float64       `json:"confidence"`
+	Rows       []StatsRowDto `json:"rows"`
+}
+
+// StatsRowDto ID измерений, по которым не группировали, не выводятся. CTR в интервале
+// [ctr_lower, ctr_upper] с уровнем доверия confidence (интервал Уилсона).
+type StatsRowDto struct {
+	SlotID        int64   `json:"slot_id,omitempty"`
+	BannerID      int64   `json:"banner_id,omitempty"`
+	SocialGroupID int64   `json:"social_group_id,omitempty"`
+	Display       int64   `json:"display"`
+	Click         int64   `json:"click"`
+	CTR           float64 `json:"ctr"`
+	CTRLower      float64 `json:"ctr_lower"`
+	CTRUpper      float64 `json:"ctr_upper"`
+}
+
+// SeriesDto статистика по периодам interval в [from, to).
+type SeriesDto struct {
+	GroupBy    []string         `json:"group_by"`
+	Confidence float64          `json:"confidence"`
+	Interval   string           `json:"interval"`
+	From       time.Time        `json:"from"`
+	To         time.Time        `json:"to"`
+	Points     []SeriesPointDto `json:"points"`
+}
+
+// SeriesPointDto статистика группы за период, который начинается в time.
+type SeriesPointDto struct {
+	Time time.Time `json:"time"`
+	StatsRowDto
+}
+
+// CandidateDto оценка баннера. CTR - наблюдаемый click / display, Mean - CTR с псевдосчетчиками,
+// по нему считается оценка. У баннера без показов бонус и оценка бесконечны,
+// в JSON они null, а unexplored = true.
+type CandidateDto struct {
+	BannerID         int64    `json:"banner_id"`
+	Display          int64    `json:"display"`
+	Click            int64    `json:"click"`
+	CTR              float64  `json:"ctr"`
+	Mean             float64  `json:"mean"`
+	PriorAlpha       float64  `json:"prior_alpha"`
+	PriorBeta        float64  `json:"prior_beta"`
+	ExplorationBonus *float64 `json:"exploration_bonus"`
+	Score            *float64 `json:"score"`
+	Unexplored       bool     `json:"unexplored,omitempty"`
 }
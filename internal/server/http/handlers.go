@@ -3,11 +3,20 @@ package internalhttp
 import (
 	"encoding/json"
 	"fmt"
+	"go.uber.org/zap"
 	"io/ioutil"
+	"math"
 	"net/http"
 	"rotator/internal/app"
+	"rotator/internal/events"
 )
 
+// maxEventsBatch ограничение на размер пачки событий в одном запросе.
+const maxEventsBatch = 1000
+
+// maxImportRows ограничение на число строк статистики в одном запросе импорта.
+const maxImportRows = 1000
+
 type ServerHandlers struct {
 	app *app.App
 }
@@ -16,15 +25,63 @@ func NewServerHandlers(a *app.App) *ServerHandlers {
 	return &ServerHandlers{app: a}
 }
 
+// errorStatuses HTTP статусы для классов ошибок приложения.
+var errorStatuses = map[app.ErrorKind]int{
+	app.KindInternal:         http.StatusInternalServerError,
+	app.KindNotFound:         http.StatusNotFound,
+	app.KindAlreadyExists:    http.StatusConflict,
+	app.KindValidation:       http.StatusUnprocessableEntity,
+	app.KindUnavailable:      http.StatusServiceUnavailable,
+	app.KindUnauthenticated:  http.StatusUnauthorized,
+	app.KindPermissionDenied: http.StatusForbidden,
+}
+
+// Коды ошибок, которые возникают до вызова приложения.
+const (
+	CodeBadRequest       = "bad_request"
+	CodePayloadTooLarge  = "payload_too_large"
+	CodeValidationFailed = "validation_failed"
+	CodeRateLimited      = "rate_limited"
+)
+
 func ResponseError(w http.ResponseWriter, code int, err error) {
-	data, err := json.Marshal(ErrorDto{
+	errCode := CodeBadRequest
+	switch code {
+	case http.StatusRequestEntityTooLarge:
+		errCode = CodePayloadTooLarge
+	case http.StatusInternalServerError:
+		errCode = app.ErrInternal.Code
+	}
+
+	writeError(w, code, ErrorDto{
 		Success: false,
+		Code:    errCode,
 		Error:   err.Error(),
 	})
+}
+
+// ResponseAppError отвечает статусом и кодом по ошибке приложения.
+// Подробности внутренних ошибок и недоступности хранилища только логируются.
+func (s *ServerHandlers) ResponseAppError(w http.ResponseWriter, r *http.Request, err error) {
+	appErr := app.AsError(err)
+	if appErr.Kind == app.KindInternal || appErr.Kind == app.KindUnavailable {
+		s.app.Logger.Error("[-] Request failed", zap.String("url", r.URL.Path), zap.Error(err))
+	}
+
+	writeError(w, errorStatuses[appErr.Kind], ErrorDto{
+		Success: false,
+		Code:    appErr.Code,
+		Error:   appErr.PublicMessage(),
+	})
+}
+
+func writeError(w http.ResponseWriter, code int, dto ErrorDto) {
+	data, err := json.Marshal(dto)
 
 	if err != nil {
 		w.WriteHeader(500)
 		w.Write([]byte("Failed to marshall error dto"))
+		return
 	}
 
 	w.Header().Add("Content-Type", "application/json")
@@ -47,7 +104,7 @@ func ParsingData(r *http.Request, dto interface{}) error {
 }
 
 func (s *ServerHandlers) AddBannerToSlot(w http.ResponseWriter, r *http.Request) {
-	var dto BannerToSlotDto
+	var dto AddBannerToSlotDto
 
 	err := ParsingData(r, &dto)
 	if err != nil {
@@ -55,9 +112,23 @@ func (s *ServerHandlers) AddBannerToSlot(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err = s.app.AddBannerToSlot(r.Context(), dto.BannerID, dto.SlotID)
+	var warmStart app.WarmStart
+	if dto.WarmStart != nil {
+		warmStart = app.WarmStart{
+			Source:   dto.WarmStart.Source,
+			BannerID: dto.WarmStart.BannerID,
+			Displays: dto.WarmStart.Displays,
+		}
+	}
+
+	var schedule app.Schedule
+	if dto.Schedule != nil {
+		schedule = dto.Schedule.schedule()
+	}
+
+	err = s.app.AddBannerToSlot(r.Context(), dto.BannerID, dto.SlotID, warmStart, schedule)
 	if err != nil {
-		ResponseError(w, http.StatusBadRequest, err)
+		s.ResponseAppError(w, r, err)
 		return
 	}
 
@@ -76,7 +147,7 @@ func (s *ServerHandlers) RemoveBannerToSlot(w http.ResponseWriter, r *http.Reque
 
 	err = s.app.RemoveBannerToSlot(r.Context(), dto.BannerID, dto.SlotID)
 	if err != nil {
-		ResponseError(w, http.StatusBadRequest, err)
+		s.ResponseAppError(w, r, err)
 		return
 	}
 
@@ -93,9 +164,9 @@ func (s *ServerHandlers) CountTransition(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err = s.app.CountTransition(r.Context(), dto.BannerID, dto.SlotID, dto.SocialGroupID)
+	err = s.app.CountTransition(r.Context(), dto.BannerID, dto.SlotID, dto.SocialGroupID, dto.ImpressionID)
 	if err != nil {
-		ResponseError(w, http.StatusBadRequest, err)
+		s.ResponseAppError(w, r, err)
 		return
 	}
 
@@ -112,22 +183,127 @@ func (s *ServerHandlers) ChooseBanner(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	bannerID, err := s.app.ChooseBanner(r.Context(), dto.SlotID, dto.SocialGroupID)
+	choice, err := s.app.ChooseBanner(r.Context(), dto.SlotID, dto.SocialGroupID)
+	if err != nil {
+		s.ResponseAppError(w, r, err)
+		return
+	}
+
+	res, err := json.Marshal(ChosenBannerDto{
+		BannerID:     choice.BannerID,
+		ImpressionID: choice.ImpressionID,
+	})
+	if err != nil {
+		ResponseError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
+
+func (s *ServerHandlers) ExplainBanner(w http.ResponseWriter, r *http.Request) {
+	var dto ChooseBannerDto
+
+	err := ParsingData(r, &dto)
 	if err != nil {
 		ResponseError(w, http.StatusBadRequest, err)
 		return
 	}
 
-	response := map[string]int64{
-		"banner_id": bannerID,
+	explanation, err := s.app.ExplainBanner(r.Context(), dto.SlotID, dto.SocialGroupID)
+	if err != nil {
+		s.ResponseAppError(w, r, err)
+		return
+	}
+
+	result := ExplanationDto{
+		Strategy:     explanation.Strategy,
+		TotalDisplay: explanation.TotalDisplay,
+		BannerID:     explanation.WinnerID,
+		Candidates:   make([]CandidateDto, len(explanation.Candidates)),
+	}
+	for i, c := range explanation.Candidates {
+		result.Candidates[i] = CandidateDto{
+			BannerID:   int64(c.ID),
+			Display:    int64(c.Trials),
+			Click:      int64(c.Reward),
+			CTR:        c.CTR(),
+			Mean:       c.Mean,
+			PriorAlpha: c.Alpha,
+			PriorBeta:  c.Beta,
+		}
+		if math.IsInf(c.Score, 0) {
+			result.Candidates[i].Unexplored = true
+			continue
+		}
+		bonus, score := c.Bonus, c.Score
+		result.Candidates[i].ExplorationBonus = &bonus
+		result.Candidates[i].Score = &score
 	}
 
-	res, err := json.Marshal(response)
+	res, err := json.Marshal(result)
+	if err != nil {
+		ResponseError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
+
+func (s *ServerHandlers) EventsBatch(w http.ResponseWriter, r *http.Request) {
+	var dto []EventDto
+
+	err := ParsingData(r, &dto)
 	if err != nil {
 		ResponseError(w, http.StatusBadRequest, err)
 		return
 	}
 
+	if len(dto) > maxEventsBatch {
+		ResponseError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("batch is limited to %d events", maxEventsBatch))
+		return
+	}
+
+	batch := make([]events.Event, len(dto))
+	for i, v := range dto {
+		batch[i] = events.Event{
+			Type:          events.Type(v.Type),
+			SlotID:        v.SlotID,
+			BannerID:      v.BannerID,
+			SocialGroupID: v.SocialGroupID,
+			ImpressionID:  v.ImpressionID,
+		}
+		if v.Timestamp != nil {
+			batch[i].Timestamp = v.Timestamp.UTC()
+		}
+	}
+
+	results, err := s.app.ApplyEvents(r.Context(), batch)
+	if err != nil {
+		s.ResponseAppError(w, r, err)
+		return
+	}
+
+	response := EventsBatchResultDto{Results: make([]EventResultDto, len(results))}
+	for i, err := range results {
+		response.Results[i] = EventResultDto{Index: i, Success: err == nil}
+		if err != nil {
+			appErr := app.AsError(err)
+			response.Results[i].Error = appErr.PublicMessage()
+			response.Results[i].Code = appErr.Code
+		}
+	}
+
+	res, err := json.Marshal(response)
+	if err != nil {
+		ResponseError(w, http.StatusInternalServerError, err)
+		return
+	}
+
 	w.Header().Add("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	w.Write(res)
@@ -0,0 +1,712 @@
+package internalhttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	bandit "rotator/internal/alghoritms"
+	"rotator/internal/app"
+	sqlstorage "rotator/internal/storage/sql"
+	"strings"
+	"testing"
+	"time"
+)
+
+// statStorage возвращает заданный результат выборки статистики.
+type statStorage struct {
+	app.Storage
+	stats []sqlstorage.BannerStats
+	err   error
+}
+
+func (s statStorage) GetBannersStat(context.Context, int64, int64, time.Time) ([]sqlstorage.BannerStats, int, error) {
+	return s.stats, 0, s.err
+}
+
+func TestErrorStatuses(t *testing.T) {
+	cases := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"no banners", nil, http.StatusNotFound, app.ErrNoBannersInSlot.Code},
+		{"unknown slot", sqlstorage.ErrNotFound, http.StatusNotFound, app.ErrSlotNotFound.Code},
+		{"storage down", sqlstorage.ErrUnavailable, http.StatusServiceUnavailable, app.ErrStorageUnavailable.Code},
+		{"unexpected", errors.New("boom"), http.StatusInternalServerError, app.ErrInternal.Code},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			handler := Routers(app.New(nopLogger{}, statStorage{err: c.err}, nil))
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/banner/choose",
+				strings.NewReader(`{"slot_id": 1, "social_group_id": 1}`))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			var dto ErrorDto
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &dto))
+			require.Equal(t, c.status, rec.Code)
+			require.Equal(t, c.code, dto.Code)
+			require.NotContains(t, dto.Error, "boom")
+		})
+	}
+
+	t.Run("malformed body", func(t *testing.T) {
+		handler := Routers(app.New(nopLogger{}, statStorage{}, nil))
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/banner/choose", strings.NewReader(`{`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		var dto ErrorDto
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &dto))
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, CodeBadRequest, dto.Code)
+	})
+}
+
+func TestExplainBanner(t *testing.T) {
+	// statStorage без CountDisplay: если объяснение засчитает показ, тест упадет
+	handler := Routers(app.New(nopLogger{}, statStorage{stats: []sqlstorage.BannerStats{
+		{ID: 1, Display: 10, Click: 1},
+		{ID: 2, Display: 10, Click: 5},
+		{ID: 3, Display: 0, Click: 0},
+	}}, nil))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/banner/explain",
+		strings.NewReader(`{"slot_id": 1, "social_group_id": 1}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var dto ExplanationDto
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &dto))
+	require.Equal(t, "ucb1", dto.Strategy)
+	require.Equal(t, int64(3), dto.BannerID)
+	require.Len(t, dto.Candidates, 3)
+
+	require.Equal(t, int64(3), dto.Candidates[0].BannerID)
+	require.True(t, dto.Candidates[0].Unexplored)
+	require.Nil(t, dto.Candidates[0].Score)
+
+	require.Equal(t, int64(2), dto.Candidates[1].BannerID)
+	require.InDelta(t, 0.5, dto.Candidates[1].CTR, 1e-9)
+	require.NotNil(t, dto.Candidates[1].Score)
+	require.Greater(t, *dto.Candidates[1].Score, *dto.Candidates[2].Score)
+
+	t.Run("prior", func(t *testing.T) {
+		alpha, beta := 1.0, 9.0
+		a := app.New(nopLogger{}, statStorage{stats: []sqlstorage.BannerStats{
+			{ID: 1, Display: 10, Click: 5, SlotPriorAlpha: &alpha, SlotPriorBeta: &beta},
+			{ID: 2, Display: 0, Click: 0, PriorAlpha: 2, PriorBeta: 8, SlotPriorAlpha: &alpha, SlotPriorBeta: &beta},
+		}}, nil)
+		a.Prior = bandit.Prior{Alpha: 100}
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/banner/explain",
+			strings.NewReader(`{"slot_id": 1, "social_group_id": 1}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		Routers(a).ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var dto ExplanationDto
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &dto))
+		require.Len(t, dto.Candidates, 2)
+		require.Equal(t, int64(1), dto.Candidates[0].BannerID)
+		// CTR - наблюдаемые 5 кликов на 10 показов, mean - с псевдосчетчиками (5+1)/(10+1+9)
+		require.InDelta(t, 0.5, dto.Candidates[0].CTR, 1e-9)
+		require.InDelta(t, 0.3, dto.Candidates[0].Mean, 1e-9)
+
+		// баннер без показов с псевдосчетчиками оценивается, а не показывается первым
+		require.False(t, dto.Candidates[1].Unexplored)
+		require.Equal(t, int64(0), dto.Candidates[1].Display)
+		require.Equal(t, 3.0, dto.Candidates[1].PriorAlpha)
+		require.Equal(t, 17.0, dto.Candidates[1].PriorBeta)
+		require.Zero(t, dto.Candidates[1].CTR)
+		require.InDelta(t, 0.15, dto.Candidates[1].Mean, 1e-9)
+	})
+}
+
+// priorStorage хранит псевдосчетчики одного слота.
+type priorStorage struct {
+	app.Storage
+	alpha, beta **float64
+}
+
+func (s priorStorage) GetSlotByID(_ context.Context, slotID int64) (*sqlstorage.Slot, error) {
+	if slotID != 1 {
+		return nil, nil
+	}
+
+	return &sqlstorage.Slot{ID: slotID, PriorAlpha: *s.alpha, PriorBeta: *s.beta}, nil
+}
+
+func (s priorStorage) SetSlotPrior(_ context.Context, slotID int64, alpha, beta *float64) error {
+	if slotID != 1 {
+		return sqlstorage.ErrNotFound
+	}
+	*s.alpha, *s.beta = alpha, beta
+
+	return nil
+}
+
+func TestSlotPrior(t *testing.T) {
+	storage := priorStorage{alpha: new(*float64), beta: new(*float64)}
+	a := app.New(nopLogger{}, storage, nil)
+	a.Prior = bandit.Prior{Alpha: 1, Beta: 1}
+	handler := Routers(a)
+
+	do := func(method, url, body string) (*httptest.ResponseRecorder, SlotPriorDto) {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		var dto SlotPriorDto
+		if rec.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &dto))
+		}
+
+		return rec, dto
+	}
+
+	rec, dto := do(http.MethodGet, "/api/v1/slots/1/prior", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, SlotPriorDto{SlotID: 1, Alpha: 1, Beta: 1, Default: true}, dto)
+
+	rec, dto = do(http.MethodPut, "/api/v1/slots/1/prior", `{"alpha": 2, "beta": 50}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, SlotPriorDto{SlotID: 1, Alpha: 2, Beta: 50}, dto)
+
+	_, dto = do(http.MethodGet, "/api/v1/slots/1/prior", "")
+	require.Equal(t, SlotPriorDto{SlotID: 1, Alpha: 2, Beta: 50}, dto)
+
+	t.Run("reset", func(t *testing.T) {
+		rec, dto := do(http.MethodDelete, "/api/v1/slots/1/prior", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.True(t, dto.Default)
+		require.Nil(t, *storage.alpha)
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		rec, _ := do(http.MethodPut, "/api/v1/slots/1/prior", `{"alpha": -1, "beta": 1}`)
+		require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+
+	t.Run("unknown slot", func(t *testing.T) {
+		rec, _ := do(http.MethodGet, "/api/v1/slots/2/prior", "")
+		require.Equal(t, http.StatusNotFound, rec.Code)
+
+		rec, _ = do(http.MethodPut, "/api/v1/slots/2/prior", `{"alpha": 1, "beta": 1}`)
+		require.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+// scheduleStorage хранит окно активности баннера 1 в слоте 1.
+type scheduleStorage struct {
+	app.Storage
+	schedule *sqlstorage.Schedule
+}
+
+func (s scheduleStorage) GetBannerSchedule(_ context.Context, bannerID, slotID int64) (*sqlstorage.Schedule, error) {
+	if bannerID != 1 || slotID != 1 {
+		return nil, nil
+	}
+
+	return s.schedule, nil
+}
+
+func (s scheduleStorage) SetBannerSchedule(_ context.Context, bannerID, slotID int64,
+	schedule sqlstorage.Schedule,
+) error {
+	if bannerID != 1 || slotID != 1 {
+		return sqlstorage.ErrNotFound
+	}
+	*s.schedule = schedule
+
+	return nil
+}
+
+func TestBannerSchedule(t *testing.T) {
+	storage := scheduleStorage{schedule: &sqlstorage.Schedule{}}
+	handler := Routers(app.New(nopLogger{}, storage, nil))
+
+	do := func(method, url, body string) (*httptest.ResponseRecorder, BannerScheduleDto) {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		var dto BannerScheduleDto
+		if rec.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &dto))
+		}
+
+		return rec, dto
+	}
+
+	rec, _ := do(http.MethodPut, "/api/v1/banner-slot/schedule", `{"banner_id": 1, "slot_id": 1,
+		"from": "2024-03-01T00:00:00Z", "weekdays": [1, 5, 7], "hours": [0, 9, 23]}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, sqlstorage.Schedule{
+		From:     time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		Weekdays: 0b1010001,
+		Hours:    1 | 1<<9 | 1<<23,
+	}, *storage.schedule)
+
+	rec, dto := do(http.MethodGet, "/api/v1/banner-slot/schedule?banner_id=1&slot_id=1", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, []int{1, 5, 7}, dto.Weekdays)
+	require.Equal(t, []int{0, 9, 23}, dto.Hours)
+	require.Nil(t, dto.To)
+	require.NotContains(t, rec.Body.String(), `"to"`)
+
+	t.Run("reset", func(t *testing.T) {
+		rec, _ := do(http.MethodPut, "/api/v1/banner-slot/schedule", `{"banner_id": 1, "slot_id": 1}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, sqlstorage.Schedule{}, *storage.schedule)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		rec, _ := do(http.MethodPut, "/api/v1/banner-slot/schedule", `{"banner_id": 1, "slot_id": 1,
+			"from": "2024-03-08T00:00:00Z", "to": "2024-03-01T00:00:00Z"}`)
+		require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		require.Contains(t, rec.Body.String(), "invalid_schedule")
+
+		rec, _ = do(http.MethodPut, "/api/v1/banner-slot/schedule", `{"banner_id": 1, "slot_id": 1, "hours": [24]}`)
+		require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+
+	t.Run("banner not in slot", func(t *testing.T) {
+		rec, _ := do(http.MethodGet, "/api/v1/banner-slot/schedule?banner_id=2&slot_id=1", "")
+		require.Equal(t, http.StatusNotFound, rec.Code)
+
+		rec, _ = do(http.MethodPut, "/api/v1/banner-slot/schedule", `{"banner_id": 2, "slot_id": 1}`)
+		require.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+// statusStorage хранит состояние баннера 1 в слоте 1.
+type statusStorage struct {
+	app.Storage
+	status *string
+}
+
+func (s statusStorage) GetBannerStatus(_ context.Context, bannerID, slotID int64) (string, error) {
+	if bannerID != 1 || slotID != 1 {
+		return "", nil
+	}
+
+	return *s.status, nil
+}
+
+func (s statusStorage) SetBannerStatus(_ context.Context, bannerID, slotID int64, status string) (string, error) {
+	if bannerID != 1 || slotID != 1 {
+		return "", nil
+	}
+
+	previous := *s.status
+	if previous != sqlstorage.BannerArchived {
+		*s.status = status
+	}
+
+	return previous, nil
+}
+
+func TestBannerStatus(t *testing.T) {
+	storage := statusStorage{status: new(string)}
+	*storage.status = sqlstorage.BannerActive
+	handler := Routers(app.New(nopLogger{}, storage, nil))
+
+	do := func(method, url, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodPut, "/api/v1/banner-slot/status", `{"banner_id": 1, "slot_id": 1, "status": "paused"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, sqlstorage.BannerPaused, *storage.status)
+
+	rec = do(http.MethodGet, "/api/v1/banner-slot/status?banner_id=1&slot_id=1", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var dto BannerStatusDto
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &dto))
+	require.Equal(t, BannerStatusDto{BannerToSlotDto: BannerToSlotDto{BannerID: 1, SlotID: 1}, Status: "paused"}, dto)
+
+	t.Run("archived can't be resumed", func(t *testing.T) {
+		rec := do(http.MethodPut, "/api/v1/banner-slot/status", `{"banner_id": 1, "slot_id": 1, "status": "archived"}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		rec = do(http.MethodPut, "/api/v1/banner-slot/status", `{"banner_id": 1, "slot_id": 1, "status": "active"}`)
+		require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		require.Contains(t, rec.Body.String(), "invalid_banner_status")
+		require.Equal(t, sqlstorage.BannerArchived, *storage.status)
+	})
+
+	t.Run("unknown status", func(t *testing.T) {
+		rec := do(http.MethodPut, "/api/v1/banner-slot/status", `{"banner_id": 1, "slot_id": 1, "status": "deleted"}`)
+		require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+
+	t.Run("banner not in slot", func(t *testing.T) {
+		rec := do(http.MethodGet, "/api/v1/banner-slot/status?banner_id=2&slot_id=1", "")
+		require.Equal(t, http.StatusNotFound, rec.Code)
+
+		rec = do(http.MethodPut, "/api/v1/banner-slot/status", `{"banner_id": 2, "slot_id": 1, "status": "paused"}`)
+		require.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+// reportStorage запоминает запрос статистики и возвращает заданные строки.
+type reportStorage struct {
+	app.Storage
+	rows    []sqlstorage.StatisticsRow
+	filter  *sqlstorage.StatisticsFilter
+	groupBy *[]string
+}
+
+func (s reportStorage) GetStatistics(_ context.Context, filter sqlstorage.StatisticsFilter,
+	groupBy []string,
+) ([]sqlstorage.StatisticsRow, error) {
+	*s.filter, *s.groupBy = filter, groupBy
+	return s.rows, nil
+}
+
+func (s reportStorage) GetStatisticsSeries(_ context.Context, filter sqlstorage.StatisticsFilter, groupBy []string,
+	interval string, from, to time.Time,
+) ([]sqlstorage.StatisticsPoint, error) {
+	*s.filter, *s.groupBy = filter, append(groupBy, interval, from.Format(time.RFC3339), to.Format(time.RFC3339))
+
+	points := make([]sqlstorage.StatisticsPoint, len(s.rows))
+	for i, r := range s.rows {
+		points[i] = sqlstorage.StatisticsPoint{Time: from.Add(time.Hour * time.Duration(i)), StatisticsRow: r}
+	}
+
+	return points, nil
+}
+
+func (s reportStorage) ExportStatistics(_ context.Context, filter sqlstorage.StatisticsFilter, interval string,
+	_, _ time.Time, fn func(sqlstorage.StatisticsPoint) error,
+) error {
+	*s.filter, *s.groupBy = filter, []string{interval}
+
+	for i, r := range s.rows {
+		p := sqlstorage.StatisticsPoint{StatisticsRow: r}
+		if interval != "" {
+			p.Time = time.Date(2024, 3, 1, i, 0, 0, 0, time.UTC)
+		}
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func TestGetStats(t *testing.T) {
+	storage := reportStorage{
+		rows: []sqlstorage.StatisticsRow{
+			{BannerID: 1, Display: 100, Click: 10},
+			{BannerID: 2, Display: 0, Click: 0},
+		},
+		filter:  &sqlstorage.StatisticsFilter{},
+		groupBy: &[]string{},
+	}
+	handler := Routers(app.New(nopLogger{}, storage, nil))
+
+	do := func(url string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		return rec
+	}
+
+	rec := do("/api/v1/stats?group_by=banner&slot_id=3")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, sqlstorage.StatisticsFilter{SlotID: 3}, *storage.filter)
+	require.Equal(t, []string{"banner"}, *storage.groupBy)
+
+	var dto StatsDto
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &dto))
+	require.Equal(t, app.DefaultConfidence, dto.Confidence)
+	require.Len(t, dto.Rows, 2)
+	require.InDelta(t, 0.1, dto.Rows[0].CTR, 1e-9)
+	require.InDelta(t, 0.0552, dto.Rows[0].CTRLower, 1e-4)
+	require.InDelta(t, 0.1744, dto.Rows[0].CTRUpper, 1e-4)
+	require.Equal(t, 1.0, dto.Rows[1].CTRUpper)
+	require.NotContains(t, rec.Body.String(), "slot_id")
+
+	t.Run("unknown dimension", func(t *testing.T) {
+		require.Equal(t, http.StatusUnprocessableEntity, do("/api/v1/stats?group_by=country").Code)
+	})
+
+	t.Run("invalid confidence", func(t *testing.T) {
+		require.Equal(t, http.StatusUnprocessableEntity, do("/api/v1/stats?confidence=1").Code)
+	})
+}
+
+func TestGetStatsSeries(t *testing.T) {
+	storage := reportStorage{
+		rows:    []sqlstorage.StatisticsRow{{SlotID: 1, Display: 100, Click: 10}},
+		filter:  &sqlstorage.StatisticsFilter{},
+		groupBy: &[]string{},
+	}
+	handler := Routers(app.New(nopLogger{}, storage, nil))
+
+	do := func(url string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		return rec
+	}
+
+	rec := do("/api/v1/stats/series?group_by=slot&interval=day&from=2024-03-01T00:00:00Z&to=2024-03-08T00:00:00Z")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, []string{"slot", "day", "2024-03-01T00:00:00Z", "2024-03-08T00:00:00Z"}, *storage.groupBy)
+
+	var dto SeriesDto
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &dto))
+	require.Equal(t, "day", dto.Interval)
+	require.Len(t, dto.Points, 1)
+	require.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), dto.Points[0].Time.UTC())
+	require.Equal(t, int64(1), dto.Points[0].SlotID)
+	require.InDelta(t, 0.1, dto.Points[0].CTR, 1e-9)
+
+	t.Run("defaults to last 24 hours", func(t *testing.T) {
+		rec := do("/api/v1/stats/series")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var dto SeriesDto
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &dto))
+		require.Equal(t, "hour", dto.Interval)
+		require.Equal(t, time.Hour*24, dto.To.Sub(dto.From))
+	})
+
+	t.Run("invalid range", func(t *testing.T) {
+		rec := do("/api/v1/stats/series?from=2024-03-08T00:00:00Z&to=2024-03-01T00:00:00Z")
+		require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+		rec = do("/api/v1/stats/series?interval=hour&from=2023-01-01T00:00:00Z&to=2024-03-01T00:00:00Z")
+		require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+
+	t.Run("invalid time", func(t *testing.T) {
+		require.Equal(t, http.StatusUnprocessableEntity, do("/api/v1/stats/series?from=yesterday").Code)
+	})
+}
+
+// seriesStorage строит ряд, как запрос к базе: по точке на каждый период последних трех суток,
+// начало которого попадает в [from, to).
+type seriesStorage struct {
+	app.Storage
+}
+
+func (seriesStorage) GetStatisticsSeries(_ context.Context, _ sqlstorage.StatisticsFilter, _ []string,
+	interval string, from, to time.Time,
+) ([]sqlstorage.StatisticsPoint, error) {
+	step := time.Hour
+	if interval == sqlstorage.IntervalDay {
+		step = time.Hour * 24
+	}
+
+	now := time.Now().UTC()
+	points := make([]sqlstorage.StatisticsPoint, 0)
+	for bucket := now.Add(-time.Hour * 72).Truncate(step); !bucket.After(now); bucket = bucket.Add(step) {
+		if !bucket.Before(from) && bucket.Before(to) {
+			points = append(points, sqlstorage.StatisticsPoint{Time: bucket, StatisticsRow: sqlstorage.StatisticsRow{Display: 1}})
+		}
+	}
+
+	return points, nil
+}
+
+func TestGetStatsSeriesBounds(t *testing.T) {
+	handler := Routers(app.New(nopLogger{}, seriesStorage{}, nil))
+
+	get := func(url string) SeriesDto {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var dto SeriesDto
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &dto))
+
+		return dto
+	}
+
+	t.Run("default is 24 whole hours up to the current one", func(t *testing.T) {
+		dto := get("/api/v1/stats/series")
+		require.Len(t, dto.Points, 24)
+		require.Equal(t, dto.From.UTC(), dto.Points[0].Time.UTC())
+		require.Equal(t, time.Now().UTC().Truncate(time.Hour), dto.Points[23].Time.UTC())
+		require.Equal(t, time.Hour*24, dto.To.Sub(dto.From))
+	})
+
+	t.Run("first period is not dropped", func(t *testing.T) {
+		from := time.Now().UTC().Add(-time.Hour*5 - time.Minute*30)
+		dto := get("/api/v1/stats/series?from=" + from.Format(time.RFC3339))
+		require.Equal(t, from.Truncate(time.Hour), dto.From.UTC())
+		require.Equal(t, from.Truncate(time.Hour), dto.Points[0].Time.UTC())
+		require.Len(t, dto.Points, int(dto.To.Sub(dto.From)/time.Hour))
+	})
+
+	t.Run("days", func(t *testing.T) {
+		dto := get("/api/v1/stats/series?interval=day&from=" + time.Now().UTC().Add(-time.Hour*30).Format(time.RFC3339))
+		require.Equal(t, time.Now().UTC().Add(-time.Hour*30).Truncate(time.Hour*24), dto.From.UTC())
+		require.Equal(t, time.Now().UTC().Truncate(time.Hour*24).Add(time.Hour*24), dto.To.UTC())
+		require.Len(t, dto.Points, int(dto.To.Sub(dto.From)/(time.Hour*24)))
+	})
+}
+
+func TestExportStats(t *testing.T) {
+	storage := reportStorage{
+		rows: []sqlstorage.StatisticsRow{
+			{SlotID: 1, BannerID: 2, SocialGroupID: 3, Display: 100, Click: 10},
+			{SlotID: 1, BannerID: 4, SocialGroupID: 3, Display: 8, Click: 0},
+		},
+		filter:  &sqlstorage.StatisticsFilter{},
+		groupBy: &[]string{},
+	}
+	handler := Routers(app.New(nopLogger{}, storage, nil))
+
+	do := func(url string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		return rec
+	}
+
+	rec := do("/api/v1/stats/export?social_group_id=3&interval=hour&from=2024-03-01T00:00:00Z")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "text/csv", rec.Header().Get("Content-Type"))
+	require.Equal(t, `attachment; filename="statistics.csv"`, rec.Header().Get("Content-Disposition"))
+	require.Equal(t, sqlstorage.StatisticsFilter{SocialGroupID: 3}, *storage.filter)
+	require.Equal(t, "time,slot_id,banner_id,social_group_id,display,click,ctr\n"+
+		"2024-03-01T00:00:00Z,1,2,3,100,10,0.1\n"+
+		"2024-03-01T01:00:00Z,1,4,3,8,0,0\n", rec.Body.String())
+
+	t.Run("parquet", func(t *testing.T) {
+		rec := do("/api/v1/stats/export?format=parquet")
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "application/vnd.apache.parquet", rec.Header().Get("Content-Type"))
+		require.Equal(t, "PAR1", rec.Body.String()[:4])
+	})
+
+	t.Run("date range without interval", func(t *testing.T) {
+		rec := do("/api/v1/stats/export?from=2024-03-01T00:00:00Z")
+		require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		require.Contains(t, rec.Header().Get("Content-Type"), "application/json")
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		require.Equal(t, http.StatusUnprocessableEntity, do("/api/v1/stats/export?format=xlsx").Code)
+	})
+}
+
+// importStorage запоминает загруженные строки и начальную статистику, строки из missing
+// считаются отсутствующими в слоте.
+type importStorage struct {
+	app.Storage
+	rows      *[]sqlstorage.StatisticsImport
+	replace   *bool
+	warmStart *sqlstorage.WarmStart
+	missing   []int
+}
+
+func (s importStorage) ImportStatistics(_ context.Context, rows []sqlstorage.StatisticsImport,
+	replace bool,
+) ([]int, error) {
+	*s.rows, *s.replace = rows, replace
+
+	missing := make([]int, 0, len(s.missing))
+	for _, i := range s.missing {
+		if i < len(rows) {
+			missing = append(missing, i)
+		}
+	}
+
+	return missing, nil
+}
+
+func (s importStorage) GetBannerId(_ context.Context, bannerID int64) (*sqlstorage.Banner, error) {
+	return &sqlstorage.Banner{ID: bannerID}, nil
+}
+
+func (s importStorage) AddBannerToSlot(_ context.Context, _, _ int64, warmStart sqlstorage.WarmStart,
+	_ sqlstorage.Schedule,
+) error {
+	*s.warmStart = warmStart
+	return nil
+}
+
+func TestImportStats(t *testing.T) {
+	storage := importStorage{
+		rows:      &[]sqlstorage.StatisticsImport{},
+		replace:   new(bool),
+		warmStart: &sqlstorage.WarmStart{},
+		missing:   []int{1},
+	}
+	handler := Routers(app.New(nopLogger{}, storage, nil))
+
+	do := func(url, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do("/api/v1/stats/import", `{"rows": [
+		{"slot_id": 1, "banner_id": 2, "social_group_id": 3, "display": 100, "click": 10},
+		{"slot_id": 1, "banner_id": 5, "social_group_id": 3, "display": 10, "click": 1},
+		{"slot_id": 1, "banner_id": 2, "social_group_id": 4, "display": 1, "click": 2}
+	]}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.True(t, *storage.replace)
+	require.Equal(t, []sqlstorage.StatisticsImport{
+		{SlotID: 1, BannerID: 2, SocialGroupID: 3, Display: 100, Click: 10},
+		{SlotID: 1, BannerID: 5, SocialGroupID: 3, Display: 10, Click: 1},
+	}, *storage.rows)
+
+	var dto EventsBatchResultDto
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &dto))
+	require.Len(t, dto.Results, 3)
+	require.True(t, dto.Results[0].Success)
+	require.Equal(t, "banner_not_in_slot", dto.Results[1].Code)
+	require.Equal(t, "invalid_import", dto.Results[2].Code)
+
+	t.Run("add mode", func(t *testing.T) {
+		rec := do("/api/v1/stats/import", `{"mode": "add", "rows": []}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.False(t, *storage.replace)
+	})
+
+	t.Run("unknown mode", func(t *testing.T) {
+		rec := do("/api/v1/stats/import", `{"mode": "merge", "rows": []}`)
+		require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+
+	t.Run("warm start", func(t *testing.T) {
+		rec := do("/api/v1/banner-slot/add", `{"banner_id": 6, "slot_id": 1,
+			"warm_start": {"source": "banner", "banner_id": 2}}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, sqlstorage.WarmStart{
+			Source: sqlstorage.WarmStartBanner, BannerID: 2, Displays: app.DefaultWarmStartDisplays,
+		}, *storage.warmStart)
+	})
+
+	t.Run("warm start without similar banner", func(t *testing.T) {
+		rec := do("/api/v1/banner-slot/add", `{"banner_id": 6, "slot_id": 1, "warm_start": {"source": "banner"}}`)
+		require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		require.Contains(t, rec.Body.String(), "invalid_warm_start")
+	})
+}
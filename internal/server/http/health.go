@@ -0,0 +1,33 @@
+package internalhttp
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Healthz отвечает, пока процесс жив, зависимости не проверяет.
+func (s *ServerHandlers) Healthz(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"status":"ok"}`))
+}
+
+// Readyz отвечает 503, пока недоступна обязательная зависимость.
+func (s *ServerHandlers) Readyz(w http.ResponseWriter, r *http.Request) {
+	readiness := s.app.Readiness(r.Context())
+
+	res, err := json.Marshal(readiness)
+	if err != nil {
+		ResponseError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	code := http.StatusOK
+	if !readiness.Ready {
+		code = http.StatusServiceUnavailable
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(res)
+}
@@ -0,0 +1,75 @@
+package internalhttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"rotator/internal/app"
+	"rotator/internal/events"
+	"testing"
+)
+
+type pingStorage struct {
+	app.Storage
+	err error
+}
+
+func (s pingStorage) Ping(context.Context) error {
+	return s.err
+}
+
+type fakePublisher struct {
+	connected bool
+}
+
+func (fakePublisher) Publish(context.Context, events.Event) error {
+	return nil
+}
+
+func (p fakePublisher) Connected() bool {
+	return p.connected
+}
+
+func TestReadyz(t *testing.T) {
+	cases := []struct {
+		name      string
+		storage   error
+		connected bool
+		mode      app.StatsMode
+		status    int
+	}{
+		{"all up", nil, true, app.StatsDirect, http.StatusOK},
+		{"storage down", errors.New("connection refused"), true, app.StatsDirect, http.StatusServiceUnavailable},
+		{"broker down in direct mode", nil, false, app.StatsDirect, http.StatusOK},
+		{"broker down in events mode", nil, false, app.StatsEvents, http.StatusServiceUnavailable},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			a := app.New(nopLogger{}, pingStorage{err: c.storage}, fakePublisher{connected: c.connected})
+			a.StatsMode = c.mode
+			a.AuthEnabled = true
+
+			rec := httptest.NewRecorder()
+			Routers(a).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			require.Equal(t, c.status, rec.Code)
+
+			var readiness app.Readiness
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &readiness))
+			require.Equal(t, c.status == http.StatusOK, readiness.Ready)
+			require.Contains(t, readiness.Checks, "storage")
+			require.Contains(t, readiness.Checks, "publisher")
+		})
+	}
+
+	t.Run("healthz does not check dependencies", func(t *testing.T) {
+		a := app.New(nopLogger{}, pingStorage{err: errors.New("connection refused")}, nil)
+
+		rec := httptest.NewRecorder()
+		Routers(a).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+	})
+}
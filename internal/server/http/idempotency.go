@@ -0,0 +1,123 @@
+package internalhttp
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"go.uber.org/zap"
+	"io/ioutil"
+	"net/http"
+	"rotator/internal/app"
+)
+
+const (
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+// recordingWriter запоминает статус и тело ответа, чтобы сохранить их для повторов.
+type recordingWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *recordingWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.body.Write(b)
+
+	return w.ResponseWriter.Write(b)
+}
+
+// idempotencyMiddleware для POST и DELETE с заголовком Idempotency-Key выполняет запрос
+// один раз, а на повторы с тем же ключом отдает сохраненный ответ. Ответы 5xx не сохраняются,
+// такой запрос можно повторить.
+func idempotencyMiddleware(next http.Handler, a *app.App) http.Handler {
+	handlers := NewServerHandlers(a)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(HeaderIdempotencyKey)
+		if key == "" || (r.Method != http.MethodPost && r.Method != http.MethodDelete) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			ResponseError(w, http.StatusBadRequest,
+				fmt.Errorf("%s should be at most %d characters", HeaderIdempotencyKey, maxIdempotencyKeyLength))
+			return
+		}
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			ResponseError(w, http.StatusBadRequest, fmt.Errorf("error read body: %w", err))
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		// ключи разных арендаторов и клиентов не должны пересекаться
+		var apiKeyID int64
+		if apiKey := app.APIKeyFromContext(r.Context()); apiKey != nil {
+			apiKeyID = apiKey.ID
+		}
+		key = fmt.Sprintf("%d:%d:%s", app.TenantFromContext(r.Context()), apiKeyID, key)
+
+		saved, err := a.BeginIdempotent(r.Context(), key, requestHash(r, body))
+		if err != nil {
+			handlers.ResponseAppError(w, r, err)
+			return
+		}
+
+		if saved != nil {
+			if saved.ContentType != "" {
+				w.Header().Set("Content-Type", saved.ContentType)
+			}
+			w.Header().Set(HeaderIdempotentReplayed, "true")
+			w.WriteHeader(saved.StatusCode)
+			w.Write(saved.Body)
+			return
+		}
+
+		rec := &recordingWriter{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+
+		// клиент мог уже отключиться, а результат сохранить нужно
+		ctx := context.WithoutCancel(r.Context())
+		status := rec.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		if status >= http.StatusInternalServerError {
+			err = a.ReleaseIdempotent(ctx, key)
+		} else {
+			err = a.FinishIdempotent(ctx, key, status, rec.Header().Get("Content-Type"), rec.body.Bytes())
+		}
+		if err != nil {
+			a.Logger.Error("[-] Failed to store idempotent response", zap.String("key", key), zap.Error(err))
+		}
+	})
+}
+
+// requestHash отпечаток запроса, чтобы один ключ нельзя было использовать для разных запросов.
+func requestHash(r *http.Request, body []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte(r.Method))
+	h.Write([]byte{0})
+	h.Write([]byte(r.URL.RequestURI()))
+	h.Write([]byte{0})
+	h.Write(body)
+
+	return h.Sum(nil)
+}
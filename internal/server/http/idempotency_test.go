@@ -0,0 +1,114 @@
+package internalhttp
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"rotator/internal/app"
+	sqlstorage "rotator/internal/storage/sql"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// idempotencyStorage хранит ключи в памяти и считает клики.
+type idempotencyStorage struct {
+	app.Storage
+	mu     sync.Mutex
+	keys   map[string]*sqlstorage.IdempotencyKey
+	clicks int
+}
+
+func (s *idempotencyStorage) CountTransition(context.Context, int64, int64, int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clicks++
+
+	return nil
+}
+
+func (s *idempotencyStorage) ReserveIdempotencyKey(_ context.Context, key string, requestHash []byte,
+	expiresAt, _ time.Time,
+) (*sqlstorage.IdempotencyKey, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.keys[key]; ok {
+		copied := *existing
+		return &copied, false, nil
+	}
+	s.keys[key] = &sqlstorage.IdempotencyKey{Key: key, RequestHash: requestHash, ExpiresAt: expiresAt}
+
+	return nil, true, nil
+}
+
+func (s *idempotencyStorage) SaveIdempotentResponse(_ context.Context, key string, statusCode int,
+	contentType string, body []byte,
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := s.keys[key]
+	k.StatusCode, k.ContentType, k.Body = statusCode, contentType, body
+
+	return nil
+}
+
+func (s *idempotencyStorage) ReleaseIdempotencyKey(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.keys, key)
+
+	return nil
+}
+
+func TestIdempotency(t *testing.T) {
+	storage := &idempotencyStorage{keys: make(map[string]*sqlstorage.IdempotencyKey)}
+	handler := Routers(app.New(nopLogger{}, storage, nil))
+
+	click := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/banner/transition", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set(HeaderIdempotencyKey, key)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		return rec
+	}
+	body := `{"banner_id": 1, "slot_id": 1, "social_group_id": 1}`
+
+	t.Run("retry is counted once", func(t *testing.T) {
+		first := click("click-1", body)
+		require.Equal(t, http.StatusOK, first.Code)
+		require.Empty(t, first.Header().Get(HeaderIdempotentReplayed))
+
+		retry := click("click-1", body)
+		require.Equal(t, http.StatusOK, retry.Code)
+		require.Equal(t, "true", retry.Header().Get(HeaderIdempotentReplayed))
+		require.Equal(t, 1, storage.clicks)
+	})
+
+	t.Run("key reused for another request", func(t *testing.T) {
+		rec := click("click-1", `{"banner_id": 2, "slot_id": 1, "social_group_id": 1}`)
+		require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		require.Contains(t, rec.Body.String(), app.ErrIdempotencyKeyReused.Code)
+	})
+
+	t.Run("request in progress", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/banner/transition", nil)
+		storage.keys["1:0:click-2"] = &sqlstorage.IdempotencyKey{Key: "1:0:click-2", RequestHash: requestHash(req, []byte(body))}
+		rec := click("click-2", body)
+		require.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("without key", func(t *testing.T) {
+		clicks := storage.clicks
+		click("", body)
+		click("", body)
+		require.Equal(t, clicks+2, storage.clicks)
+	})
+}
@@ -1,6 +1,16 @@
 package internalhttp
 
-import "net/http"
+import (
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"rotator/internal/metrics"
+	"rotator/internal/tracing"
+	"time"
+)
 
 type ResponseWriter struct {
 	http.ResponseWriter
@@ -8,10 +18,85 @@ type ResponseWriter struct {
 	Bytes      int
 }
 
+func (w *ResponseWriter) WriteHeader(code int) {
+	if w.StatusCode == 0 {
+		w.StatusCode = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *ResponseWriter) Write(data []byte) (int, error) {
+	if w.StatusCode == 0 {
+		w.StatusCode = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(data)
+	w.Bytes += n
+
+	return n, err
+}
+
+// status код ответа, обработчик без WriteHeader и Write отвечает 200.
+func (w *ResponseWriter) status() int {
+	if w.StatusCode == 0 {
+		return http.StatusOK
+	}
+
+	return w.StatusCode
+}
+
 func loggingMiddleware(next http.Handler, logger Logger) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		wrt := &ResponseWriter{w, 0, 0}
 		next.ServeHTTP(wrt, r)
-		logger.LogHTTP(r, wrt.StatusCode, wrt.Bytes)
+		logger.LogHTTP(r, wrt.status(), wrt.Bytes)
+	})
+}
+
+// routeTemplate шаблон маршрута запроса, чтобы ID в пути и неизвестные адреса
+// не порождали новые метки и имена спанов.
+func routeTemplate(routes *mux.Router, r *http.Request) string {
+	var match mux.RouteMatch
+	if routes.Match(r, &match) && match.Route != nil {
+		if template, err := match.Route.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+
+	return "unmatched"
+}
+
+// metricsMiddleware считает запросы и их длительность по маршрутам.
+func metricsMiddleware(next http.Handler, routes *mux.Router) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := routeTemplate(routes, r)
+
+		start := time.Now()
+		wrt := &ResponseWriter{w, 0, 0}
+		next.ServeHTTP(wrt, r)
+		metrics.ObserveHTTP(route, r.Method, wrt.status(), time.Since(start))
+	})
+}
+
+// tracingMiddleware начинает спан запроса, продолжая трассу из заголовков traceparent и tracestate.
+func tracingMiddleware(next http.Handler, routes *mux.Router) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := routeTemplate(routes, r)
+
+		ctx := tracing.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracing.Start(ctx, r.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("http.route", route),
+			))
+		defer span.End()
+
+		wrt := &ResponseWriter{w, 0, 0}
+		next.ServeHTTP(wrt, r.WithContext(ctx))
+
+		span.SetAttributes(attribute.Int("http.response.status_code", wrt.status()))
+		if wrt.status() >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(wrt.status()))
+		}
 	})
 }
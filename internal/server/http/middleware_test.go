@@ -0,0 +1,98 @@
+package internalhttp
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"net/http"
+	"net/http/httptest"
+	"rotator/internal/app"
+	"rotator/internal/config"
+	"rotator/internal/events"
+	"rotator/internal/metrics"
+	"rotator/internal/tracing"
+	"strings"
+	"testing"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	a := app.New(nopLogger{}, fakeStorage{}, nil)
+	handler := metricsMiddleware(Routers(a), newRouter(a))
+
+	do := func(method, path string) int {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+
+		return rec.Code
+	}
+
+	t.Run("real status code", func(t *testing.T) {
+		counter := metrics.HTTPRequests.WithLabelValues("/api/v1/banner-slot/add", http.MethodPost, "422")
+		before := testutil.ToFloat64(counter)
+
+		require.Equal(t, http.StatusUnprocessableEntity, do(http.MethodPost, "/api/v1/banner-slot/add"))
+		require.Equal(t, before+1, testutil.ToFloat64(counter))
+	})
+
+	t.Run("unknown path", func(t *testing.T) {
+		counter := metrics.HTTPRequests.WithLabelValues("unmatched", http.MethodGet, "404")
+		before := testutil.ToFloat64(counter)
+
+		do(http.MethodGet, "/wp-admin")
+		require.Equal(t, before+1, testutil.ToFloat64(counter))
+	})
+
+	t.Run("metrics endpoint", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Contains(t, rec.Body.String(), "rotator_http_requests_total")
+	})
+}
+
+// tracePublisher запоминает трассу, в которой публиковалось событие.
+type tracePublisher struct {
+	traceID *trace.TraceID
+}
+
+func (p tracePublisher) Publish(ctx context.Context, _ events.Event) error {
+	*p.traceID = trace.SpanContextFromContext(ctx).TraceID()
+
+	return nil
+}
+
+func TestTracingMiddleware(t *testing.T) {
+	_, err := tracing.Init(context.Background(), config.TracingConf{}, "rotator")
+	require.NoError(t, err)
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	var published trace.TraceID
+	a := app.New(nopLogger{}, fakeStorage{}, tracePublisher{traceID: &published})
+	handler := tracingMiddleware(Routers(a), newRouter(a))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/banner/choose",
+		strings.NewReader(`{"slot_id": 1, "social_group_id": 1}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", published.String())
+
+	names := make([]string, 0)
+	for _, span := range recorder.Ended() {
+		require.Equal(t, published, span.SpanContext().TraceID())
+		names = append(names, span.Name())
+	}
+	require.Contains(t, names, "POST /api/v1/banner/choose")
+	require.Contains(t, names, "App.ChooseBanner")
+}
@@ -0,0 +1,148 @@
+package internalhttp
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+//go:embed openapi.json
+var openAPISpec []byte
+
+var errValidation = errors.New("request validation failed")
+
+func loadOpenAPI() (routers.Router, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(openAPISpec)
+	if err != nil {
+		return nil, fmt.Errorf("can't load openapi spec: %w", err)
+	}
+
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("invalid openapi spec: %w", err)
+	}
+
+	return gorillamux.NewRouter(doc)
+}
+
+func serveOpenAPI(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(openAPISpec)
+}
+
+// validationMiddleware проверяет запрос по спецификации до того, как он попадет в обработчик.
+// Маршруты, которых нет в спецификации, пропускаются как есть.
+func validationMiddleware(next http.Handler, router routers.Router) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, pathParams, err := router.FindRoute(r)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		err = openapi3filter.ValidateRequest(r.Context(), &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: pathParams,
+			Route:      route,
+			Options: &openapi3filter.Options{
+				MultiError:         true,
+				AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+			},
+		})
+		// тело, которое не удалось разобрать, это ошибка запроса, а не валидации
+		var parseErr *openapi3filter.ParseError
+		if errors.As(err, &parseErr) {
+			ResponseError(w, http.StatusBadRequest, fmt.Errorf("can't parse request: %w", parseErr))
+			return
+		}
+
+		if err != nil {
+			ResponseValidationError(w, validationFields(err))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func ResponseValidationError(w http.ResponseWriter, fields []FieldErrorDto) {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.Field
+	}
+
+	ResponseJSON(w, http.StatusUnprocessableEntity, ErrorDto{
+		Success: false,
+		Code:    CodeValidationFailed,
+		Error:   fmt.Sprintf("%s: %s", errValidation, strings.Join(names, ", ")),
+		Fields:  fields,
+	})
+}
+
+// validationFields раскладывает ошибки kin-openapi по полям запроса.
+func validationFields(err error) []FieldErrorDto {
+	fields := make([]FieldErrorDto, 0)
+	collectFields(err, "", &fields)
+
+	sort.SliceStable(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+
+	return fields
+}
+
+func collectFields(err error, prefix string, fields *[]FieldErrorDto) {
+	var multi openapi3.MultiError
+	if errors.As(err, &multi) {
+		for _, e := range multi {
+			collectFields(e, prefix, fields)
+		}
+		return
+	}
+
+	var reqErr *openapi3filter.RequestError
+	if errors.As(err, &reqErr) {
+		if reqErr.Parameter != nil {
+			prefix = reqErr.Parameter.Name
+		}
+
+		if reqErr.Err != nil {
+			collectFields(reqErr.Err, prefix, fields)
+			return
+		}
+
+		*fields = append(*fields, FieldErrorDto{Field: fieldName(prefix, nil), Message: reqErr.Reason})
+		return
+	}
+
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		*fields = append(*fields, FieldErrorDto{
+			Field:   fieldName(prefix, schemaErr.JSONPointer()),
+			Message: schemaErr.Reason,
+		})
+		return
+	}
+
+	*fields = append(*fields, FieldErrorDto{Field: fieldName(prefix, nil), Message: err.Error()})
+}
+
+func fieldName(prefix string, pointer []string) string {
+	parts := make([]string, 0, len(pointer)+1)
+	if prefix != "" {
+		parts = append(parts, prefix)
+	}
+	parts = append(parts, pointer...)
+
+	if len(parts) == 0 {
+		return "body"
+	}
+
+	return strings.Join(parts, ".")
+}
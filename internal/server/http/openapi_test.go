@@ -0,0 +1,109 @@
+package internalhttp
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"rotator/internal/app"
+	sqlstorage "rotator/internal/storage/sql"
+	"strings"
+	"testing"
+	"time"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...zap.Field) {}
+func (nopLogger) Info(string, ...zap.Field)  {}
+func (nopLogger) Error(string, ...zap.Field) {}
+func (nopLogger) Fatal(string, ...zap.Field) {}
+
+// fakeStorage реализует только методы, которые нужны тестам.
+type fakeStorage struct {
+	app.Storage
+}
+
+func (fakeStorage) GetBannersStat(context.Context, int64, int64, time.Time) ([]sqlstorage.BannerStats, int, error) {
+	return []sqlstorage.BannerStats{{ID: 7, Display: 1, Click: 1}}, 1, nil
+}
+
+func (fakeStorage) CountDisplay(context.Context, int64, int64, int64) error {
+	return nil
+}
+
+func TestOpenAPICoversRoutes(t *testing.T) {
+	doc, err := openapi3.NewLoader().LoadFromData(openAPISpec)
+	require.NoError(t, err)
+
+	pathParam := regexp.MustCompile(`\{(\w+):[^}]+\}`)
+	err = newRouter(app.New(nopLogger{}, fakeStorage{}, nil)).Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		tmpl, err := route.GetPathTemplate()
+		require.NoError(t, err)
+		methods, err := route.GetMethods()
+		require.NoError(t, err)
+
+		item := doc.Paths.Find(pathParam.ReplaceAllString(tmpl, "{$1}"))
+		require.NotNil(t, item, tmpl)
+		for _, method := range methods {
+			require.NotNil(t, item.GetOperation(method), method+" "+tmpl)
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestValidation(t *testing.T) {
+	handler := Routers(app.New(nopLogger{}, fakeStorage{}, nil))
+
+	do := func(method, path, body string) (*httptest.ResponseRecorder, ErrorDto) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		var dto ErrorDto
+		if rec.Code >= 400 {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &dto))
+		}
+
+		return rec, dto
+	}
+
+	t.Run("valid choose", func(t *testing.T) {
+		rec, _ := do(http.MethodPost, "/api/v1/banner/choose", `{"slot_id": 1, "social_group_id": 1}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("missing and zero fields", func(t *testing.T) {
+		rec, dto := do(http.MethodPost, "/api/v1/banner/choose", `{"slot_id": 0}`)
+		require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		require.Equal(t, CodeValidationFailed, dto.Code)
+
+		fields := make([]string, len(dto.Fields))
+		for i, f := range dto.Fields {
+			fields[i] = f.Field
+		}
+		require.Equal(t, []string{"slot_id", "social_group_id"}, fields)
+	})
+
+	t.Run("batch item type", func(t *testing.T) {
+		rec, dto := do(http.MethodPost, "/api/v1/events/batch",
+			`[{"type": "view", "banner_id": 1, "slot_id": 1, "social_group_id": 1}]`)
+		require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		require.Len(t, dto.Fields, 1)
+		require.Equal(t, "0.type", dto.Fields[0].Field)
+	})
+
+	t.Run("spec is served", func(t *testing.T) {
+		rec, _ := do(http.MethodGet, "/api/openapi.json", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.JSONEq(t, string(openAPISpec), rec.Body.String())
+	})
+}
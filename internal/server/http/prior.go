@@ -0,0 +1,71 @@
+package internalhttp
+
+import (
+	"net/http"
+	bandit "rotator/internal/alghoritms"
+	"rotator/internal/app"
+)
+
+func slotPriorDto(prior app.SlotPrior) SlotPriorDto {
+	return SlotPriorDto{
+		SlotID:  prior.SlotID,
+		Alpha:   prior.Alpha,
+		Beta:    prior.Beta,
+		Default: prior.Default,
+	}
+}
+
+func (s *ServerHandlers) GetSlotPrior(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		ResponseError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	prior, err := s.app.GetSlotPrior(r.Context(), id)
+	if err != nil {
+		s.ResponseAppError(w, r, err)
+		return
+	}
+
+	ResponseJSON(w, http.StatusOK, slotPriorDto(prior))
+}
+
+func (s *ServerHandlers) SetSlotPrior(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		ResponseError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var dto PriorDto
+	if err := ParsingData(r, &dto); err != nil {
+		ResponseError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	prior, err := s.app.SetSlotPrior(r.Context(), id, &bandit.Prior{Alpha: dto.Alpha, Beta: dto.Beta})
+	if err != nil {
+		s.ResponseAppError(w, r, err)
+		return
+	}
+
+	ResponseJSON(w, http.StatusOK, slotPriorDto(prior))
+}
+
+// ResetSlotPrior возвращает слоту псевдосчетчики по умолчанию.
+func (s *ServerHandlers) ResetSlotPrior(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		ResponseError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	prior, err := s.app.SetSlotPrior(r.Context(), id, nil)
+	if err != nil {
+		s.ResponseAppError(w, r, err)
+		return
+	}
+
+	ResponseJSON(w, http.StatusOK, slotPriorDto(prior))
+}
@@ -0,0 +1,90 @@
+package internalhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"rotator/internal/events"
+	"rotator/internal/ratelimit"
+	"strconv"
+	"time"
+)
+
+// rateLimitRoutes маршруты с ограничением частоты и их бюджеты.
+var rateLimitRoutes = map[string]string{
+	"/api/v1/banner/choose":     ratelimit.RouteChoose,
+	"/api/v1/banner/transition": ratelimit.RouteTransition,
+}
+
+// eventsBatchPath пачка событий расходует бюджеты по каждому клику и показу.
+const eventsBatchPath = "/api/v1/events/batch"
+
+// rateLimitMiddleware ограничивает частоту выбора баннера и переходов по ключу API и IP клиента,
+// чтобы один клиент не мог накрутить переходы и CTR баннера. Стоит после authMiddleware:
+// бюджет берется по проверенному ключу, а не по заголовку запроса.
+func rateLimitMiddleware(next http.Handler, limiter *ratelimit.Limiter) http.Handler {
+	if limiter == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var counts map[string]int
+		if r.URL.Path == eventsBatchPath {
+			// нечитаемую пачку отклонит проверка запроса, ни одно событие не применится
+			counts = batchCounts(r)
+		} else if route, ok := rateLimitRoutes[r.URL.Path]; ok {
+			counts = map[string]int{route: 1}
+		} else {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ip := limiter.ClientIP(r.RemoteAddr, r.Header.Values("X-Forwarded-For"), r.Header.Get("X-Real-IP"))
+		delay, err := limiter.ReserveN(counts, ratelimit.Client(r.Context()), ip, time.Now())
+		if err != nil {
+			writeError(w, http.StatusTooManyRequests, ErrorDto{
+				Success: false,
+				Code:    CodeRateLimited,
+				Error:   err.Error(),
+			})
+			return
+		}
+		if delay > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+			writeError(w, http.StatusTooManyRequests, ErrorDto{
+				Success: false,
+				Code:    CodeRateLimited,
+				Error:   "rate limit exceeded",
+			})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// batchCounts бюджеты пачки событий по типам событий в теле запроса. Тело возвращается
+// в запрос для обработчика.
+func batchCounts(r *http.Request) map[string]int {
+	body, err := ioutil.ReadAll(r.Body)
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return nil
+	}
+
+	var batch []struct {
+		Type events.Type `json:"type"`
+	}
+	if err := json.Unmarshal(body, &batch); err != nil {
+		return nil
+	}
+
+	types := make([]events.Type, len(batch))
+	for i, e := range batch {
+		types[i] = e.Type
+	}
+
+	return ratelimit.Events(types)
+}
@@ -0,0 +1,153 @@
+package internalhttp
+
+import (
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"rotator/internal/app"
+	"rotator/internal/config"
+	"rotator/internal/ratelimit"
+	"strings"
+	"testing"
+)
+
+func TestRateLimit(t *testing.T) {
+	limiter, err := ratelimit.New(config.RateLimitConf{
+		Enabled: true,
+		Choose: config.RateLimitRoute{
+			PerKey: config.RateLimit{RPS: 0.5, Burst: 2},
+			PerIP:  config.RateLimit{RPS: 0.5, Burst: 1},
+		},
+		Transition: config.RateLimitRoute{
+			PerIP: config.RateLimit{RPS: 0.5, Burst: 1},
+		},
+		TrustedProxies: []string{"10.1.0.0/16"},
+	})
+	require.NoError(t, err)
+
+	a := app.New(nopLogger{}, keyStorage{}, nil)
+	handler := limitedRouters(a, limiter)
+
+	do := func(path, key, tenant, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"slot_id": 1, "social_group_id": 1}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = ip + ":1234"
+		if key != "" {
+			req.Header.Set(HeaderAPIKey, key)
+		}
+		if tenant != "" {
+			req.Header.Set(HeaderTenantID, tenant)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	t.Run("per ip", func(t *testing.T) {
+		rec := do("/api/v1/banner/transition", "", "", "10.0.0.1")
+		require.NotEqual(t, http.StatusTooManyRequests, rec.Code)
+
+		rec = do("/api/v1/banner/transition", "", "", "10.0.0.1")
+		require.Equal(t, http.StatusTooManyRequests, rec.Code)
+		require.Equal(t, "2", rec.Header().Get("Retry-After"))
+		require.Contains(t, rec.Body.String(), CodeRateLimited)
+
+		require.NotEqual(t, http.StatusTooManyRequests, do("/api/v1/banner/transition", "", "", "10.0.0.2").Code)
+	})
+
+	t.Run("missing or made up key does not reset budget", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, do("/api/v1/banner/choose", "", "3", "10.0.1.1").Code)
+		require.Equal(t, http.StatusOK, do("/api/v1/banner/choose", "made-up-1", "3", "10.0.1.2").Code)
+		require.Equal(t, http.StatusTooManyRequests, do("/api/v1/banner/choose", "made-up-2", "3", "10.0.1.3").Code)
+		require.Equal(t, http.StatusTooManyRequests, do("/api/v1/banner/choose", "", "3", "10.0.1.4").Code)
+	})
+
+	t.Run("rejected request does not spend other budget", func(t *testing.T) {
+		require.Equal(t, http.StatusTooManyRequests, do("/api/v1/banner/choose", "", "3", "10.0.2.1").Code)
+		require.Equal(t, http.StatusOK, do("/api/v1/banner/choose", "", "4", "10.0.2.1").Code)
+	})
+
+	t.Run("forwarded for from trusted proxy", func(t *testing.T) {
+		req := func(forwardedFor string) int {
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/banner/transition", nil)
+			r.RemoteAddr = "10.1.0.5:1234"
+			r.Header.Set("X-Forwarded-For", forwardedFor)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, r)
+			return rec.Code
+		}
+
+		require.NotEqual(t, http.StatusTooManyRequests, req("192.0.2.1"))
+		require.NotEqual(t, http.StatusTooManyRequests, req("192.0.2.2, 10.1.0.9"))
+		require.Equal(t, http.StatusTooManyRequests, req("192.0.2.1"))
+	})
+
+	t.Run("other routes are not limited", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			require.NotEqual(t, http.StatusTooManyRequests, do("/api/v1/banner/explain", "", "", "10.0.0.1").Code)
+		}
+	})
+
+	t.Run("unauthenticated requests do not get a budget", func(t *testing.T) {
+		a := app.New(nopLogger{}, keyStorage{}, nil)
+		a.AuthEnabled = true
+		handler := limitedRouters(a, limiter)
+
+		for _, key := range []string{"", "made-up-1", "made-up-2"} {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/banner/choose", nil)
+			req.RemoteAddr = "10.0.3.1:1234"
+			req.Header.Set(HeaderAPIKey, key)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			require.Equal(t, http.StatusUnauthorized, rec.Code)
+		}
+	})
+}
+
+func TestRateLimitEventsBatch(t *testing.T) {
+	limiter, err := ratelimit.New(config.RateLimitConf{
+		Enabled:    true,
+		Choose:     config.RateLimitRoute{PerKey: config.RateLimit{RPS: 0.5, Burst: 2}},
+		Transition: config.RateLimitRoute{PerKey: config.RateLimit{RPS: 0.5, Burst: 3}},
+	})
+	require.NoError(t, err)
+
+	a := app.New(nopLogger{}, keyStorage{}, nil)
+	a.StatsMode = app.StatsEvents
+	handler := limitedRouters(a, limiter)
+
+	do := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(HeaderTenantID, "5")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		return rec
+	}
+	click := `{"type": "click", "slot_id": 1, "banner_id": 1, "social_group_id": 1}`
+	display := `{"type": "display", "slot_id": 1, "banner_id": 1, "social_group_id": 1}`
+	batch := func(events ...string) string {
+		return "[" + strings.Join(events, ", ") + "]"
+	}
+
+	rec := do("/api/v1/events/batch", batch(click, click, display))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	// в бюджете переходов остался один клик: пачка из двух отклоняется целиком
+	rec = do("/api/v1/events/batch", batch(click, click))
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.NotEmpty(t, rec.Header().Get("Retry-After"))
+	require.Contains(t, rec.Body.String(), CodeRateLimited)
+
+	require.Equal(t, http.StatusOK,
+		do("/api/v1/banner/transition", `{"banner_id": 1, "slot_id": 1, "social_group_id": 1}`).Code)
+	require.Equal(t, http.StatusTooManyRequests,
+		do("/api/v1/banner/transition", `{"banner_id": 1, "slot_id": 1, "social_group_id": 1}`).Code)
+
+	// пачка больше бюджета не пройдет никогда
+	rec = do("/api/v1/events/batch", batch(display, display, display))
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Empty(t, rec.Header().Get("Retry-After"))
+}
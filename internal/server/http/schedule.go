@@ -0,0 +1,84 @@
+package internalhttp
+
+import (
+	"fmt"
+	"net/http"
+	"rotator/internal/app"
+	"strconv"
+)
+
+func (dto ScheduleDto) schedule() app.Schedule {
+	schedule := app.Schedule{Weekdays: dto.Weekdays, Hours: dto.Hours}
+	if dto.From != nil {
+		schedule.From = *dto.From
+	}
+	if dto.To != nil {
+		schedule.To = *dto.To
+	}
+
+	return schedule
+}
+
+func scheduleDto(schedule app.Schedule) ScheduleDto {
+	dto := ScheduleDto{Weekdays: schedule.Weekdays, Hours: schedule.Hours}
+	if !schedule.From.IsZero() {
+		from := schedule.From.UTC()
+		dto.From = &from
+	}
+	if !schedule.To.IsZero() {
+		to := schedule.To.UTC()
+		dto.To = &to
+	}
+
+	return dto
+}
+
+// parseBannerToSlot banner_id и slot_id из строки запроса.
+func parseBannerToSlot(r *http.Request) (BannerToSlotDto, error) {
+	var dto BannerToSlotDto
+	for name, dst := range map[string]*int64{"banner_id": &dto.BannerID, "slot_id": &dto.SlotID} {
+		id, err := strconv.ParseInt(r.URL.Query().Get(name), 10, 64)
+		if err != nil {
+			return BannerToSlotDto{}, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		*dst = id
+	}
+
+	return dto, nil
+}
+
+func (s *ServerHandlers) GetBannerSchedule(w http.ResponseWriter, r *http.Request) {
+	dto, err := parseBannerToSlot(r)
+	if err != nil {
+		ResponseError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	schedule, err := s.app.GetBannerSchedule(r.Context(), dto.BannerID, dto.SlotID)
+	if err != nil {
+		s.ResponseAppError(w, r, err)
+		return
+	}
+
+	ResponseJSON(w, http.StatusOK, BannerScheduleDto{BannerToSlotDto: dto, ScheduleDto: scheduleDto(schedule)})
+}
+
+// SetBannerSchedule заменяет окно целиком: поля, которых нет в запросе, снимают ограничение.
+func (s *ServerHandlers) SetBannerSchedule(w http.ResponseWriter, r *http.Request) {
+	var dto BannerScheduleDto
+
+	err := ParsingData(r, &dto)
+	if err != nil {
+		ResponseError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	schedule := dto.ScheduleDto.schedule()
+	err = s.app.SetBannerSchedule(r.Context(), dto.BannerID, dto.SlotID, schedule)
+	if err != nil {
+		s.ResponseAppError(w, r, err)
+		return
+	}
+
+	ResponseJSON(w, http.StatusOK, BannerScheduleDto{BannerToSlotDto: dto.BannerToSlotDto, ScheduleDto: scheduleDto(schedule)})
+}
@@ -2,11 +2,15 @@ package internalhttp
 
 import (
 	"context"
+	"errors"
+	"expvar"
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 	"net"
 	"net/http"
 	"rotator/internal/app"
+	"rotator/internal/ratelimit"
 )
 
 type Server struct {
@@ -24,7 +28,7 @@ type Logger interface {
 	LogHTTP(r *http.Request, code, length int)
 }
 
-func NewServer(host, port string, app *app.App, logger Logger) *Server {
+func NewServer(host, port string, limiter *ratelimit.Limiter, app *app.App, logger Logger) *Server {
 	server := &Server{
 		host:   host,
 		port:   port,
@@ -32,9 +36,16 @@ func NewServer(host, port string, app *app.App, logger Logger) *Server {
 		server: nil,
 	}
 
+	// routes только для определения шаблона маршрута в метриках и трассировке
+	routes := newRouter(app)
+
+	handler := limitedRouters(app, limiter)
+	handler = metricsMiddleware(handler, routes)
+	handler = tracingMiddleware(handler, routes)
+
 	httpServ := &http.Server{
 		Addr:    net.JoinHostPort(host, port),
-		Handler: loggingMiddleware(Routers(app), logger),
+		Handler: loggingMiddleware(handler, logger),
 	}
 
 	server.server = httpServ
@@ -44,8 +55,9 @@ func NewServer(host, port string, app *app.App, logger Logger) *Server {
 
 func (s *Server) Start(ctx context.Context) error {
 	s.logger.Info("[+] Staring http server and listen", zap.String("host:", s.host), zap.String("port", s.port))
+	// после Stop ListenAndServe возвращает http.ErrServerClosed, это штатная остановка
 	err := s.server.ListenAndServe()
-	if err != nil {
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		return err
 	}
 
@@ -59,13 +71,56 @@ func (s *Server) Stop(ctx context.Context) error {
 }
 
 func Routers(app *app.App) http.Handler {
+	return limitedRouters(app, nil)
+}
+
+// limitedRouters limiter ограничивает частоту запросов уже аутентифицированных клиентов, nil - без ограничения.
+func limitedRouters(app *app.App, limiter *ratelimit.Limiter) http.Handler {
+	specRouter, err := loadOpenAPI()
+	if err != nil {
+		// спецификация встроена в бинарник, ошибка здесь - ошибка сборки
+		panic(err)
+	}
+
+	handler := validationMiddleware(idempotencyMiddleware(newRouter(app), app), specRouter)
+
+	return authMiddleware(rateLimitMiddleware(handler, limiter), app)
+}
+
+func newRouter(app *app.App) *mux.Router {
 	handlers := NewServerHandlers(app)
 
 	r := mux.NewRouter()
 	r.HandleFunc("/api/v1/banner-slot/add", handlers.AddBannerToSlot).Methods("POST")
 	r.HandleFunc("/api/v1/banner-slot/remove", handlers.RemoveBannerToSlot).Methods("DELETE")
+	r.HandleFunc("/api/v1/banner-slot/schedule", handlers.GetBannerSchedule).Methods("GET")
+	r.HandleFunc("/api/v1/banner-slot/schedule", handlers.SetBannerSchedule).Methods("PUT")
+	r.HandleFunc("/api/v1/banner-slot/status", handlers.GetBannerStatus).Methods("GET")
+	r.HandleFunc("/api/v1/banner-slot/status", handlers.SetBannerStatus).Methods("PUT")
 	r.HandleFunc("/api/v1/banner/transition", handlers.CountTransition).Methods("POST")
 	r.HandleFunc("/api/v1/banner/choose", handlers.ChooseBanner).Methods("POST")
+	r.HandleFunc("/api/v1/banner/explain", handlers.ExplainBanner).Methods("POST")
+	r.HandleFunc("/api/v1/events/batch", handlers.EventsBatch).Methods("POST")
+	r.HandleFunc("/api/v1/stats", handlers.GetStats).Methods("GET")
+	r.HandleFunc("/api/v1/stats/series", handlers.GetStatsSeries).Methods("GET")
+	r.HandleFunc("/api/v1/stats/export", handlers.ExportStats).Methods("GET")
+	r.HandleFunc("/api/v1/stats/import", handlers.ImportStats).Methods("POST")
+	r.HandleFunc("/api/v1/slots/{id:[0-9]+}/prior", handlers.GetSlotPrior).Methods("GET")
+	r.HandleFunc("/api/v1/slots/{id:[0-9]+}/prior", handlers.SetSlotPrior).Methods("PUT")
+	r.HandleFunc("/api/v1/slots/{id:[0-9]+}/prior", handlers.ResetSlotPrior).Methods("DELETE")
+	r.Handle("/debug/vars", expvar.Handler()).Methods("GET")
+	r.Handle("/metrics", promhttp.Handler()).Methods("GET")
+	r.HandleFunc("/api/openapi.json", serveOpenAPI).Methods("GET")
+	r.HandleFunc("/healthz", handlers.Healthz).Methods("GET")
+	r.HandleFunc("/readyz", handlers.Readyz).Methods("GET")
+
+	for path, catalog := range catalogPaths {
+		r.HandleFunc("/api/v1/"+path, handlers.ListCatalogItems(catalog)).Methods("GET")
+		r.HandleFunc("/api/v1/"+path, handlers.CreateCatalogItem(catalog)).Methods("POST")
+		r.HandleFunc("/api/v1/"+path+"/{id:[0-9]+}", handlers.GetCatalogItem(catalog)).Methods("GET")
+		r.HandleFunc("/api/v1/"+path+"/{id:[0-9]+}", handlers.UpdateCatalogItem(catalog)).Methods("PUT")
+		r.HandleFunc("/api/v1/"+path+"/{id:[0-9]+}", handlers.DeleteCatalogItem(catalog)).Methods("DELETE")
+	}
 
 	return r
 }
@@ -0,0 +1,276 @@
+package internalhttp
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"net/http"
+	"rotator/internal/app"
+	"rotator/internal/export"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseStatsQuery group_by - измерения через запятую, фильтры slot_id, banner_id, social_group_id.
+func parseStatsQuery(r *http.Request) (app.StatsQuery, error) {
+	values := r.URL.Query()
+
+	var query app.StatsQuery
+	if groupBy := values.Get("group_by"); groupBy != "" {
+		query.GroupBy = strings.Split(groupBy, ",")
+	}
+
+	filters := []struct {
+		name string
+		dst  *int64
+	}{
+		{"slot_id", &query.SlotID},
+		{"banner_id", &query.BannerID},
+		{"social_group_id", &query.SocialGroupID},
+	}
+	for _, f := range filters {
+		value := values.Get(f.name)
+		if value == "" {
+			continue
+		}
+
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return app.StatsQuery{}, fmt.Errorf("invalid %s: %w", f.name, err)
+		}
+		*f.dst = id
+	}
+
+	if value := values.Get("confidence"); value != "" {
+		confidence, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return app.StatsQuery{}, fmt.Errorf("invalid confidence: %w", err)
+		}
+		query.Confidence = confidence
+	}
+
+	return query, nil
+}
+
+// parseSeriesQuery параметры статистики, шаг interval и период from, to в RFC 3339.
+func parseSeriesQuery(r *http.Request) (app.SeriesQuery, error) {
+	stats, err := parseStatsQuery(r)
+	if err != nil {
+		return app.SeriesQuery{}, err
+	}
+
+	values := r.URL.Query()
+	query := app.SeriesQuery{StatsQuery: stats, Interval: values.Get("interval")}
+	bounds := []struct {
+		name string
+		dst  *time.Time
+	}{
+		{"from", &query.From},
+		{"to", &query.To},
+	}
+	for _, b := range bounds {
+		value := values.Get(b.name)
+		if value == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return app.SeriesQuery{}, fmt.Errorf("invalid %s: %w", b.name, err)
+		}
+		*b.dst = t
+	}
+
+	return query, nil
+}
+
+func statsRowDto(row app.StatsRow) StatsRowDto {
+	return StatsRowDto{
+		SlotID:        row.SlotID,
+		BannerID:      row.BannerID,
+		SocialGroupID: row.SocialGroupID,
+		Display:       row.Display,
+		Click:         row.Click,
+		CTR:           row.CTR,
+		CTRLower:      row.CTRLower,
+		CTRUpper:      row.CTRUpper,
+	}
+}
+
+func (s *ServerHandlers) GetStats(w http.ResponseWriter, r *http.Request) {
+	query, err := parseStatsQuery(r)
+	if err != nil {
+		ResponseError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	stats, err := s.app.GetStats(r.Context(), query)
+	if err != nil {
+		s.ResponseAppError(w, r, err)
+		return
+	}
+
+	result := StatsDto{
+		GroupBy:    stats.GroupBy,
+		Confidence: stats.Confidence,
+		Rows:       make([]StatsRowDto, len(stats.Rows)),
+	}
+	for i, row := range stats.Rows {
+		result.Rows[i] = statsRowDto(row)
+	}
+
+	ResponseJSON(w, http.StatusOK, result)
+}
+
+func (s *ServerHandlers) GetStatsSeries(w http.ResponseWriter, r *http.Request) {
+	query, err := parseSeriesQuery(r)
+	if err != nil {
+		ResponseError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	series, err := s.app.GetStatsSeries(r.Context(), query)
+	if err != nil {
+		s.ResponseAppError(w, r, err)
+		return
+	}
+
+	result := SeriesDto{
+		GroupBy:    series.GroupBy,
+		Confidence: series.Confidence,
+		Interval:   series.Interval,
+		From:       series.From,
+		To:         series.To,
+		Points:     make([]SeriesPointDto, len(series.Points)),
+	}
+	for i, p := range series.Points {
+		result.Points[i] = SeriesPointDto{Time: p.Time, StatsRowDto: statsRowDto(p.StatsRow)}
+	}
+
+	ResponseJSON(w, http.StatusOK, result)
+}
+
+// parseExportQuery формат format (по умолчанию csv), шаг interval (пусто - накопленная
+// статистика), фильтры и период from, to.
+func parseExportQuery(r *http.Request) (string, app.ExportQuery, error) {
+	series, err := parseSeriesQuery(r)
+	if err != nil {
+		return "", app.ExportQuery{}, err
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = export.FormatCSV
+	}
+
+	return format, app.ExportQuery{
+		Interval:      series.Interval,
+		SlotID:        series.SlotID,
+		BannerID:      series.BannerID,
+		SocialGroupID: series.SocialGroupID,
+		From:          series.From,
+		To:            series.To,
+	}, nil
+}
+
+// ExportStats отдает статистику файлом по мере чтения из базы. Заголовки ответа пишутся
+// с первой строкой, поэтому ошибка до нее возвращается обычным JSON.
+func (s *ServerHandlers) ExportStats(w http.ResponseWriter, r *http.Request) {
+	format, query, err := parseExportQuery(r)
+	if err != nil {
+		ResponseError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	contentType, ok := export.ContentType(format)
+	if !ok {
+		ResponseError(w, http.StatusBadRequest, fmt.Errorf("unknown format %q", format))
+		return
+	}
+
+	var writer export.Writer
+	start := func() error {
+		if writer != nil {
+			return nil
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="statistics.%s"`, format))
+		writer, err = export.NewWriter(format, w, query.Interval != "")
+
+		return err
+	}
+
+	err = s.app.ExportStats(r.Context(), query, func(p app.StatsPoint) error {
+		if err := start(); err != nil {
+			return err
+		}
+		return writer.Write(p)
+	})
+	if err == nil {
+		err = start()
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+
+	if err != nil && writer == nil {
+		s.ResponseAppError(w, r, err)
+		return
+	}
+	if err != nil {
+		// часть файла уже отправлена, статус не поменять: обрываем ответ, чтобы клиент не принял его за полный
+		s.app.Logger.Error("[-] Export failed", zap.String("url", r.URL.Path), zap.Error(err))
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// ImportStats загружает накопленные показы и клики. Результат - по строке на каждую строку запроса,
+// как у пачки событий.
+func (s *ServerHandlers) ImportStats(w http.ResponseWriter, r *http.Request) {
+	var dto ImportStatsDto
+
+	err := ParsingData(r, &dto)
+	if err != nil {
+		ResponseError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if len(dto.Rows) > maxImportRows {
+		ResponseError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("import is limited to %d rows", maxImportRows))
+		return
+	}
+
+	if dto.Mode == "" {
+		dto.Mode = app.ImportReplace
+	}
+
+	rows := make([]app.ImportRow, len(dto.Rows))
+	for i, v := range dto.Rows {
+		rows[i] = app.ImportRow{
+			SlotID:        v.SlotID,
+			BannerID:      v.BannerID,
+			SocialGroupID: v.SocialGroupID,
+			Display:       v.Display,
+			Click:         v.Click,
+		}
+	}
+
+	results, err := s.app.ImportStats(r.Context(), dto.Mode, rows)
+	if err != nil {
+		s.ResponseAppError(w, r, err)
+		return
+	}
+
+	response := EventsBatchResultDto{Results: make([]EventResultDto, len(results))}
+	for i, err := range results {
+		response.Results[i] = EventResultDto{Index: i, Success: err == nil}
+		if err != nil {
+			appErr := app.AsError(err)
+			response.Results[i].Error = appErr.PublicMessage()
+			response.Results[i].Code = appErr.Code
+		}
+	}
+
+	ResponseJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,38 @@
+package internalhttp
+
+import "net/http"
+
+func (s *ServerHandlers) GetBannerStatus(w http.ResponseWriter, r *http.Request) {
+	dto, err := parseBannerToSlot(r)
+	if err != nil {
+		ResponseError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	status, err := s.app.GetBannerStatus(r.Context(), dto.BannerID, dto.SlotID)
+	if err != nil {
+		s.ResponseAppError(w, r, err)
+		return
+	}
+
+	ResponseJSON(w, http.StatusOK, BannerStatusDto{BannerToSlotDto: dto, Status: status})
+}
+
+// SetBannerStatus приостанавливает (paused), возобновляет (active) или архивирует баннер в слоте.
+func (s *ServerHandlers) SetBannerStatus(w http.ResponseWriter, r *http.Request) {
+	var dto BannerStatusDto
+
+	err := ParsingData(r, &dto)
+	if err != nil {
+		ResponseError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = s.app.SetBannerStatus(r.Context(), dto.BannerID, dto.SlotID, dto.Status)
+	if err != nil {
+		s.ResponseAppError(w, r, err)
+		return
+	}
+
+	ResponseJSON(w, http.StatusOK, dto)
+}
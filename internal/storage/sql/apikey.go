@@ -0,0 +1,91 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	pgx4 "github.com/jackc/pgx/v4"
+	"time"
+)
+
+// APIKey Ключ доступа к API. Сам ключ не хранится, только его хеш.
+type APIKey struct {
+	ID        int64      `db:"api_key_id"`
+	TenantID  int64      `db:"tenant_id"`
+	Name      string     `db:"name"`
+	Role      string     `db:"role"`
+	CreatedAt time.Time  `db:"created_at"`
+	RevokedAt *time.Time `db:"revoked_at"`
+}
+
+// CreateAPIKey Ключ выдается арендатору из контекста.
+func (s *Storage) CreateAPIKey(ctx context.Context, name, role string, keyHash []byte) (int64, error) {
+	query := `INSERT INTO api_key (name, key_hash, role, tenant_id) VALUES ($1, $2, $3, $4) RETURNING api_key_id`
+
+	var id int64
+	if err := s.conn.QueryRow(ctx, query, name, keyHash, role, TenantFromContext(ctx)).Scan(&id); err != nil {
+		return 0, fmt.Errorf("can't create api key: %w", wrapError(err))
+	}
+
+	return id, nil
+}
+
+// GetAPIKeyByHash Ищет действующий (не отозванный) ключ по хешу.
+func (s *Storage) GetAPIKeyByHash(ctx context.Context, keyHash []byte) (*APIKey, error) {
+	var key APIKey
+
+	query := `
+		SELECT api_key_id, tenant_id, name, role, created_at, revoked_at FROM api_key
+		WHERE key_hash = $1 AND revoked_at IS NULL
+	`
+
+	err := s.conn.QueryRow(ctx, query, keyHash).Scan(
+		&key.ID, &key.TenantID, &key.Name, &key.Role, &key.CreatedAt, &key.RevokedAt)
+	if err == nil {
+		return &key, nil
+	}
+
+	if errors.Is(err, pgx4.ErrNoRows) {
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("cant scan SQL result to struct %w", wrapError(err))
+}
+
+func (s *Storage) ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+	result := make([]APIKey, 0)
+
+	query := `SELECT api_key_id, tenant_id, name, role, created_at, revoked_at FROM api_key ORDER BY api_key_id`
+
+	rows, err := s.conn.Query(ctx, query)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key APIKey
+		if err := rows.Scan(&key.ID, &key.TenantID, &key.Name, &key.Role, &key.CreatedAt, &key.RevokedAt); err != nil {
+			return nil, fmt.Errorf("cant convert result: %w", err)
+		}
+
+		result = append(result, key)
+	}
+
+	return result, rows.Err()
+}
+
+func (s *Storage) RevokeAPIKey(ctx context.Context, id int64) error {
+	query := `UPDATE api_key SET revoked_at = now() WHERE api_key_id = $1 AND revoked_at IS NULL`
+
+	result, err := s.conn.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("can't revoke api key %d: %w", id, wrapError(err))
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("api key %d: %w", id, ErrNotFound)
+	}
+
+	return nil
+}
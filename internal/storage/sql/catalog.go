@@ -0,0 +1,212 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	pgx4 "github.com/jackc/pgx/v4"
+)
+
+const (
+	CatalogBanner      = "banner"
+	CatalogSlot        = "slot"
+	CatalogSocialGroup = "social_group"
+)
+
+// CatalogItem Элемент справочника: баннер, слот или соц.группа.
+type CatalogItem struct {
+	ID          int64  `db:"id"`
+	Description string `db:"description"`
+}
+
+type catalogTable struct {
+	table             string
+	idColumn          string
+	descriptionColumn string
+	// dependents таблицы со ссылками на справочник, чистятся перед удалением
+	dependents []string
+}
+
+var catalogTables = map[string]catalogTable{
+	CatalogBanner: {
+		table:             "banner",
+		idColumn:          "banner_id",
+		descriptionColumn: "banner_description",
+		dependents:        []string{"statistics", "statistics_hourly", "statistics_daily", "banner_to_slot"},
+	},
+	CatalogSlot: {
+		table:             "slot",
+		idColumn:          "slot_id",
+		descriptionColumn: "slot_description",
+		dependents:        []string{"statistics", "statistics_hourly", "statistics_daily", "banner_to_slot"},
+	},
+	CatalogSocialGroup: {
+		table:             "social_group",
+		idColumn:          "social_group_id",
+		descriptionColumn: "social_description",
+		dependents:        []string{"statistics", "statistics_hourly", "statistics_daily"},
+	},
+}
+
+func getCatalogTable(catalog string) (catalogTable, error) {
+	t, ok := catalogTables[catalog]
+	if !ok {
+		return catalogTable{}, fmt.Errorf("%w: %s", ErrUnknownCatalog, catalog)
+	}
+
+	return t, nil
+}
+
+// CreateCatalogItem Добавляет элемент справочника.
+// Для новой соц.группы сразу заводится статистика по всем баннерам в слотах.
+func (s *Storage) CreateCatalogItem(ctx context.Context, catalog, description string) (int64, error) {
+	t, err := getCatalogTable(catalog)
+	if err != nil {
+		return 0, wrapError(err)
+	}
+
+	tx, err := s.conn.BeginTx(ctx, pgx4.TxOptions{
+		IsoLevel:       pgx4.ReadCommitted,
+		AccessMode:     pgx4.ReadWrite,
+		DeferrableMode: pgx4.NotDeferrable,
+	})
+	if err != nil {
+		return 0, wrapError(err)
+	}
+	defer tx.Rollback(ctx)
+
+	tenantID := TenantFromContext(ctx)
+
+	var id int64
+	query := fmt.Sprintf(`INSERT INTO %s (%s, tenant_id) VALUES ($1, $2) RETURNING %s`,
+		t.table, t.descriptionColumn, t.idColumn)
+	if err := tx.QueryRow(ctx, query, description, tenantID).Scan(&id); err != nil {
+		return 0, fmt.Errorf("can't create %s: %w", catalog, wrapError(err))
+	}
+
+	if catalog == CatalogSocialGroup {
+		query = `
+			INSERT INTO statistics (banner_id, social_group_id, slot_id, tenant_id)
+			SELECT banner_id, $1, slot_id, tenant_id FROM banner_to_slot WHERE tenant_id = $2
+		`
+		if _, err := tx.Exec(ctx, query, id, tenantID); err != nil {
+			return 0, fmt.Errorf("can't create statistics for social group %d: %w", id, wrapError(err))
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, wrapError(err)
+	}
+
+	return id, nil
+}
+
+func (s *Storage) GetCatalogItem(ctx context.Context, catalog string, id int64) (*CatalogItem, error) {
+	t, err := getCatalogTable(catalog)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	var item CatalogItem
+	query := fmt.Sprintf(`SELECT %s, %s FROM %s WHERE %s = $1 AND tenant_id = $2`,
+		t.idColumn, t.descriptionColumn, t.table, t.idColumn)
+
+	err = s.conn.QueryRow(ctx, query, id, TenantFromContext(ctx)).Scan(&item.ID, &item.Description)
+	if err == nil {
+		return &item, nil
+	}
+
+	if errors.Is(err, pgx4.ErrNoRows) {
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("cant scan SQL result to struct %w", wrapError(err))
+}
+
+func (s *Storage) ListCatalogItems(ctx context.Context, catalog string) ([]CatalogItem, error) {
+	t, err := getCatalogTable(catalog)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	result := make([]CatalogItem, 0)
+	query := fmt.Sprintf(`SELECT %s, %s FROM %s WHERE tenant_id = $1 ORDER BY %s`,
+		t.idColumn, t.descriptionColumn, t.table, t.idColumn)
+
+	rows, err := s.conn.Query(ctx, query, TenantFromContext(ctx))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item CatalogItem
+		if err := rows.Scan(&item.ID, &item.Description); err != nil {
+			return nil, fmt.Errorf("cant convert result: %w", wrapError(err))
+		}
+
+		result = append(result, item)
+	}
+
+	return result, rows.Err()
+}
+
+func (s *Storage) UpdateCatalogItem(ctx context.Context, catalog string, id int64, description string) error {
+	t, err := getCatalogTable(catalog)
+	if err != nil {
+		return wrapError(err)
+	}
+
+	query := fmt.Sprintf(`UPDATE %s SET %s = $1 WHERE %s = $2 AND tenant_id = $3`,
+		t.table, t.descriptionColumn, t.idColumn)
+
+	result, err := s.conn.Exec(ctx, query, description, id, TenantFromContext(ctx))
+	if err != nil {
+		return fmt.Errorf("can't update %s %d: %w", catalog, id, wrapError(err))
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%s %d: %w", catalog, id, ErrNotFound)
+	}
+
+	return nil
+}
+
+// DeleteCatalogItem Удаляет элемент справочника вместе со статистикой и привязками к слотам.
+func (s *Storage) DeleteCatalogItem(ctx context.Context, catalog string, id int64) error {
+	t, err := getCatalogTable(catalog)
+	if err != nil {
+		return wrapError(err)
+	}
+
+	tx, err := s.conn.BeginTx(ctx, pgx4.TxOptions{
+		IsoLevel:       pgx4.Serializable,
+		AccessMode:     pgx4.ReadWrite,
+		DeferrableMode: pgx4.NotDeferrable,
+	})
+	if err != nil {
+		return wrapError(err)
+	}
+	defer tx.Rollback(ctx)
+
+	tenantID := TenantFromContext(ctx)
+
+	for _, dependent := range t.dependents {
+		query := fmt.Sprintf(`DELETE FROM %s WHERE %s = $1 AND tenant_id = $2`, dependent, t.idColumn)
+		if _, err := tx.Exec(ctx, query, id, tenantID); err != nil {
+			return fmt.Errorf("can't delete %s of %s %d: %w", dependent, catalog, id, wrapError(err))
+		}
+	}
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE %s = $1 AND tenant_id = $2`, t.table, t.idColumn)
+	result, err := tx.Exec(ctx, query, id, tenantID)
+	if err != nil {
+		return fmt.Errorf("can't delete %s %d: %w", catalog, id, wrapError(err))
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%s %d: %w", catalog, id, ErrNotFound)
+	}
+
+	return tx.Commit(ctx)
+}
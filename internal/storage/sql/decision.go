@@ -0,0 +1,51 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	pgx4 "github.com/jackc/pgx/v4"
+	"time"
+)
+
+// Decision запись журнала решений. StrategyParams и Candidates хранятся в JSON.
+type Decision struct {
+	TenantID       int64
+	DecidedAt      time.Time
+	ImpressionID   string
+	SlotID         int64
+	SocialGroupID  int64
+	BannerID       int64
+	Strategy       string
+	StrategyParams []byte
+	TotalDisplay   int64
+	Candidates     []byte
+}
+
+// SaveDecisions Сохраняет пачку решений одним COPY.
+func (s *Storage) SaveDecisions(ctx context.Context, decisions []Decision) error {
+	if len(decisions) == 0 {
+		return nil
+	}
+
+	rows := make([][]interface{}, len(decisions))
+	for i, d := range decisions {
+		rows[i] = []interface{}{
+			d.TenantID, d.DecidedAt, d.ImpressionID, d.SlotID, d.SocialGroupID, d.BannerID,
+			d.Strategy, string(d.StrategyParams), d.TotalDisplay, string(d.Candidates),
+		}
+	}
+
+	_, err := s.conn.CopyFrom(ctx,
+		pgx4.Identifier{"decision_log"},
+		[]string{
+			"tenant_id", "decided_at", "impression_id", "slot_id", "social_group_id", "banner_id",
+			"strategy", "strategy_params", "total_display", "candidates",
+		},
+		pgx4.CopyFromRows(rows),
+	)
+	if err != nil {
+		return fmt.Errorf("can't save decisions: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgconn"
+	pgx4 "github.com/jackc/pgx/v4"
+	"net"
+	"strings"
+)
+
+var (
+	ErrNotFound       = errors.New("not found")
+	ErrAlreadyExists  = errors.New("already exists")
+	ErrUnavailable    = errors.New("storage is unavailable")
+	ErrUnknownCatalog = errors.New("unknown catalog")
+)
+
+// Коды ошибок postgres, см. https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+)
+
+// wrapError помечает ошибки драйвера одной из ошибок пакета, чтобы вызывающий код
+// мог отличить отсутствующую запись от недоступной базы. Исходная ошибка сохраняется.
+func wrapError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrAlreadyExists) || errors.Is(err, ErrUnavailable) {
+		return err
+	}
+
+	if errors.Is(err, pgx4.ErrNoRows) {
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case pgErr.Code == pgUniqueViolation:
+			return fmt.Errorf("%w: %w", ErrAlreadyExists, err)
+		case pgErr.Code == pgForeignKeyViolation:
+			return fmt.Errorf("%w: %w", ErrNotFound, err)
+		// 08 - проблемы соединения, 53 - нехватка ресурсов, 57P - сервер останавливается
+		case strings.HasPrefix(pgErr.Code, "08"), strings.HasPrefix(pgErr.Code, "53"),
+			strings.HasPrefix(pgErr.Code, "57P"):
+			return fmt.Errorf("%w: %w", ErrUnavailable, err)
+		}
+
+		return err
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || pgconn.Timeout(err) || pgconn.SafeToRetry(err) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+
+	return err
+}
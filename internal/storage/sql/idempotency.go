@@ -0,0 +1,105 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	pgx4 "github.com/jackc/pgx/v4"
+	"time"
+)
+
+// IdempotencyKey Ключ идемпотентности и сохраненный ответ на первый запрос с ним.
+// StatusCode == 0, пока первый запрос еще выполняется.
+type IdempotencyKey struct {
+	Key         string
+	RequestHash []byte
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	ExpiresAt   time.Time
+}
+
+// ReserveIdempotencyKey Занимает ключ под новый запрос. Если ключ уже занят и не истек,
+// возвращает существующую запись и false. Ключ, запрос по которому так и не завершился
+// до staleBefore (например, упал сервис), занимается заново.
+func (s *Storage) ReserveIdempotencyKey(ctx context.Context, key string, requestHash []byte,
+	expiresAt, staleBefore time.Time,
+) (*IdempotencyKey, bool, error) {
+	query := `
+		INSERT INTO idempotency_key (idempotency_key, request_hash, expires_at) VALUES ($1, $2, $3)
+		ON CONFLICT (idempotency_key) DO UPDATE SET
+			request_hash = EXCLUDED.request_hash, status_code = NULL, content_type = '',
+			response_body = NULL, created_at = now(), expires_at = EXCLUDED.expires_at
+		WHERE idempotency_key.expires_at < now()
+			OR (idempotency_key.status_code IS NULL AND idempotency_key.created_at < $4)
+		RETURNING idempotency_key
+	`
+
+	var reserved string
+	err := s.conn.QueryRow(ctx, query, key, requestHash, expiresAt, staleBefore).Scan(&reserved)
+	if err == nil {
+		return nil, true, nil
+	}
+
+	if !errors.Is(err, pgx4.ErrNoRows) {
+		return nil, false, fmt.Errorf("can't reserve idempotency key: %w", wrapError(err))
+	}
+
+	query = `
+		SELECT idempotency_key, request_hash, status_code, content_type, response_body, expires_at
+		FROM idempotency_key WHERE idempotency_key = $1
+	`
+
+	var (
+		existing IdempotencyKey
+		status   *int32
+	)
+	err = s.conn.QueryRow(ctx, query, key).Scan(&existing.Key, &existing.RequestHash, &status,
+		&existing.ContentType, &existing.Body, &existing.ExpiresAt)
+	if err != nil {
+		return nil, false, fmt.Errorf("can't get idempotency key: %w", wrapError(err))
+	}
+
+	if status != nil {
+		existing.StatusCode = int(*status)
+	}
+
+	return &existing, false, nil
+}
+
+// SaveIdempotentResponse Сохраняет ответ на запрос, для которого был занят ключ.
+func (s *Storage) SaveIdempotentResponse(ctx context.Context, key string, statusCode int,
+	contentType string, body []byte,
+) error {
+	query := `
+		UPDATE idempotency_key SET status_code = $2, content_type = $3, response_body = $4
+		WHERE idempotency_key = $1
+	`
+
+	_, err := s.conn.Exec(ctx, query, key, statusCode, contentType, body)
+	if err != nil {
+		return fmt.Errorf("can't save idempotent response: %w", wrapError(err))
+	}
+
+	return nil
+}
+
+// ReleaseIdempotencyKey Освобождает ключ, чтобы запрос можно было повторить.
+func (s *Storage) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	_, err := s.conn.Exec(ctx, `DELETE FROM idempotency_key WHERE idempotency_key = $1`, key)
+	if err != nil {
+		return fmt.Errorf("can't release idempotency key: %w", wrapError(err))
+	}
+
+	return nil
+}
+
+// DeleteExpiredIdempotencyKeys Удаляет истекшие ключи, возвращает сколько удалено.
+func (s *Storage) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	result, err := s.conn.Exec(ctx, `DELETE FROM idempotency_key WHERE expires_at < now()`)
+	if err != nil {
+		return 0, fmt.Errorf("can't delete expired idempotency keys: %w", wrapError(err))
+	}
+
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,156 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	pgx4 "github.com/jackc/pgx/v4"
+	"sort"
+	"strings"
+)
+
+// StatisticsImport Накопленные показы и клики баннера в слоте и соц.группе из внешнего источника.
+type StatisticsImport struct {
+	SlotID        int64
+	BannerID      int64
+	SocialGroupID int64
+	Display       int64
+	Click         int64
+}
+
+// ImportStatistics Записывает накопленные счетчики: replace - вместо текущих, иначе прибавляет к ним.
+// Строки, баннера которых нет в слоте, не записываются, возвращаются их индексы в rows.
+// К total_display затронутых слотов прибавляется изменение показов, как при применении событий:
+// показы баннеров, которых уже нет в слоте, из него не пропадают. Часовая и дневная статистика
+// не меняются: у накопленных счетчиков нет времени.
+func (s *Storage) ImportStatistics(ctx context.Context, rows []StatisticsImport, replace bool) ([]int, error) {
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	tx, err := s.conn.BeginTx(ctx, pgx4.TxOptions{
+		IsoLevel:       pgx4.ReadCommitted,
+		AccessMode:     pgx4.ReadWrite,
+		DeferrableMode: pgx4.NotDeferrable,
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer tx.Rollback(ctx)
+
+	values := make([]string, 0, len(rows))
+	args := make([]interface{}, 0, len(rows)*6+1)
+	args = append(args, TenantFromContext(ctx))
+	for i, r := range rows {
+		n := i*6 + 1
+		values = append(values, fmt.Sprintf("($%d::int, $%d::int, $%d::int, $%d::int, $%d::int, $%d::int)",
+			n+1, n+2, n+3, n+4, n+5, n+6))
+		args = append(args, i, r.SlotID, r.BannerID, r.SocialGroupID, r.Display, r.Click)
+	}
+
+	// строки блокируются до обновления, чтобы изменение показов считалось от значения,
+	// которое не поменяет параллельный CountDisplay
+	query := `
+		SELECT s.statistics_id, s.display
+		FROM statistics AS s
+		JOIN (VALUES ` + strings.Join(values, ", ") + `)
+			AS v(idx, slot_id, banner_id, social_group_id, display, click)
+			ON s.slot_id = v.slot_id AND s.banner_id = v.banner_id AND s.social_group_id = v.social_group_id
+		WHERE s.tenant_id = $1
+		ORDER BY s.statistics_id
+		FOR UPDATE OF s
+	`
+	locked, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("can't lock statistics: %w", wrapError(err))
+	}
+
+	before := make(map[int64]int64)
+	for locked.Next() {
+		var id, display int64
+		if err := locked.Scan(&id, &display); err != nil {
+			locked.Close()
+			return nil, fmt.Errorf("cant convert result: %w", wrapError(err))
+		}
+		before[id] = display
+	}
+	locked.Close()
+	if err := locked.Err(); err != nil {
+		return nil, fmt.Errorf("can't lock statistics: %w", wrapError(err))
+	}
+
+	set := "display = v.display, click = v.click"
+	if !replace {
+		set = "display = s.display + v.display, click = s.click + v.click"
+	}
+
+	query = `
+		UPDATE statistics AS s SET ` + set + `
+		FROM (VALUES ` + strings.Join(values, ", ") + `)
+			AS v(idx, slot_id, banner_id, social_group_id, display, click)
+		WHERE s.tenant_id = $1 AND s.slot_id = v.slot_id AND s.banner_id = v.banner_id
+			AND s.social_group_id = v.social_group_id
+		RETURNING v.idx, v.slot_id, s.statistics_id, s.display
+	`
+	result, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("can't import statistics: %w", wrapError(err))
+	}
+
+	imported := make([]bool, len(rows))
+	slotDisplays := make(map[int64]int64)
+	for result.Next() {
+		var idx int
+		var slotID, id, display int64
+		if err := result.Scan(&idx, &slotID, &id, &display); err != nil {
+			result.Close()
+			return nil, fmt.Errorf("cant convert result: %w", wrapError(err))
+		}
+		imported[idx] = true
+		slotDisplays[slotID] += display - before[id]
+	}
+	result.Close()
+	if err := result.Err(); err != nil {
+		return nil, fmt.Errorf("can't import statistics: %w", wrapError(err))
+	}
+
+	missing := make([]int, 0)
+	for i, ok := range imported {
+		if !ok {
+			missing = append(missing, i)
+		}
+	}
+
+	// слоты обновляем в одном порядке, чтобы параллельные транзакции не ловили deadlock
+	slots := make([]int64, 0, len(slotDisplays))
+	for slotID, display := range slotDisplays {
+		if display != 0 {
+			slots = append(slots, slotID)
+		}
+	}
+	sort.Slice(slots, func(i, j int) bool { return slots[i] < slots[j] })
+
+	values = values[:0]
+	args = args[:1]
+	for i, slotID := range slots {
+		values = append(values, fmt.Sprintf("($%d::int, $%d::int)", i*2+2, i*2+3))
+		args = append(args, slotID, slotDisplays[slotID])
+	}
+
+	if len(values) > 0 {
+		query = `
+			UPDATE slot AS sl SET total_display = sl.total_display + v.display
+			FROM (VALUES ` + strings.Join(values, ", ") + `) AS v(slot_id, display)
+			WHERE sl.slot_id = v.slot_id AND sl.tenant_id = $1
+		`
+		_, err = tx.Exec(ctx, query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("can't apply slot total display: %w", wrapError(err))
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, wrapError(err)
+	}
+
+	return missing, nil
+}
@@ -0,0 +1,53 @@
+package sql
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"rotator/internal/metrics"
+)
+
+// poolCollector снимает состояние пула соединений в момент сбора метрик.
+type poolCollector struct {
+	storage *Storage
+
+	acquired, idle, total, max, acquires, acquireDuration, emptyAcquires *prometheus.Desc
+}
+
+// Collector метрики пула соединений с базой.
+func (s *Storage) Collector() prometheus.Collector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(metrics.Namespace, "db_pool", name), help, nil, nil)
+	}
+
+	return &poolCollector{
+		storage:         s,
+		acquired:        desc("acquired_connections", "Connections currently in use."),
+		idle:            desc("idle_connections", "Idle connections."),
+		total:           desc("total_connections", "Open connections."),
+		max:             desc("max_connections", "Maximum pool size."),
+		acquires:        desc("acquires_total", "Successful connection acquires."),
+		acquireDuration: desc("acquire_duration_seconds_total", "Total time spent waiting for a connection."),
+		emptyAcquires:   desc("empty_acquires_total", "Acquires that had to wait for a connection."),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquired
+	ch <- c.idle
+	ch <- c.total
+	ch <- c.max
+	ch <- c.acquires
+	ch <- c.acquireDuration
+	ch <- c.emptyAcquires
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.storage.conn.Stat()
+
+	ch <- prometheus.MustNewConstMetric(c.acquired, prometheus.GaugeValue, float64(s.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(s.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(s.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.max, prometheus.GaugeValue, float64(s.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquires, prometheus.CounterValue, float64(s.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, s.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquires, prometheus.CounterValue, float64(s.EmptyAcquireCount()))
+}
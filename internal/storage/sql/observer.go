@@ -0,0 +1,64 @@
+package sql
+
+import (
+	"context"
+	pgx4 "github.com/jackc/pgx/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"rotator/internal/metrics"
+	"rotator/internal/tracing"
+	"strings"
+	"time"
+)
+
+// queryObserver получает от pgx сообщения о выполненных запросах, учитывает их длительность
+// в метриках и создает для каждого запроса спан. pgx v4 не умеет сообщать о начале запроса,
+// поэтому спан создается задним числом.
+type queryObserver struct{}
+
+func (queryObserver) Log(ctx context.Context, _ pgx4.LogLevel, msg string, data map[string]interface{}) {
+	switch msg {
+	case "Query", "Exec", "CopyFrom":
+	default:
+		return
+	}
+
+	sql, _ := data["sql"].(string)
+	kind := "copy"
+	if msg != "CopyFrom" {
+		kind = statement(sql)
+	}
+
+	end := time.Now()
+	duration, ok := data["time"].(time.Duration)
+	if ok {
+		metrics.StorageDuration.WithLabelValues(kind).Observe(duration.Seconds())
+	}
+
+	_, span := tracing.Start(ctx, "db."+kind,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(end.Add(-duration)),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.statement", sql),
+		))
+	if err, ok := data["err"].(error); ok {
+		tracing.Error(span, err)
+	}
+	span.End(trace.WithTimestamp(end))
+}
+
+// statement тип запроса для метки метрики, сам текст запроса в метку не попадает.
+func statement(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "other"
+	}
+
+	switch word := strings.ToLower(fields[0]); word {
+	case "select", "insert", "update", "delete", "with":
+		return word
+	default:
+		return "other"
+	}
+}
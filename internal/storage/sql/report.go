@@ -0,0 +1,247 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Измерения, по которым группируется статистика.
+const (
+	DimensionSlot        = "slot"
+	DimensionBanner      = "banner"
+	DimensionSocialGroup = "social_group"
+)
+
+// dimensionColumns колонки измерений в порядке вывода.
+var dimensionColumns = []struct {
+	dimension, column string
+}{
+	{DimensionSlot, "slot_id"},
+	{DimensionBanner, "banner_id"},
+	{DimensionSocialGroup, "social_group_id"},
+}
+
+// Шаги временного ряда статистики.
+const (
+	IntervalHour = "hour"
+	IntervalDay  = "day"
+)
+
+// seriesSources строки с началом периода bucket для каждого шага. Часовые строки старше срока
+// хранения уже свернуты в statistics_daily, поэтому почасовой ряд есть только за последние дни.
+var seriesSources = map[string]string{
+	IntervalHour: `
+		SELECT hour AS bucket, tenant_id, slot_id, banner_id, social_group_id, display, click
+		FROM statistics_hourly`,
+	IntervalDay: `
+		SELECT date_trunc('day', hour AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' AS bucket,
+			tenant_id, slot_id, banner_id, social_group_id, display, click
+		FROM statistics_hourly
+		UNION ALL
+		SELECT day::timestamp AT TIME ZONE 'UTC', tenant_id, slot_id, banner_id, social_group_id, display, click
+		FROM statistics_daily`,
+}
+
+// StatisticsFilter нулевое поле - без фильтра по нему.
+type StatisticsFilter struct {
+	SlotID        int64
+	BannerID      int64
+	SocialGroupID int64
+}
+
+// StatisticsRow показы и клики группы. Поля измерений, по которым не группировали, нулевые.
+type StatisticsRow struct {
+	SlotID        int64
+	BannerID      int64
+	SocialGroupID int64
+	Display       int64
+	Click         int64
+}
+
+// StatisticsPoint показы и клики группы за период, который начинается в Time.
+type StatisticsPoint struct {
+	Time time.Time
+	StatisticsRow
+}
+
+// groupColumns колонки для GROUP BY, неизвестное измерение - ошибка.
+func groupColumns(groupBy []string) ([]string, error) {
+	selected := make(map[string]bool, len(groupBy))
+	for _, d := range groupBy {
+		known := false
+		for _, c := range dimensionColumns {
+			known = known || c.dimension == d
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown dimension %q", d)
+		}
+		selected[d] = true
+	}
+
+	columns := make([]string, 0, len(selected))
+	for _, c := range dimensionColumns {
+		if selected[c.dimension] {
+			columns = append(columns, c.column)
+		}
+	}
+
+	return columns, nil
+}
+
+// selectDimensions выражения SELECT для измерений: колонка или 0, если по ней не группируем.
+func selectDimensions(columns []string) string {
+	grouped := make(map[string]bool, len(columns))
+	for _, c := range columns {
+		grouped[c] = true
+	}
+
+	exprs := make([]string, len(dimensionColumns))
+	for i, c := range dimensionColumns {
+		if grouped[c.column] {
+			exprs[i] = c.column
+		} else {
+			exprs[i] = "0"
+		}
+	}
+
+	return strings.Join(exprs, ", ")
+}
+
+// statisticsQuery суммы из statistics по колонкам columns, параметры $1-$4 - арендатор и фильтр.
+func statisticsQuery(columns []string) string {
+	query := fmt.Sprintf(`
+		SELECT %s, COALESCE(sum(display), 0), COALESCE(sum(click), 0) FROM statistics
+		WHERE tenant_id = $1 AND ($2 = 0 OR slot_id = $2) AND ($3 = 0 OR banner_id = $3)
+			AND ($4 = 0 OR social_group_id = $4)
+	`, selectDimensions(columns))
+	if len(columns) > 0 {
+		query += fmt.Sprintf(" GROUP BY %[1]s ORDER BY %[1]s", strings.Join(columns, ", "))
+	}
+
+	return query
+}
+
+// seriesQuery суммы по периодам source и колонкам columns, параметры $1-$4 - арендатор
+// и фильтр, $5 и $6 - границы периода, NULL - без границы.
+func seriesQuery(source string, columns []string) string {
+	group := strings.Join(append([]string{"bucket"}, columns...), ", ")
+
+	return fmt.Sprintf(`
+		SELECT bucket, %s, sum(display), sum(click) FROM (%s) AS t
+		WHERE tenant_id = $1 AND ($2 = 0 OR slot_id = $2) AND ($3 = 0 OR banner_id = $3)
+			AND ($4 = 0 OR social_group_id = $4)
+			AND ($5::timestamptz IS NULL OR bucket >= $5) AND ($6::timestamptz IS NULL OR bucket < $6)
+		GROUP BY %[3]s ORDER BY %[3]s
+	`, selectDimensions(columns), source, group)
+}
+
+// nullTime нулевое время передается в запрос как NULL.
+func nullTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+
+	return t
+}
+
+// GetStatistics Суммы показов и кликов арендатора по группам измерений groupBy.
+// Без группировки возвращается одна строка с итогом.
+func (s *Storage) GetStatistics(ctx context.Context, filter StatisticsFilter, groupBy []string) ([]StatisticsRow, error) {
+	columns, err := groupColumns(groupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]StatisticsRow, 0)
+	err = s.scanStatistics(ctx, statisticsQuery(columns), false, func(p StatisticsPoint) error {
+		result = append(result, p.StatisticsRow)
+		return nil
+	}, TenantFromContext(ctx), filter.SlotID, filter.BannerID, filter.SocialGroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetStatisticsSeries Суммы показов и кликов арендатора по периодам interval в [from, to)
+// и группам измерений groupBy. Периоды без показов и кликов не возвращаются.
+func (s *Storage) GetStatisticsSeries(ctx context.Context, filter StatisticsFilter, groupBy []string,
+	interval string, from, to time.Time,
+) ([]StatisticsPoint, error) {
+	source, ok := seriesSources[interval]
+	if !ok {
+		return nil, fmt.Errorf("unknown interval %q", interval)
+	}
+
+	columns, err := groupColumns(groupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]StatisticsPoint, 0)
+	err = s.scanStatistics(ctx, seriesQuery(source, columns), true, func(p StatisticsPoint) error {
+		result = append(result, p)
+		return nil
+	}, TenantFromContext(ctx), filter.SlotID, filter.BannerID, filter.SocialGroupID, nullTime(from), nullTime(to))
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ExportStatistics Передает в fn по одной строке статистики арендатора по слоту, баннеру и соц.группе:
+// накопленные счетчики, если interval пустой, иначе суммы по периодам interval в [from, to).
+// Нулевая граница периода не ограничивает выгрузку. Строки не собираются в памяти.
+func (s *Storage) ExportStatistics(ctx context.Context, filter StatisticsFilter, interval string,
+	from, to time.Time, fn func(StatisticsPoint) error,
+) error {
+	columns := make([]string, len(dimensionColumns))
+	for i, c := range dimensionColumns {
+		columns[i] = c.column
+	}
+
+	args := []interface{}{TenantFromContext(ctx), filter.SlotID, filter.BannerID, filter.SocialGroupID}
+	if interval == "" {
+		return s.scanStatistics(ctx, statisticsQuery(columns), false, fn, args...)
+	}
+
+	source, ok := seriesSources[interval]
+	if !ok {
+		return fmt.Errorf("unknown interval %q", interval)
+	}
+
+	return s.scanStatistics(ctx, seriesQuery(source, columns), true, fn, append(args, nullTime(from), nullTime(to))...)
+}
+
+// scanStatistics выполняет запрос статистики и передает строки в fn. withTime - первая колонка - начало периода.
+func (s *Storage) scanStatistics(ctx context.Context, query string, withTime bool,
+	fn func(StatisticsPoint) error, args ...interface{},
+) error {
+	rows, err := s.conn.Query(ctx, query, args...)
+	if err != nil {
+		return wrapError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p StatisticsPoint
+		dst := []interface{}{&p.SlotID, &p.BannerID, &p.SocialGroupID, &p.Display, &p.Click}
+		if withTime {
+			dst = append([]interface{}{&p.Time}, dst...)
+		}
+		if err := rows.Scan(dst...); err != nil {
+			return fmt.Errorf("cant convert result: %w", err)
+		}
+		p.Time = p.Time.UTC()
+
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+
+	return wrapError(rows.Err())
+}
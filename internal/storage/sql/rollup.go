@@ -0,0 +1,44 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RollupHourlyStatistics Сворачивает часовые строки за дни раньше before в дневные и удаляет их.
+// before округляется вниз до начала дня по UTC, чтобы в statistics_daily попадали только полные дни.
+// Строки удаляются и переносятся одним запросом, поэтому параллельный запуск не считает их дважды.
+func (s *Storage) RollupHourlyStatistics(ctx context.Context, before time.Time) (int64, error) {
+	query := `
+		WITH moved AS (
+			DELETE FROM statistics_hourly WHERE hour < $1
+			RETURNING tenant_id, slot_id, banner_id, social_group_id, hour, display, click
+		)
+		INSERT INTO statistics_daily (tenant_id, slot_id, banner_id, social_group_id, day, display, click)
+		SELECT tenant_id, slot_id, banner_id, social_group_id, (hour AT TIME ZONE 'UTC')::date,
+			sum(display), sum(click)
+		FROM moved GROUP BY tenant_id, slot_id, banner_id, social_group_id, (hour AT TIME ZONE 'UTC')::date
+		ON CONFLICT (tenant_id, slot_id, banner_id, social_group_id, day) DO UPDATE
+		SET display = statistics_daily.display + excluded.display, click = statistics_daily.click + excluded.click
+	`
+
+	result, err := s.conn.Exec(ctx, query, before.UTC().Truncate(time.Hour*24))
+	if err != nil {
+		return 0, fmt.Errorf("can't rollup hourly statistics: %w", wrapError(err))
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// DeleteDailyStatistics Удаляет дневные строки за дни раньше before.
+func (s *Storage) DeleteDailyStatistics(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM statistics_daily WHERE day < $1::date`
+
+	result, err := s.conn.Exec(ctx, query, before.UTC().Format(time.DateOnly))
+	if err != nil {
+		return 0, fmt.Errorf("can't delete daily statistics: %w", wrapError(err))
+	}
+
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,82 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	pgx4 "github.com/jackc/pgx/v4"
+	"time"
+)
+
+// Schedule Окно активности баннера в слоте: [From, To), нулевое время не ограничивает.
+// Weekdays - маска дней недели (бит 0 - понедельник), Hours - маска часов (бит 0 - 00:00-01:00),
+// 0 - без ограничения. Дни и часы берутся в часовом поясе времени, на которое выбирается баннер.
+type Schedule struct {
+	From     time.Time
+	To       time.Time
+	Weekdays uint8
+	Hours    uint32
+}
+
+// scheduleFilter условие активности связи bs на момент $4 с днем недели $5 и часом $6.
+const scheduleFilter = `
+	(bs.active_from IS NULL OR bs.active_from <= $4) AND (bs.active_to IS NULL OR bs.active_to > $4)
+	AND (bs.weekdays = 0 OR bs.weekdays & (1 << $5::int) <> 0)
+	AND (bs.hours = 0 OR bs.hours & (1 << $6::int) <> 0)
+`
+
+// scheduleArgs момент, день недели (0 - понедельник) и час для scheduleFilter.
+func scheduleArgs(at time.Time) []interface{} {
+	weekday := (int(at.Weekday()) + 6) % 7
+
+	return []interface{}{at, weekday, at.Hour()}
+}
+
+// SetBannerSchedule Задает окно активности баннера в слоте.
+func (s *Storage) SetBannerSchedule(ctx context.Context, bannerID, slotID int64, schedule Schedule) error {
+	query := `
+		UPDATE banner_to_slot SET active_from = $4, active_to = $5, weekdays = $6, hours = $7
+		WHERE banner_id = $1 AND slot_id = $2 AND tenant_id = $3
+	`
+
+	result, err := s.conn.Exec(ctx, query, bannerID, slotID, TenantFromContext(ctx),
+		nullTime(schedule.From), nullTime(schedule.To), int16(schedule.Weekdays), int32(schedule.Hours))
+	if err != nil {
+		return fmt.Errorf("can't set banner schedule: %w", wrapError(err))
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("banner %d in slot %d: %w", bannerID, slotID, ErrNotFound)
+	}
+
+	return nil
+}
+
+// GetBannerSchedule Окно активности баннера в слоте, nil - баннера нет в слоте.
+func (s *Storage) GetBannerSchedule(ctx context.Context, bannerID, slotID int64) (*Schedule, error) {
+	query := `
+		SELECT active_from, active_to, weekdays, hours FROM banner_to_slot
+		WHERE banner_id = $1 AND slot_id = $2 AND tenant_id = $3
+	`
+
+	var from, to *time.Time
+	var weekdays int16
+	var hours int32
+	err := s.conn.QueryRow(ctx, query, bannerID, slotID, TenantFromContext(ctx)).Scan(&from, &to, &weekdays, &hours)
+	if errors.Is(err, pgx4.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't get banner schedule: %w", wrapError(err))
+	}
+
+	schedule := Schedule{Weekdays: uint8(weekdays), Hours: uint32(hours)}
+	if from != nil {
+		schedule.From = *from
+	}
+	if to != nil {
+		schedule.To = *to
+	}
+
+	return &schedule, nil
+}
@@ -0,0 +1,61 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	pgx4 "github.com/jackc/pgx/v4"
+)
+
+// Состояния баннера в слоте. В выборе участвуют только активные баннеры, статистика
+// приостановленных и архивных сохраняется.
+const (
+	BannerActive   = "active"
+	BannerPaused   = "paused"
+	BannerArchived = "archived"
+)
+
+// GetBannerStatus Состояние баннера в слоте, пустая строка - баннера нет в слоте.
+func (s *Storage) GetBannerStatus(ctx context.Context, bannerID, slotID int64) (string, error) {
+	query := `
+		SELECT status FROM banner_to_slot WHERE banner_id = $1 AND slot_id = $2 AND tenant_id = $3
+	`
+
+	var status string
+	err := s.conn.QueryRow(ctx, query, bannerID, slotID, TenantFromContext(ctx)).Scan(&status)
+	if errors.Is(err, pgx4.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("can't get banner status: %w", wrapError(err))
+	}
+
+	return status, nil
+}
+
+// SetBannerStatus Меняет состояние баннера в слоте и возвращает прежнее, пустая строка - баннера
+// нет в слоте. Архивный баннер остается архивным.
+func (s *Storage) SetBannerStatus(ctx context.Context, bannerID, slotID int64, status string) (string, error) {
+	query := `
+		UPDATE banner_to_slot bs SET status = CASE WHEN old.status = $5 THEN old.status ELSE $4 END
+		FROM (
+			SELECT banner_id, slot_id, tenant_id, status FROM banner_to_slot
+			WHERE banner_id = $1 AND slot_id = $2 AND tenant_id = $3
+			FOR UPDATE
+		) old
+		WHERE bs.banner_id = old.banner_id AND bs.slot_id = old.slot_id AND bs.tenant_id = old.tenant_id
+		RETURNING old.status
+	`
+
+	var previous string
+	err := s.conn.QueryRow(ctx, query, bannerID, slotID, TenantFromContext(ctx), status, BannerArchived).
+		Scan(&previous)
+	if errors.Is(err, pgx4.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("can't set banner status: %w", wrapError(err))
+	}
+
+	return previous, nil
+}
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	pgx4 "github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
-	"os"
+	"sort"
+	"strings"
+	"time"
 )
 
 type Storage struct {
@@ -21,10 +23,13 @@ type Banner struct {
 	TotalDisplay int64  `db:"total_display"`
 }
 
+// Slot PriorAlpha и PriorBeta - априорные псевдосчетчики слота, nil - значение по умолчанию.
 type Slot struct {
-	ID           int64  `db:"slot_id"`
-	Description  string `db:"slot_description"`
-	TotalDisplay int64  `db:"total_display"`
+	ID           int64    `db:"slot_id"`
+	Description  string   `db:"slot_description"`
+	TotalDisplay int64    `db:"total_display"`
+	PriorAlpha   *float64 `db:"prior_alpha"`
+	PriorBeta    *float64 `db:"prior_beta"`
 }
 
 type SocialGroup struct {
@@ -32,11 +37,53 @@ type SocialGroup struct {
 	Description string `db:"description"`
 }
 
+// BannerStats PriorAlpha и PriorBeta - начальная статистика баннера (теплый старт),
+// SlotPriorAlpha и SlotPriorBeta - псевдосчетчики слота, nil - значение по умолчанию.
 type BannerStats struct {
-	ID           int64 `db:"banner_id"`
-	Display      int64 `db:"display"`
-	Click        int64 `db:"click"`
-	TotalDisplay int64 `db:"total_display"`
+	ID             int64    `db:"banner_id"`
+	Display        int64    `db:"display"`
+	Click          int64    `db:"click"`
+	TotalDisplay   int64    `db:"total_display"`
+	PriorAlpha     float64  `db:"prior_alpha"`
+	PriorBeta      float64  `db:"prior_beta"`
+	SlotPriorAlpha *float64 `db:"slot_prior_alpha"`
+	SlotPriorBeta  *float64 `db:"slot_prior_beta"`
+}
+
+// StatisticsDelta Приращение счетчиков для одного баннера в слоте и соц.группе.
+// Нулевой TenantID означает арендатора по умолчанию. Hourly - то же приращение по часам,
+// сумма по ним равна Display и Click.
+type StatisticsDelta struct {
+	TenantID      int64
+	SlotID        int64
+	BannerID      int64
+	SocialGroupID int64
+	Display       int64
+	Click         int64
+	Hourly        []HourlyDelta
+}
+
+// HourlyDelta приращение за час. Нулевой Hour - текущий час.
+type HourlyDelta struct {
+	Hour    time.Time
+	Display int64
+	Click   int64
+}
+
+// hourlyUpsert прибавляет строки к statistics_hourly, если строки за час еще нет - создает ее.
+const hourlyUpsert = `
+	ON CONFLICT (tenant_id, slot_id, banner_id, social_group_id, hour) DO UPDATE
+	SET display = statistics_hourly.display + excluded.display, click = statistics_hourly.click + excluded.click
+`
+
+// DeadLetter Событие, которое не удалось опубликовать или применить к статистике.
+type DeadLetter struct {
+	ID            int64     `db:"dead_letter_event_id"`
+	Body          []byte    `db:"payload"`
+	ContentType   string    `db:"content_type"`
+	SchemaVersion int       `db:"schema_version"`
+	Reason        string    `db:"reason"`
+	CreatedAt     time.Time `db:"created_at"`
 }
 
 func New(ctx context.Context, dsn string) *Storage {
@@ -47,9 +94,18 @@ func New(ctx context.Context, dsn string) *Storage {
 }
 
 func (s *Storage) Connect(ctx context.Context) error {
-	conn, err := pgxpool.Connect(ctx, s.dsn)
+	config, err := pgxpool.ParseConfig(s.dsn)
+	if err != nil {
+		return fmt.Errorf("invalid database dsn: %w", err)
+	}
+
+	// pgx сообщает длительность каждого запроса через логгер, из нее строится метрика
+	config.ConnConfig.Logger = queryObserver{}
+	config.ConnConfig.LogLevel = pgx4.LogLevelInfo
+
+	conn, err := pgxpool.ConnectConfig(ctx, config)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Unable to connect database %s", err)
+		return fmt.Errorf("unable to connect database: %w", wrapError(err))
 	}
 
 	s.conn = conn
@@ -61,14 +117,23 @@ func (s *Storage) Close() {
 	s.conn.Close()
 }
 
+// Ping проверяет, что база доступна.
+func (s *Storage) Ping(ctx context.Context) error {
+	if err := s.conn.Ping(ctx); err != nil {
+		return fmt.Errorf("can't ping database: %w", wrapError(err))
+	}
+
+	return nil
+}
+
 func (s *Storage) GetBannerId(ctx context.Context, bannerID int64) (*Banner, error) {
 	var b Banner
 
 	sql := `
-		SELECT banner_id, banner_description, total_display FROM banner WHERE banner_id = $1
+		SELECT banner_id, banner_description, total_display FROM banner WHERE banner_id = $1 AND tenant_id = $2
 	`
 
-	err := s.conn.QueryRow(ctx, sql, bannerID).Scan(
+	err := s.conn.QueryRow(ctx, sql, bannerID, TenantFromContext(ctx)).Scan(
 		&b.ID, &b.Description, &b.TotalDisplay)
 
 	if err == nil {
@@ -86,11 +151,12 @@ func (s *Storage) GetSlotByID(ctx context.Context, slotID int64) (*Slot, error)
 	var slot Slot
 
 	sql := `
-		SELECT slot_id, slot_description, total_display FROM slot WHERE slot_id = $1
+		SELECT slot_id, slot_description, total_display, prior_alpha, prior_beta
+		FROM slot WHERE slot_id = $1 AND tenant_id = $2
 	`
 
-	err := s.conn.QueryRow(ctx, sql, slotID).Scan(
-		&slot.ID, &slot.Description, &slot.TotalDisplay)
+	err := s.conn.QueryRow(ctx, sql, slotID, TenantFromContext(ctx)).Scan(
+		&slot.ID, &slot.Description, &slot.TotalDisplay, &slot.PriorAlpha, &slot.PriorBeta)
 
 	if err == nil {
 		return &slot, nil
@@ -103,14 +169,30 @@ func (s *Storage) GetSlotByID(ctx context.Context, slotID int64) (*Slot, error)
 	return nil, fmt.Errorf("cant scan SQL result to struct %w", err)
 }
 
+// SetSlotPrior Задает псевдосчетчики слота, nil - значение по умолчанию.
+func (s *Storage) SetSlotPrior(ctx context.Context, slotID int64, alpha, beta *float64) error {
+	query := `UPDATE slot SET prior_alpha = $3, prior_beta = $4 WHERE slot_id = $1 AND tenant_id = $2`
+
+	result, err := s.conn.Exec(ctx, query, slotID, TenantFromContext(ctx), alpha, beta)
+	if err != nil {
+		return fmt.Errorf("can't set slot prior: %w", wrapError(err))
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("slot %d: %w", slotID, ErrNotFound)
+	}
+
+	return nil
+}
+
 func (s *Storage) GetSocialGroupByID(ctx context.Context, socialGroupID int64) (*SocialGroup, error) {
 	var socialGroup SocialGroup
 
 	sql := `
-		SELECT social_group_id, social_description FROM social_group WHERE social_group_id = $1
+		SELECT social_group_id, social_description FROM social_group WHERE social_group_id = $1 AND tenant_id = $2
 	`
 
-	err := s.conn.QueryRow(ctx, sql, socialGroupID).Scan(
+	err := s.conn.QueryRow(ctx, sql, socialGroupID, TenantFromContext(ctx)).Scan(
 		&socialGroup.ID, &socialGroup.Description)
 
 	if err == nil {
@@ -124,38 +206,107 @@ func (s *Storage) GetSocialGroupByID(ctx context.Context, socialGroupID int64) (
 	return nil, fmt.Errorf("cant scan SQL result to struct %w", err)
 }
 
+// Источники теплого старта нового баннера в слоте.
+const (
+	WarmStartBanner = "banner"
+	WarmStartSlot   = "slot"
+)
+
+// WarmStart Начальная статистика нового баннера. Пустой Source - без нее, WarmStartBanner - CTR
+// баннера BannerID по всем его слотам, WarmStartSlot - CTR среднего баннера слота. Заимствуется
+// не больше Displays показов, чтобы своя статистика быстро перевешивала чужую. Она пишется
+// в prior_alpha и prior_beta, настоящие показы и клики баннера начинаются с нуля.
+type WarmStart struct {
+	Source   string
+	BannerID int64
+	Displays int64
+}
+
+// warmStartSources показы и клики по соц.группам вместе с начальной статистикой источника,
+// с которых начинает новый баннер. $1 - новый баннер, $2 - слот, $3 - арендатор, $5 - похожий баннер.
+var warmStartSources = map[string]string{
+	WarmStartBanner: `
+		SELECT social_group_id, sum(display + prior_alpha + prior_beta)::float8 AS display,
+			sum(click + prior_alpha)::float8 AS click
+		FROM statistics WHERE banner_id = $5::int AND tenant_id = $3
+		GROUP BY social_group_id
+	`,
+	WarmStartSlot: `
+		SELECT social_group_id, avg(display + prior_alpha + prior_beta)::float8 AS display,
+			avg(click + prior_alpha)::float8 AS click
+		FROM statistics WHERE slot_id = $2 AND tenant_id = $3 AND banner_id <> $1
+		GROUP BY social_group_id
+	`,
+}
+
 // AddBannerToSlot relation banner <-> slot
-func (s *Storage) AddBannerToSlot(ctx context.Context, bannerID, slotID int64) error {
+func (s *Storage) AddBannerToSlot(ctx context.Context, bannerID, slotID int64, warmStart WarmStart,
+	schedule Schedule,
+) error {
 	tx, err := s.conn.BeginTx(ctx, pgx4.TxOptions{
 		IsoLevel:       pgx4.Serializable,
 		AccessMode:     pgx4.ReadWrite,
 		DeferrableMode: pgx4.NotDeferrable,
 	})
 	if err != nil {
-		return err
+		return wrapError(err)
 	}
 	defer tx.Rollback(ctx)
 
+	tenantID := TenantFromContext(ctx)
+
+	// баннер и слот должны принадлежать арендатору, иначе вставлять нечего
 	query := `
-		INSERT INTO banner_to_slot (banner_id, slot_id) VALUES ($1, $2)
+		INSERT INTO banner_to_slot (banner_id, slot_id, tenant_id, active_from, active_to, weekdays, hours)
+		SELECT b.banner_id, s.slot_id, $3, $4, $5, $6, $7 FROM banner b, slot s
+		WHERE b.banner_id = $1 AND s.slot_id = $2 AND b.tenant_id = $3 AND s.tenant_id = $3
 	`
-	_, err = tx.Exec(ctx, query, bannerID, slotID)
+	result, err := tx.Exec(ctx, query, bannerID, slotID, tenantID, nullTime(schedule.From), nullTime(schedule.To),
+		int16(schedule.Weekdays), int32(schedule.Hours))
 	if err != nil {
-		return err
+		return wrapError(err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("banner %d or slot %d: %w", bannerID, slotID, ErrNotFound)
 	}
 
 	query = `
-		INSERT INTO statistics (banner_id, social_group_id, slot_id) SELECT banner_id, social_group_id, slot_id 
-		FROM banner_to_slot CROSS JOIN social_group WHERE banner_id = $1 AND slot_id = $2
+		INSERT INTO statistics (banner_id, social_group_id, slot_id, tenant_id)
+		SELECT bs.banner_id, g.social_group_id, bs.slot_id, bs.tenant_id
+		FROM banner_to_slot bs JOIN social_group g ON g.tenant_id = bs.tenant_id
+		WHERE bs.banner_id = $1 AND bs.slot_id = $2 AND bs.tenant_id = $3
 	`
+	args := []interface{}{bannerID, slotID, tenantID}
+
+	if source, ok := warmStartSources[warmStart.Source]; ok {
+		// CTR источника переносится на не больше чем $4 показов, соц.группа без статистики
+		// у источника начинает без начальной статистики
+		query = `
+			INSERT INTO statistics (banner_id, social_group_id, slot_id, tenant_id, prior_alpha, prior_beta)
+			SELECT bs.banner_id, g.social_group_id, bs.slot_id, bs.tenant_id, seed.display * seed.ctr,
+				seed.display * (1 - seed.ctr)
+			FROM banner_to_slot bs JOIN social_group g ON g.tenant_id = bs.tenant_id
+			LEFT JOIN (` + source + `) src ON src.social_group_id = g.social_group_id
+			CROSS JOIN LATERAL (
+				SELECT LEAST(COALESCE(src.display, 0), $4::int) AS display,
+					COALESCE(src.click / NULLIF(src.display, 0), 0) AS ctr
+			) seed
+			WHERE bs.banner_id = $1 AND bs.slot_id = $2 AND bs.tenant_id = $3
+		`
+		args = append(args, warmStart.Displays)
+		if warmStart.Source == WarmStartBanner {
+			args = append(args, warmStart.BannerID)
+		}
+	}
 
-	_, err = tx.Exec(ctx, query, bannerID, slotID)
+	_, err = tx.Exec(ctx, query, args...)
 	if err != nil {
-		return err
+		return wrapError(err)
 	}
 
 	if err = tx.Commit(ctx); err != nil {
-		return err
+		return wrapError(err)
 	}
 
 	return nil
@@ -168,29 +319,34 @@ func (s *Storage) RemoveBannerFromSlot(ctx context.Context, bannerID, slotID int
 		DeferrableMode: pgx4.NotDeferrable,
 	})
 	if err != nil {
-		return err
+		return wrapError(err)
 	}
 	defer tx.Rollback(ctx)
 
+	tenantID := TenantFromContext(ctx)
+
 	query := `
-		DELETE FROM banner_to_slot WHERE banner_id = $1 AND slot_id = $2
+		DELETE FROM banner_to_slot WHERE banner_id = $1 AND slot_id = $2 AND tenant_id = $3
 	`
-	_, err = tx.Exec(ctx, query, bannerID, slotID)
+	result, err := tx.Exec(ctx, query, bannerID, slotID, tenantID)
 	if err != nil {
-		return err
+		return wrapError(err)
 	}
 
-	query = `
-		DELETE FROM statistics WHERE banner_id = $1 AND slot_id = $2
-	`
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("banner %d in slot %d: %w", bannerID, slotID, ErrNotFound)
+	}
 
-	_, err = tx.Exec(ctx, query, bannerID, slotID)
-	if err != nil {
-		return err
+	// часовая и дневная статистика уходят вместе со счетчиками, иначе попадут в ряды
+	for _, table := range []string{"statistics", "statistics_hourly", "statistics_daily"} {
+		query = `DELETE FROM ` + table + ` WHERE banner_id = $1 AND slot_id = $2 AND tenant_id = $3`
+		if _, err = tx.Exec(ctx, query, bannerID, slotID, tenantID); err != nil {
+			return wrapError(err)
+		}
 	}
 
 	if err = tx.Commit(ctx); err != nil {
-		return err
+		return wrapError(err)
 	}
 
 	return nil
@@ -198,17 +354,24 @@ func (s *Storage) RemoveBannerFromSlot(ctx context.Context, bannerID, slotID int
 
 // CountTransition Регистрирует переход
 func (s *Storage) CountTransition(ctx context.Context, bannerID, slotID, socialGroupID int64) error {
-	query := `UPDATE statistics SET click = click + 1
-		WHERE slot_id = $1 AND banner_id = $2 AND social_group_id = $3`
-
-	result, err := s.conn.Exec(ctx, query, bannerID, slotID, socialGroupID)
+	query := `
+		WITH updated AS (
+			UPDATE statistics SET click = click + 1
+			WHERE slot_id = $1 AND banner_id = $2 AND social_group_id = $3 AND tenant_id = $4
+			RETURNING tenant_id, slot_id, banner_id, social_group_id
+		)
+		INSERT INTO statistics_hourly (tenant_id, slot_id, banner_id, social_group_id, hour, display, click)
+		SELECT tenant_id, slot_id, banner_id, social_group_id, date_trunc('hour', now()), 0, 1 FROM updated
+	` + hourlyUpsert
+
+	result, err := s.conn.Exec(ctx, query, slotID, bannerID, socialGroupID, TenantFromContext(ctx))
 	if err != nil {
-		return fmt.Errorf("can't count transition slot %d banner = %d social group %d: %w", slotID, bannerID, socialGroupID, err)
+		return fmt.Errorf("can't count transition slot %d banner = %d social group %d: %w",
+			slotID, bannerID, socialGroupID, wrapError(err))
 	}
 
-	count := result.RowsAffected()
-	if count == 0 {
-		return err
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("statistics slot %d banner %d social group %d: %w", slotID, bannerID, socialGroupID, ErrNotFound)
 	}
 
 	return nil
@@ -222,52 +385,71 @@ func (s *Storage) CountDisplay(ctx context.Context, bannerID, slotID, socialGrou
 		DeferrableMode: pgx4.NotDeferrable,
 	})
 	if err != nil {
-		return err
+		return wrapError(err)
 	}
 	defer tx.Rollback(ctx)
 
 	query := `
-		UPDATE statistics SET display = display + 1 WHERE slot_id = $1 AND banner_id = $2 AND social_group_id = $3
-	`
-	_, err = tx.Exec(ctx, query, bannerID, slotID, socialGroupID)
+		WITH updated AS (
+			UPDATE statistics SET display = display + 1
+			WHERE slot_id = $1 AND banner_id = $2 AND social_group_id = $3 AND tenant_id = $4
+			RETURNING tenant_id, slot_id, banner_id, social_group_id
+		)
+		INSERT INTO statistics_hourly (tenant_id, slot_id, banner_id, social_group_id, hour, display, click)
+		SELECT tenant_id, slot_id, banner_id, social_group_id, date_trunc('hour', now()), 1, 0 FROM updated
+	` + hourlyUpsert
+	result, err := tx.Exec(ctx, query, slotID, bannerID, socialGroupID, TenantFromContext(ctx))
 	if err != nil {
-		return err
+		return wrapError(err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("statistics slot %d banner %d social group %d: %w", slotID, bannerID, socialGroupID, ErrNotFound)
 	}
 
 	query = `
-		UPDATE slot SET total_display = total_display + 1 WHERE slot_id = $1
+		UPDATE slot SET total_display = total_display + 1 WHERE slot_id = $1 AND tenant_id = $2
 	`
 
-	_, err = tx.Exec(ctx, query, slotID)
+	_, err = tx.Exec(ctx, query, slotID, TenantFromContext(ctx))
 	if err != nil {
-		return err
+		return wrapError(err)
 	}
 
 	if err = tx.Commit(ctx); err != nil {
-		return err
+		return wrapError(err)
 	}
 
 	return nil
 }
 
 // GetBannersStat Выбирает баннеры с их статистиками
-// которые могут быть показаны в указанном слоте и для указанной соц.группы.
-func (s *Storage) GetBannersStat(ctx context.Context, slotID, socialGroupID int64) ([]BannerStats, int, error) {
+// которые могут быть показаны в указанном слоте и для указанной соц.группы в момент at:
+// только активные баннеры, у которых at попадает в расписание.
+func (s *Storage) GetBannersStat(ctx context.Context, slotID, socialGroupID int64,
+	at time.Time,
+) ([]BannerStats, int, error) {
 	result := make([]BannerStats, 0)
 
 	query := `
-		SELECT banner_id, display, click FROM statistics WHERE slot_id = $1 AND social_group_id = $2
-	`
-
-	rows, err := s.conn.Query(ctx, query, slotID, socialGroupID)
+		SELECT st.banner_id, st.display, st.click, st.prior_alpha, st.prior_beta
+		FROM statistics st
+		JOIN banner_to_slot bs
+			ON bs.banner_id = st.banner_id AND bs.slot_id = st.slot_id AND bs.tenant_id = st.tenant_id
+		WHERE st.slot_id = $1 AND st.social_group_id = $2 AND st.tenant_id = $3 AND bs.status = 'active'
+			AND ` + scheduleFilter
+
+	tenantID := TenantFromContext(ctx)
+	args := append([]interface{}{slotID, socialGroupID, tenantID}, scheduleArgs(at)...)
+	rows, err := s.conn.Query(ctx, query, args...)
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, wrapError(err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
 		var b BannerStats
-		if err := rows.Scan(&b.ID, &b.Display, &b.Click); err != nil {
+		if err := rows.Scan(&b.ID, &b.Display, &b.Click, &b.PriorAlpha, &b.PriorBeta); err != nil {
 			return nil, 0, fmt.Errorf("cant convert result: %w", err)
 		}
 
@@ -275,12 +457,279 @@ func (s *Storage) GetBannersStat(ctx context.Context, slotID, socialGroupID int6
 	}
 
 	var totalDisplay int
-	query = `SELECT total_display FROM slot WHERE slot_id = $1`
-	err = s.conn.QueryRow(ctx, query, slotID).Scan(&totalDisplay)
+	var priorAlpha, priorBeta *float64
+	query = `SELECT total_display, prior_alpha, prior_beta FROM slot WHERE slot_id = $1 AND tenant_id = $2`
+	err = s.conn.QueryRow(ctx, query, slotID, tenantID).Scan(&totalDisplay, &priorAlpha, &priorBeta)
+	if err != nil {
+		return nil, 0, fmt.Errorf("slot %d: %w", slotID, wrapError(err))
+	}
 
-	if errors.Is(err, pgx4.ErrNoRows) {
-		return nil, 0, nil
+	for i := range result {
+		result[i].SlotPriorAlpha, result[i].SlotPriorBeta = priorAlpha, priorBeta
+	}
+
+	return result, totalDisplay, nil
+}
+
+// ApplyStatistics Прибавляет накопленные показы и клики к статистике одной транзакцией.
+// Возвращает приращения, для которых не нашлось строки в statistics. Если задан deadLetters,
+// события этих приращений сохраняются в dead letter в той же транзакции, чтобы повтор пачки
+// после ошибки не прибавил уже примененные приращения второй раз.
+func (s *Storage) ApplyStatistics(ctx context.Context, deltas []StatisticsDelta,
+	deadLetters func(missing []StatisticsDelta) []DeadLetter,
+) ([]StatisticsDelta, error) {
+	if len(deltas) == 0 {
+		return nil, nil
+	}
+
+	tx, err := s.conn.BeginTx(ctx, pgx4.TxOptions{
+		IsoLevel:       pgx4.ReadCommitted,
+		AccessMode:     pgx4.ReadWrite,
+		DeferrableMode: pgx4.NotDeferrable,
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer tx.Rollback(ctx)
+
+	values := make([]string, 0, len(deltas))
+	args := make([]interface{}, 0, len(deltas)*7)
+	for i, d := range deltas {
+		n := i * 7
+		values = append(values, fmt.Sprintf("($%d::int, $%d::int, $%d::int, $%d::int, $%d::int, $%d::int, $%d::int)",
+			n+1, n+2, n+3, n+4, n+5, n+6, n+7))
+
+		tenantID := d.TenantID
+		if tenantID == 0 {
+			tenantID = DefaultTenant
+		}
+		args = append(args, i, tenantID, d.SlotID, d.BannerID, d.SocialGroupID, d.Display, d.Click)
+	}
+
+	query := `
+		UPDATE statistics AS s SET display = s.display + v.display, click = s.click + v.click
+		FROM (VALUES ` + strings.Join(values, ", ") + `)
+			AS v(idx, tenant_id, slot_id, banner_id, social_group_id, display, click)
+		WHERE s.tenant_id = v.tenant_id AND s.slot_id = v.slot_id AND s.banner_id = v.banner_id
+			AND s.social_group_id = v.social_group_id
+		RETURNING v.idx
+	`
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("can't apply statistics: %w", wrapError(err))
+	}
+
+	applied := make([]bool, len(deltas))
+	for rows.Next() {
+		var idx int
+		if err := rows.Scan(&idx); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("cant convert result: %w", wrapError(err))
+		}
+		applied[idx] = true
 	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("can't apply statistics: %w", wrapError(err))
+	}
+
+	// слот арендатора: события разных арендаторов приходят в одной пачке
+	type tenantSlot struct {
+		tenantID int64
+		slotID   int64
+	}
+
+	missing := make([]StatisticsDelta, 0)
+	slotDisplays := make(map[tenantSlot]int64)
+	hourly := make([]StatisticsDelta, 0, len(deltas))
+	for i, d := range deltas {
+		if !applied[i] {
+			missing = append(missing, d)
+			continue
+		}
+		tenantID := d.TenantID
+		if tenantID == 0 {
+			tenantID = DefaultTenant
+		}
+		slotDisplays[tenantSlot{tenantID: tenantID, slotID: d.SlotID}] += d.Display
+		hourly = append(hourly, d)
+	}
+
+	if err := applyHourly(ctx, tx, hourly); err != nil {
+		return nil, err
+	}
+
+	if deadLetters != nil && len(missing) > 0 {
+		if err := copyDeadLetters(ctx, tx, deadLetters(missing)); err != nil {
+			return nil, err
+		}
+	}
+
+	// слоты обновляем в одном порядке, чтобы параллельные транзакции не ловили deadlock
+	slots := make([]tenantSlot, 0, len(slotDisplays))
+	for slot, display := range slotDisplays {
+		if display > 0 {
+			slots = append(slots, slot)
+		}
+	}
+	sort.Slice(slots, func(i, j int) bool {
+		if slots[i].slotID != slots[j].slotID {
+			return slots[i].slotID < slots[j].slotID
+		}
+		return slots[i].tenantID < slots[j].tenantID
+	})
+
+	values = values[:0]
+	args = args[:0]
+	for i, slot := range slots {
+		values = append(values, fmt.Sprintf("($%d::int, $%d::int, $%d::int)", i*3+1, i*3+2, i*3+3))
+		args = append(args, slot.tenantID, slot.slotID, slotDisplays[slot])
+	}
+
+	if len(values) > 0 {
+		query = `
+			UPDATE slot AS s SET total_display = s.total_display + v.display
+			FROM (VALUES ` + strings.Join(values, ", ") + `) AS v(tenant_id, slot_id, display)
+			WHERE s.slot_id = v.slot_id AND s.tenant_id = v.tenant_id
+		`
+		_, err = tx.Exec(ctx, query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("can't apply slot total display: %w", wrapError(err))
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, wrapError(err)
+	}
+
+	return missing, nil
+}
+
+// applyHourly прибавляет часовые приращения в statistics_hourly. Без разбивки по часам
+// приращение целиком относится к текущему часу.
+func applyHourly(ctx context.Context, tx pgx4.Tx, deltas []StatisticsDelta) error {
+	currentHour := time.Now().UTC().Truncate(time.Hour)
+
+	values := make([]string, 0, len(deltas))
+	args := make([]interface{}, 0, len(deltas)*7)
+	for _, d := range deltas {
+		hourly := d.Hourly
+		if len(hourly) == 0 {
+			hourly = []HourlyDelta{{Display: d.Display, Click: d.Click}}
+		}
+
+		// одна строка на час: INSERT ... ON CONFLICT не обновляет строку дважды
+		hours := make(map[time.Time]HourlyDelta, len(hourly))
+		order := make([]time.Time, 0, len(hourly))
+		for _, h := range hourly {
+			hour := h.Hour.UTC().Truncate(time.Hour)
+			if h.Hour.IsZero() {
+				hour = currentHour
+			}
+			sum, ok := hours[hour]
+			if !ok {
+				order = append(order, hour)
+			}
+			sum.Display += h.Display
+			sum.Click += h.Click
+			hours[hour] = sum
+		}
+
+		tenantID := d.TenantID
+		if tenantID == 0 {
+			tenantID = DefaultTenant
+		}
+
+		for _, hour := range order {
+			n := len(args)
+			values = append(values, fmt.Sprintf("($%d::int, $%d::int, $%d::int, $%d::int, $%d::timestamptz, $%d::int, $%d::int)",
+				n+1, n+2, n+3, n+4, n+5, n+6, n+7))
+			args = append(args, tenantID, d.SlotID, d.BannerID, d.SocialGroupID, hour,
+				hours[hour].Display, hours[hour].Click)
+		}
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO statistics_hourly (tenant_id, slot_id, banner_id, social_group_id, hour, display, click)
+		VALUES ` + strings.Join(values, ", ") + hourlyUpsert
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("can't apply hourly statistics: %w", wrapError(err))
+	}
+
+	return nil
+}
+
+// SaveDeadLetters Сохраняет события, которые не удалось опубликовать или применить.
+func (s *Storage) SaveDeadLetters(ctx context.Context, letters []DeadLetter) error {
+	return copyDeadLetters(ctx, s.conn, letters)
+}
+
+// copier пул соединений или транзакция.
+type copier interface {
+	CopyFrom(ctx context.Context, tableName pgx4.Identifier, columnNames []string,
+		rowSrc pgx4.CopyFromSource) (int64, error)
+}
+
+func copyDeadLetters(ctx context.Context, conn copier, letters []DeadLetter) error {
+	if len(letters) == 0 {
+		return nil
+	}
+
+	rows := make([][]interface{}, len(letters))
+	for i, l := range letters {
+		rows[i] = []interface{}{l.Body, l.ContentType, l.SchemaVersion, l.Reason}
+	}
+
+	_, err := conn.CopyFrom(ctx,
+		pgx4.Identifier{"dead_letter_event"},
+		[]string{"payload", "content_type", "schema_version", "reason"},
+		pgx4.CopyFromRows(rows),
+	)
+	if err != nil {
+		return fmt.Errorf("can't save dead letters: %w", err)
+	}
+
+	return nil
+}
+
+// GetDeadLetters Выбирает еще не переотправленные события в порядке поступления.
+func (s *Storage) GetDeadLetters(ctx context.Context, limit int) ([]DeadLetter, error) {
+	result := make([]DeadLetter, 0)
 
-	return result, totalDisplay, err
+	query := `
+		SELECT dead_letter_event_id, payload, content_type, schema_version, reason, created_at
+		FROM dead_letter_event WHERE replayed_at IS NULL ORDER BY dead_letter_event_id LIMIT $1
+	`
+
+	rows, err := s.conn.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var l DeadLetter
+		if err := rows.Scan(&l.ID, &l.Body, &l.ContentType, &l.SchemaVersion, &l.Reason, &l.CreatedAt); err != nil {
+			return nil, fmt.Errorf("cant convert result: %w", err)
+		}
+
+		result = append(result, l)
+	}
+
+	return result, rows.Err()
+}
+
+func (s *Storage) MarkDeadLetterReplayed(ctx context.Context, id int64) error {
+	query := `UPDATE dead_letter_event SET replayed_at = now() WHERE dead_letter_event_id = $1`
+
+	_, err := s.conn.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("can't mark dead letter %d as replayed: %w", id, err)
+	}
+
+	return nil
 }
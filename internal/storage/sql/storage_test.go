@@ -4,6 +4,7 @@ import (
 	"context"
 	pgx4 "github.com/jackc/pgx/v4"
 	"github.com/stretchr/testify/require"
+	"time"
 
 	"testing"
 )
@@ -46,9 +47,69 @@ func TestStorage(t *testing.T) {
 		err = storage.CountDisplay(ctx, 1, 1, 2)
 		require.NoError(t, err)
 
-		_, _, err = storage.GetBannersStat(ctx, 1, 1)
+		_, _, err = storage.GetBannersStat(ctx, 1, 1, time.Now())
 		require.NoError(t, err)
 
+		_, err = storage.ApplyStatistics(ctx, []StatisticsDelta{
+			{SlotID: 1, BannerID: 1, SocialGroupID: 2, Display: 2, Click: 1},
+		}, nil)
+		require.NoError(t, err)
+
+		_, err = storage.GetDeadLetters(ctx, 10)
+		require.NoError(t, err)
+
+		banners, total, err := storage.GetBannersStat(ctx, 1, 2, time.Now())
+		require.NoError(t, err)
+		for _, b := range banners {
+			if b.ID != 1 {
+				continue
+			}
+
+			// total_display меняется на изменение показов, а не пересчитывается по строкам слота
+			row := StatisticsImport{SlotID: 1, BannerID: 1, SocialGroupID: 2, Display: b.Display + 3, Click: b.Click}
+			_, err = storage.ImportStatistics(ctx, []StatisticsImport{row}, true)
+			require.NoError(t, err)
+			_, after, err := storage.GetBannersStat(ctx, 1, 2, time.Now())
+			require.NoError(t, err)
+			require.Equal(t, total+3, after)
+
+			row.Display = b.Display
+			_, err = storage.ImportStatistics(ctx, []StatisticsImport{row}, true)
+			require.NoError(t, err)
+			_, after, err = storage.GetBannersStat(ctx, 1, 2, time.Now())
+			require.NoError(t, err)
+			require.Equal(t, total, after)
+		}
+
+		rows, err := storage.GetStatistics(ctx, StatisticsFilter{SlotID: 1}, []string{DimensionBanner})
+		require.NoError(t, err)
+		for _, r := range rows {
+			require.Zero(t, r.SlotID)
+			require.NotZero(t, r.BannerID)
+		}
+
+		_, err = storage.GetStatistics(ctx, StatisticsFilter{}, []string{"country"})
+		require.Error(t, err)
+
+		now := time.Now()
+		points, err := storage.GetStatisticsSeries(ctx, StatisticsFilter{SlotID: 1}, nil,
+			IntervalHour, now.Add(-time.Hour), now.Add(time.Hour))
+		require.NoError(t, err)
+		require.NotEmpty(t, points)
+
+		_, err = storage.RollupHourlyStatistics(ctx, now.Add(time.Hour*48))
+		require.NoError(t, err)
+
+		points, err = storage.GetStatisticsSeries(ctx, StatisticsFilter{SlotID: 1}, nil,
+			IntervalHour, now.Add(-time.Hour), now.Add(time.Hour))
+		require.NoError(t, err)
+		require.Empty(t, points)
+
+		points, err = storage.GetStatisticsSeries(ctx, StatisticsFilter{SlotID: 1}, nil,
+			IntervalDay, now.Add(-time.Hour*48), now.Add(time.Hour*48))
+		require.NoError(t, err)
+		require.NotEmpty(t, points)
+
 		err = tx.Rollback(ctx)
 		if err != nil {
 			t.Fatal("Failed to rollback tx", err)
@@ -0,0 +1,62 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+)
+
+// DefaultTenant арендатор, которому принадлежат данные, если арендатор не указан.
+const DefaultTenant int64 = 1
+
+type Tenant struct {
+	ID          int64  `db:"tenant_id"`
+	Description string `db:"tenant_description"`
+}
+
+type tenantContextKey struct{}
+
+// WithTenant задает арендатора, в рамках которого выполняются запросы к хранилищу.
+func WithTenant(ctx context.Context, tenantID int64) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenantID)
+}
+
+// TenantFromContext арендатор из контекста, DefaultTenant если не задан.
+func TenantFromContext(ctx context.Context) int64 {
+	if tenantID, ok := ctx.Value(tenantContextKey{}).(int64); ok && tenantID > 0 {
+		return tenantID
+	}
+
+	return DefaultTenant
+}
+
+func (s *Storage) CreateTenant(ctx context.Context, description string) (int64, error) {
+	query := `INSERT INTO tenant (tenant_description) VALUES ($1) RETURNING tenant_id`
+
+	var id int64
+	if err := s.conn.QueryRow(ctx, query, description).Scan(&id); err != nil {
+		return 0, fmt.Errorf("can't create tenant: %w", wrapError(err))
+	}
+
+	return id, nil
+}
+
+func (s *Storage) ListTenants(ctx context.Context) ([]Tenant, error) {
+	result := make([]Tenant, 0)
+
+	rows, err := s.conn.Query(ctx, `SELECT tenant_id, tenant_description FROM tenant ORDER BY tenant_id`)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t Tenant
+		if err := rows.Scan(&t.ID, &t.Description); err != nil {
+			return nil, fmt.Errorf("cant convert result: %w", err)
+		}
+
+		result = append(result, t)
+	}
+
+	return result, rows.Err()
+}
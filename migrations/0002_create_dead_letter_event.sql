@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS dead_letter_event (
+    dead_letter_event_id SERIAL PRIMARY KEY,
+    payload bytea NOT NULL,
+    content_type text NOT NULL,
+    schema_version integer NOT NULL,
+    reason text NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    replayed_at timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS dead_letter_event_pending_idx
+    ON dead_letter_event (dead_letter_event_id) WHERE replayed_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS dead_letter_event;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS idempotency_key (
+    idempotency_key text PRIMARY KEY,
+    request_hash bytea NOT NULL,
+    status_code integer,
+    content_type text NOT NULL DEFAULT '',
+    response_body bytea,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    expires_at timestamptz NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idempotency_key_expires_at_idx ON idempotency_key (expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS idempotency_key;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS api_key (
+    api_key_id SERIAL PRIMARY KEY,
+    name text NOT NULL,
+    key_hash bytea NOT NULL UNIQUE,
+    role text NOT NULL CHECK (role IN ('serving', 'admin', 'analytics')),
+    created_at timestamptz NOT NULL DEFAULT now(),
+    revoked_at timestamptz
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS api_key;
+-- +goose StatementEnd
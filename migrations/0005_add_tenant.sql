@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS tenant (
+    tenant_id SERIAL PRIMARY KEY,
+    tenant_description text NOT NULL
+);
+
+-- все существующие данные принадлежат арендатору по умолчанию
+INSERT INTO tenant (tenant_id, tenant_description) VALUES (1, 'default') ON CONFLICT DO NOTHING;
+SELECT setval('tenant_tenant_id_seq', (SELECT max(tenant_id) FROM tenant));
+
+ALTER TABLE slot ADD COLUMN tenant_id integer NOT NULL DEFAULT 1 REFERENCES tenant (tenant_id);
+ALTER TABLE banner ADD COLUMN tenant_id integer NOT NULL DEFAULT 1 REFERENCES tenant (tenant_id);
+ALTER TABLE social_group ADD COLUMN tenant_id integer NOT NULL DEFAULT 1 REFERENCES tenant (tenant_id);
+ALTER TABLE banner_to_slot ADD COLUMN tenant_id integer NOT NULL DEFAULT 1 REFERENCES tenant (tenant_id);
+ALTER TABLE statistics ADD COLUMN tenant_id integer NOT NULL DEFAULT 1 REFERENCES tenant (tenant_id);
+ALTER TABLE api_key ADD COLUMN tenant_id integer NOT NULL DEFAULT 1 REFERENCES tenant (tenant_id);
+
+CREATE INDEX IF NOT EXISTS slot_tenant_idx ON slot (tenant_id);
+CREATE INDEX IF NOT EXISTS banner_tenant_idx ON banner (tenant_id);
+CREATE INDEX IF NOT EXISTS social_group_tenant_idx ON social_group (tenant_id);
+CREATE INDEX IF NOT EXISTS statistics_tenant_slot_group_idx ON statistics (tenant_id, slot_id, social_group_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS statistics_tenant_slot_group_idx;
+ALTER TABLE api_key DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE statistics DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE banner_to_slot DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE social_group DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE banner DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE slot DROP COLUMN IF EXISTS tenant_id;
+DROP TABLE IF EXISTS tenant;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- журнал решений стратегии для оффлайн оценки: кандидаты с оценками и вероятностями показа
+CREATE TABLE IF NOT EXISTS decision_log (
+    decision_log_id BIGSERIAL PRIMARY KEY,
+    tenant_id integer NOT NULL DEFAULT 1 REFERENCES tenant (tenant_id),
+    decided_at timestamptz NOT NULL,
+    impression_id text NOT NULL,
+    slot_id integer NOT NULL,
+    social_group_id integer NOT NULL,
+    banner_id integer NOT NULL,
+    strategy text NOT NULL,
+    strategy_params jsonb NOT NULL,
+    total_display bigint NOT NULL,
+    candidates jsonb NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS decision_log_tenant_slot_idx ON decision_log (tenant_id, slot_id, decided_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS decision_log;
+-- +goose StatementEnd
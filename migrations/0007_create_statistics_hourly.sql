@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+-- показы и клики по часам, обновляются вместе с statistics
+CREATE TABLE IF NOT EXISTS statistics_hourly (
+    tenant_id integer NOT NULL DEFAULT 1 REFERENCES tenant (tenant_id),
+    slot_id integer NOT NULL,
+    banner_id integer NOT NULL,
+    social_group_id integer NOT NULL,
+    hour timestamptz NOT NULL,
+    display integer NOT NULL DEFAULT 0,
+    click integer NOT NULL DEFAULT 0,
+    PRIMARY KEY (tenant_id, slot_id, banner_id, social_group_id, hour)
+);
+
+CREATE INDEX IF NOT EXISTS statistics_hourly_hour_idx ON statistics_hourly (hour);
+
+-- часовые строки старше statistics.rollupAfterDays сворачиваются в дневные (день по UTC)
+CREATE TABLE IF NOT EXISTS statistics_daily (
+    tenant_id integer NOT NULL DEFAULT 1 REFERENCES tenant (tenant_id),
+    slot_id integer NOT NULL,
+    banner_id integer NOT NULL,
+    social_group_id integer NOT NULL,
+    day date NOT NULL,
+    display integer NOT NULL DEFAULT 0,
+    click integer NOT NULL DEFAULT 0,
+    PRIMARY KEY (tenant_id, slot_id, banner_id, social_group_id, day)
+);
+
+CREATE INDEX IF NOT EXISTS statistics_daily_day_idx ON statistics_daily (day);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS statistics_daily;
+DROP TABLE IF EXISTS statistics_hourly;
+-- +goose StatementEnd
@@ -0,0 +1,54 @@
+-- +goose Up
+-- +goose StatementBegin
+-- априорные псевдосчетчики слота: alpha кликов и beta показов без клика, NULL - значение из конфигурации
+ALTER TABLE slot ADD COLUMN prior_alpha double precision CHECK (prior_alpha >= 0);
+ALTER TABLE slot ADD COLUMN prior_beta double precision CHECK (prior_beta >= 0);
+
+-- начальная статистика баннера (теплый старт) хранится отдельно от настоящих показов и кликов
+ALTER TABLE statistics ADD COLUMN prior_alpha double precision NOT NULL DEFAULT 0;
+ALTER TABLE statistics ADD COLUMN prior_beta double precision NOT NULL DEFAULT 0;
+
+-- строки создавались с одним фиктивным показом, счетчики слотов и баннеров - с единицы;
+-- фиктивный показ снимается со всех строк, а какие строки изменены, запоминается для Down
+CREATE TABLE migration_0008_display_seed (
+    table_name text NOT NULL,
+    row_id integer NOT NULL,
+    PRIMARY KEY (table_name, row_id)
+);
+
+INSERT INTO migration_0008_display_seed (table_name, row_id)
+SELECT 'statistics', statistics_id FROM statistics WHERE display > 0;
+INSERT INTO migration_0008_display_seed (table_name, row_id)
+SELECT 'slot', slot_id FROM slot WHERE total_display > 0;
+INSERT INTO migration_0008_display_seed (table_name, row_id)
+SELECT 'banner', banner_id FROM banner WHERE total_display > 0;
+
+UPDATE statistics SET display = GREATEST(display - 1, 0) WHERE display IS NOT NULL;
+UPDATE slot SET total_display = GREATEST(total_display - 1, 0) WHERE total_display IS NOT NULL;
+UPDATE banner SET total_display = GREATEST(total_display - 1, 0) WHERE total_display IS NOT NULL;
+
+ALTER TABLE statistics ALTER COLUMN display SET DEFAULT 0;
+ALTER TABLE slot ALTER COLUMN total_display SET DEFAULT 0;
+ALTER TABLE banner ALTER COLUMN total_display SET DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE banner ALTER COLUMN total_display SET DEFAULT 1;
+ALTER TABLE slot ALTER COLUMN total_display SET DEFAULT 1;
+ALTER TABLE statistics ALTER COLUMN display SET DEFAULT 1;
+
+-- возвращается фиктивный показ только тем строкам, с которых его снял Up
+UPDATE banner b SET total_display = b.total_display + 1
+FROM migration_0008_display_seed m WHERE m.table_name = 'banner' AND m.row_id = b.banner_id;
+UPDATE slot s SET total_display = s.total_display + 1
+FROM migration_0008_display_seed m WHERE m.table_name = 'slot' AND m.row_id = s.slot_id;
+UPDATE statistics st SET display = st.display + 1
+FROM migration_0008_display_seed m WHERE m.table_name = 'statistics' AND m.row_id = st.statistics_id;
+DROP TABLE IF EXISTS migration_0008_display_seed;
+
+ALTER TABLE statistics DROP COLUMN IF EXISTS prior_beta;
+ALTER TABLE statistics DROP COLUMN IF EXISTS prior_alpha;
+ALTER TABLE slot DROP COLUMN IF EXISTS prior_beta;
+ALTER TABLE slot DROP COLUMN IF EXISTS prior_alpha;
+-- +goose StatementEnd
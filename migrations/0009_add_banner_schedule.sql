@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- окно активности баннера в слоте: [active_from, active_to), NULL - без границы;
+-- weekdays - маска дней недели (бит 0 - понедельник), hours - маска часов (бит 0 - 00:00-01:00)
+-- в часовом поясе schedule.timezone, 0 - без ограничения
+ALTER TABLE banner_to_slot ADD COLUMN active_from timestamptz;
+ALTER TABLE banner_to_slot ADD COLUMN active_to timestamptz;
+ALTER TABLE banner_to_slot ADD COLUMN weekdays smallint NOT NULL DEFAULT 0 CHECK (weekdays BETWEEN 0 AND 127);
+ALTER TABLE banner_to_slot ADD COLUMN hours integer NOT NULL DEFAULT 0 CHECK (hours BETWEEN 0 AND 16777215);
+ALTER TABLE banner_to_slot ADD CONSTRAINT banner_to_slot_active_range_check
+    CHECK (active_from IS NULL OR active_to IS NULL OR active_from < active_to);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE banner_to_slot DROP CONSTRAINT IF EXISTS banner_to_slot_active_range_check;
+ALTER TABLE banner_to_slot DROP COLUMN IF EXISTS hours;
+ALTER TABLE banner_to_slot DROP COLUMN IF EXISTS weekdays;
+ALTER TABLE banner_to_slot DROP COLUMN IF EXISTS active_to;
+ALTER TABLE banner_to_slot DROP COLUMN IF EXISTS active_from;
+-- +goose StatementEnd
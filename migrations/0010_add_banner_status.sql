@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- состояние баннера в слоте: active - участвует в выборе, paused - временно снят, archived - снят
+-- окончательно; статистика paused и archived баннеров сохраняется
+ALTER TABLE banner_to_slot ADD COLUMN status text NOT NULL DEFAULT 'active'
+    CHECK (status IN ('active', 'paused', 'archived'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE banner_to_slot DROP COLUMN IF EXISTS status;
+-- +goose StatementEnd